@@ -95,6 +95,7 @@ func main() {
 	var (
 		address string
 		execute string
+		token   string
 
 		rpc = new(client)
 		err error
@@ -121,6 +122,10 @@ func main() {
 		"a",
 		ADDRESS,
 		"rpc address")
+	flag.StringVar(&token,
+		"t",
+		"",
+		"rpc token (if the node requires it)")
 	flag.StringVar(&execute,
 		"e",
 		"",
@@ -145,7 +150,13 @@ func main() {
 		return
 	}
 
-	if rpc.r, err = node.NewRPCClient(address); err != nil {
+	if token != "" {
+		rpc.r, err = node.NewRPCClientToken(address, token)
+	} else {
+		rpc.r, err = node.NewRPCClient(address)
+	}
+
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		code = 1
 		return
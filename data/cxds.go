@@ -26,7 +26,8 @@ type IterateObjectsDelFunc func(
 // data/cxds implementation that contains boltdb based
 // and in-memory (golang map based) implementations of
 // the CXDS. The CXDS returns ErrNotFound from this
-// package if any value has not been found. The
+// package if any value has not been found, and
+// ErrClosed if it's used after Close. The
 // references counters is number of objects that points
 // to an object. E.g. schema of the CXDS is
 //
@@ -83,6 +83,21 @@ func TestCXDS_Inc(t *testing.T) {
 	})
 }
 
+func TestCXDS_Del(t *testing.T) {
+	// Del(key cipher.SHA256) (err error)
+
+	t.Run("memory", func(t *testing.T) {
+		tests.CXDSDel(t, NewMemoryCXDS())
+	})
+
+	t.Run("drive", func(t *testing.T) {
+		ds := testDriveDS(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		tests.CXDSDel(t, ds)
+	})
+}
+
 func TestCXDS_Close(t *testing.T) {
 	// Close() (err error)
 
@@ -97,3 +112,17 @@ func TestCXDS_Close(t *testing.T) {
 		tests.CXDSClose(t, ds)
 	})
 }
+
+func TestCXDS_closed(t *testing.T) {
+
+	t.Run("memory", func(t *testing.T) {
+		tests.CXDSClosed(t, NewMemoryCXDS())
+	})
+
+	t.Run("drive", func(t *testing.T) {
+		ds := testDriveDS(t)
+		defer os.Remove(testFileName)
+		defer ds.Close()
+		tests.CXDSClosed(t, ds)
+	})
+}
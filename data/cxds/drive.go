@@ -328,14 +328,22 @@ func (d *driveCXDS) Get(
 	}
 
 	if inc == 0 {
-		err = d.b.View(tx) // lookup only
+		err = dbError(d.b.View(tx)) // lookup only
 	} else {
-		err = d.b.Update(tx) // some changes
+		err = dbError(d.b.Update(tx)) // some changes
 	}
 
 	return
 }
 
+// dbError replaces bolt.ErrDatabaseNotOpen with data.ErrClosed
+func dbError(err error) error {
+	if err == bolt.ErrDatabaseNotOpen {
+		return data.ErrClosed
+	}
+	return err
+}
+
 func panicf(format string, args ...interface{}) {
 	panic(fmt.Sprintf(format, args...))
 }
@@ -367,7 +375,7 @@ func (d *driveCXDS) Set(
 		return
 	}
 
-	err = d.b.Update(func(tx *bolt.Tx) (err error) {
+	err = dbError(d.b.Update(func(tx *bolt.Tx) (err error) {
 
 		var (
 			o   = tx.Bucket(objsBucket)
@@ -385,7 +393,7 @@ func (d *driveCXDS) Set(
 
 		rc, err = d.incr(o, key[:], got[4:], getRefsCount(got), inc)
 		return
-	})
+	}))
 
 	return
 }
@@ -421,9 +429,9 @@ func (d *driveCXDS) Inc(
 	}
 
 	if inc == 0 {
-		err = d.b.View(tx) // lookup only
+		err = dbError(d.b.View(tx)) // lookup only
 	} else {
-		err = d.b.Update(tx) // changes required
+		err = dbError(d.b.Update(tx)) // changes required
 	}
 
 	return
@@ -450,7 +458,7 @@ func (d *driveCXDS) Del(
 	err error,
 ) {
 
-	err = d.b.Update(func(tx *bolt.Tx) (err error) {
+	err = dbError(d.b.Update(func(tx *bolt.Tx) (err error) {
 
 		var (
 			o   = tx.Bucket(objsBucket)
@@ -467,7 +475,7 @@ func (d *driveCXDS) Del(
 
 		d.del(getRefsCount(got), len(got)-4)
 		return // nil
-	})
+	}))
 
 	return
 }
@@ -475,7 +483,7 @@ func (d *driveCXDS) Del(
 // Iterate all keys
 func (d *driveCXDS) Iterate(iterateFunc data.IterateObjectsFunc) (err error) {
 
-	err = d.b.View(func(tx *bolt.Tx) (err error) {
+	err = dbError(d.b.View(func(tx *bolt.Tx) (err error) {
 
 		var (
 			key cipher.SHA256
@@ -497,7 +505,7 @@ func (d *driveCXDS) Iterate(iterateFunc data.IterateObjectsFunc) (err error) {
 
 		return
 
-	})
+	}))
 
 	return
 }
@@ -509,7 +517,7 @@ func (d *driveCXDS) IterateDel(
 	err error,
 ) {
 
-	err = d.b.Update(func(tx *bolt.Tx) (err error) {
+	err = dbError(d.b.Update(func(tx *bolt.Tx) (err error) {
 
 		var (
 			key cipher.SHA256
@@ -547,7 +555,7 @@ func (d *driveCXDS) IterateDel(
 
 		return
 
-	})
+	}))
 
 	return
 }
@@ -101,6 +101,11 @@ func (m *memoryCXDS) Get(
 		defer m.mx.Unlock()
 	}
 
+	if m.kvs == nil {
+		err = data.ErrClosed
+		return
+	}
+
 	if mo, ok := m.kvs[key]; ok {
 		val, rc = mo.val, mo.rc
 		rc = m.incr(key, mo, rc, inc)
@@ -132,6 +137,11 @@ func (m *memoryCXDS) Set(
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
+	if m.kvs == nil {
+		err = data.ErrClosed
+		return
+	}
+
 	if mo, ok := m.kvs[key]; ok {
 		rc = m.incr(key, mo, mo.rc, inc)
 		return
@@ -168,6 +178,11 @@ func (m *memoryCXDS) Inc(
 		defer m.mx.Unlock()
 	}
 
+	if m.kvs == nil {
+		err = data.ErrClosed
+		return
+	}
+
 	if mo, ok := m.kvs[key]; ok {
 		rc = m.incr(key, mo, mo.rc, inc)
 		return
@@ -182,6 +197,10 @@ func (m *memoryCXDS) Del(key cipher.SHA256) (_ error) {
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
+	if m.kvs == nil {
+		return data.ErrClosed
+	}
+
 	var mo, ok = m.kvs[key]
 
 	if ok == false {
@@ -196,6 +215,8 @@ func (m *memoryCXDS) Del(key cipher.SHA256) (_ error) {
 	m.amountAll--
 	m.voluemAll -= len(mo.val)
 
+	delete(m.kvs, key)
+
 	return
 }
 
@@ -205,6 +226,10 @@ func (m *memoryCXDS) Iterate(iterateFunc data.IterateObjectsFunc) (err error) {
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
+	if m.kvs == nil {
+		return data.ErrClosed
+	}
+
 	for k, mo := range m.kvs {
 		if err = iterateFunc(k, mo.rc, mo.val); err != nil {
 			if err == data.ErrStopIteration {
@@ -227,6 +252,10 @@ func (m *memoryCXDS) IterateDel(
 	m.mx.Lock()
 	defer m.mx.Unlock()
 
+	if m.kvs == nil {
+		return data.ErrClosed
+	}
+
 	var del bool
 
 	for k, mo := range m.kvs {
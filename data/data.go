@@ -14,6 +14,7 @@ var (
 	ErrNoSuchFeed    = errors.New("no such feed")
 	ErrNoSuchHead    = errors.New("no such head")
 	ErrInvalidSize   = errors.New("invalid size of encoded data")
+	ErrClosed        = errors.New("closed")
 )
 
 // A DB represents joiner of IdxDB and CXDS
@@ -121,3 +121,34 @@ type IdxDB interface {
 	Tx(func(Feeds) error) error // transaction
 	Close() error               // close the IdxDB
 }
+
+// An IterateExpiryFunc used to iterate over
+// expiration times of objects
+type IterateExpiryFunc func(key cipher.SHA256, expire int64) (err error)
+
+// An Expiry represents bucket of expiration times
+// of objects. An expiration time is unix nano
+type Expiry interface {
+	// Set expiration time of object with given key.
+	// The Set replaces existing expiration time
+	Set(key cipher.SHA256, expire int64) (err error)
+	// Del expiration time of object with given key.
+	// The Del never returns ErrNotFound
+	Del(key cipher.SHA256) (err error)
+	// Iterate all expiration times. Use ErrStopIteration
+	// to stop the iteration. It's possible to mutate the
+	// Expiry inside the Iterate
+	Iterate(iterateFunc IterateExpiryFunc) (err error)
+
+	// Len is number of expiration times stored
+	Len() (length int)
+}
+
+// An ExpiryDB is IdxDB that keeps expiration times
+// of objects (see Expiry). The data/idxdb package
+// implements it. Other IdxDB implementations can
+// omit the ExpiryDB
+type ExpiryDB interface {
+	IdxDB
+	ExpiryTx(func(Expiry) error) error // transaction
+}
@@ -13,9 +13,10 @@ import (
 )
 
 var (
-	feedsBucket = []byte("f")       // feeds
-	metaBucket  = []byte("m")       // meta information
-	versionKey  = []byte("version") // encoded version in the meta bucket
+	feedsBucket  = []byte("f")       // feeds
+	expiryBucket = []byte("e")       // expiration times of objects
+	metaBucket   = []byte("m")       // meta information
+	versionKey   = []byte("version") // encoded version in the meta bucket
 )
 
 type driveDB struct {
@@ -83,7 +84,11 @@ func NewDriveIdxDB(fileName string) (idx data.IdxDB, err error) {
 
 		}
 
-		_, err = tx.CreateBucketIfNotExists(feedsBucket)
+		if _, err = tx.CreateBucketIfNotExists(feedsBucket); err != nil {
+			return
+		}
+
+		_, err = tx.CreateBucketIfNotExists(expiryBucket)
 		return
 	})
 
@@ -103,11 +108,75 @@ func (d *driveDB) Tx(txFunc func(feeds data.Feeds) (err error)) (err error) {
 	})
 }
 
+// ExpiryTx performs ACID-transaction
+// over expiration times of objects
+func (d *driveDB) ExpiryTx(
+	txFunc func(ex data.Expiry) (err error),
+) (
+	err error,
+) {
+	return d.b.Update(func(tx *bolt.Tx) (err error) {
+		return txFunc(&driveExpiry{tx.Bucket(expiryBucket)})
+	})
+}
+
 // Close the DB
 func (d *driveDB) Close() (err error) {
 	return d.b.Close()
 }
 
+type driveExpiry struct {
+	bk *bolt.Bucket
+}
+
+// Set expiration time
+func (d *driveExpiry) Set(key cipher.SHA256, expire int64) (err error) {
+	var eb = make([]byte, 8)
+	binary.BigEndian.PutUint64(eb, uint64(expire))
+	return d.bk.Put(key[:], eb)
+}
+
+// Del expiration time
+func (d *driveExpiry) Del(key cipher.SHA256) (err error) {
+	return d.bk.Delete(key[:])
+}
+
+// Iterate over all expiration times
+func (d *driveExpiry) Iterate(
+	iterateFunc data.IterateExpiryFunc,
+) (
+	err error,
+) {
+	var key cipher.SHA256
+	c := d.bk.Cursor()
+	// we have to Seek(next) instead of using Next
+	// because we allows mutations during the iteration
+	for k, v := c.First(); k != nil; k, v = c.Seek(key[:]) {
+		if len(v) != 8 {
+			return ErrInvalidSize
+		}
+		copy(key[:], k)
+		err = iterateFunc(key, int64(binary.BigEndian.Uint64(v)))
+		if err != nil {
+			if err == data.ErrStopIteration {
+				err = nil
+			}
+			return
+		}
+		incSlice(key[:])
+	}
+	return
+}
+
+func (d *driveExpiry) Len() (length int) {
+	// the Stats doesn't count changes of the transaction
+	c := d.bk.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.Next() {
+		length++
+	}
+	return
+}
+
 type driveFeeds struct {
 	bk *bolt.Bucket
 }
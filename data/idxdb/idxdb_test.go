@@ -41,3 +41,23 @@ func TestIdxDB_Close(t *testing.T) {
 	})
 
 }
+
+func TestIdxDB_ExpiryTx(t *testing.T) {
+	// ExpiryTx(func(data.Expiry) error) error
+
+	t.Run("memory", func(t *testing.T) {
+		idx := NewMemeoryDB()
+		defer idx.Close()
+
+		tests.ExpiryTx(t, idx)
+	})
+
+	t.Run("drive", func(t *testing.T) {
+		idx := testNewDriveIdxDB(t)
+		defer os.Remove(testFileName)
+		defer idx.Close()
+
+		tests.ExpiryTx(t, idx)
+	})
+
+}
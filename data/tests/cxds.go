@@ -255,6 +255,32 @@ func CXDSInc(t *testing.T, ds data.CXDS) {
 
 }
 
+// CXDSDel tests Del method of CXDS
+func CXDSDel(t *testing.T, ds data.CXDS) {
+
+	var key, value = testKeyValue("something")
+
+	t.Run("not exist", func(t *testing.T) {
+		if err := ds.Del(key); err != nil {
+			t.Error(err)
+		}
+		shouldNotExistInCXDS(t, ds, key)
+	})
+
+	if _, err := ds.Set(key, value, 1); err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("del", func(t *testing.T) {
+		if err := ds.Del(key); err != nil {
+			t.Error(err)
+		}
+		shouldNotExistInCXDS(t, ds, key)
+	})
+
+}
+
 // CXDSClose tests Close method of CXDS
 func CXDSClose(t *testing.T, ds data.CXDS) {
 	if err := ds.Close(); err != nil {
@@ -264,3 +290,40 @@ func CXDSClose(t *testing.T, ds data.CXDS) {
 		t.Error(err)
 	}
 }
+
+// CXDSClosed tests methods of closed CXDS
+func CXDSClosed(t *testing.T, ds data.CXDS) {
+
+	var key, val = testKeyValue("something")
+
+	if _, err := ds.Set(key, val, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := ds.Get(key, 0); err != data.ErrClosed {
+		t.Error("Get: want ErrClosed, got", err)
+	}
+
+	if _, err := ds.Set(key, val, 1); err != data.ErrClosed {
+		t.Error("Set: want ErrClosed, got", err)
+	}
+
+	if _, err := ds.Inc(key, 0); err != data.ErrClosed {
+		t.Error("Inc: want ErrClosed, got", err)
+	}
+
+	if err := ds.Del(key); err != data.ErrClosed {
+		t.Error("Del: want ErrClosed, got", err)
+	}
+
+	var iterate = func(cipher.SHA256, uint32, []byte) (_ error) { return }
+
+	if err := ds.Iterate(iterate); err != data.ErrClosed {
+		t.Error("Iterate: want ErrClosed, got", err)
+	}
+
+}
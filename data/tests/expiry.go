@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// ExpiryTx is test case for ExpiryDB.ExpiryTx
+func ExpiryTx(t *testing.T, idx data.IdxDB) {
+
+	var edb, ok = idx.(data.ExpiryDB)
+
+	if ok == false {
+		t.Fatal("the IdxDB is not an ExpiryDB")
+	}
+
+	var (
+		k1 = cipher.SumSHA256([]byte("one"))
+		k2 = cipher.SumSHA256([]byte("two"))
+	)
+
+	err := edb.ExpiryTx(func(ex data.Expiry) (err error) {
+		if err = ex.Set(k1, 1); err != nil {
+			return
+		}
+		if err = ex.Set(k2, 2); err != nil {
+			return
+		}
+		return ex.Set(k2, 3) // replace
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got = make(map[cipher.SHA256]int64)
+
+	err = edb.ExpiryTx(func(ex data.Expiry) (err error) {
+		if ex.Len() != 2 {
+			t.Error("wrong length", ex.Len())
+		}
+		return ex.Iterate(func(key cipher.SHA256, expire int64) (_ error) {
+			got[key] = expire
+			return
+		})
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || got[k1] != 1 || got[k2] != 3 {
+		t.Error("wrong expiration times", got)
+	}
+
+	// delete inside the Iterate
+
+	err = edb.ExpiryTx(func(ex data.Expiry) (err error) {
+		err = ex.Iterate(func(key cipher.SHA256, _ int64) (_ error) {
+			return ex.Del(key)
+		})
+		if err != nil {
+			return
+		}
+		if ex.Len() != 0 {
+			t.Error("not deleted", ex.Len())
+		}
+		return ex.Del(k1) // not found
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
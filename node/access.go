@@ -0,0 +1,111 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// an addressList is parsed AllowList or DenyList
+type addressList struct {
+	addrs map[string]struct{} // exact addresses (host:port)
+	ips   map[string]struct{} // IP addresses
+	nets  []*net.IPNet        // CIDR ranges
+}
+
+// parseAddressList parses given list; it returns nil
+// if the list is empty
+func parseAddressList(list []string) (al *addressList, err error) {
+
+	if len(list) == 0 {
+		return
+	}
+
+	al = &addressList{
+		addrs: make(map[string]struct{}),
+		ips:   make(map[string]struct{}),
+	}
+
+	for _, addr := range list {
+
+		if strings.Contains(addr, "/") == true {
+
+			var ipNet *net.IPNet
+			if _, ipNet, err = net.ParseCIDR(addr); err != nil {
+				return nil, err
+			}
+
+			al.nets = append(al.nets, ipNet)
+			continue
+
+		}
+
+		if ip := net.ParseIP(addr); ip != nil {
+			al.ips[ip.String()] = struct{}{}
+			continue
+		}
+
+		var host string
+		if host, _, err = net.SplitHostPort(addr); err != nil {
+			return nil, err
+		}
+
+		if ip := net.ParseIP(host); ip == nil {
+			return nil, fmt.Errorf("invalid IP address in %q", addr)
+		}
+
+		al.addrs[addr] = struct{}{}
+
+	}
+
+	return
+}
+
+// match returns true if given address
+// (host:port) matches the list
+func (a *addressList) match(addr string) (ok bool) {
+
+	if _, ok = a.addrs[addr]; ok == true {
+		return
+	}
+
+	var host, _, err = net.SplitHostPort(addr)
+
+	if err != nil {
+		return false
+	}
+
+	var ip = net.ParseIP(host)
+
+	if ip == nil {
+		return false
+	}
+
+	if _, ok = a.ips[ip.String()]; ok == true {
+		return
+	}
+
+	for _, ipNet := range a.nets {
+		if ipNet.Contains(ip) == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkAddress returns ErrNotAllowed if incoming
+// connections from given address (host:port) are
+// not allowed (see AllowList and DenyList)
+func (n *Node) checkAddress(addr string) (err error) {
+
+	if n.deny != nil && n.deny.match(addr) == true {
+		return ErrNotAllowed
+	}
+
+	if n.allow != nil && n.allow.match(addr) == false {
+		return ErrNotAllowed
+	}
+
+	return
+}
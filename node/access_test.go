@@ -0,0 +1,120 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNode_checkAddress(t *testing.T) {
+
+	for _, tc := range []struct {
+		name        string
+		allow, deny []string
+		allowed     []string
+		denied      []string
+	}{
+		{
+			name:    "no lists",
+			allowed: []string{"127.0.0.1:8870", "[::1]:8870"},
+		},
+		{
+			name:    "allow only",
+			allow:   []string{"127.0.0.1", "192.168.0.1:8870"},
+			allowed: []string{"127.0.0.1:8870", "127.0.0.1:9000", "192.168.0.1:8870"},
+			denied:  []string{"127.0.0.2:8870", "192.168.0.1:8871"},
+		},
+		{
+			name:    "deny specific",
+			deny:    []string{"10.0.0.1", "10.0.0.2:8870"},
+			allowed: []string{"10.0.0.3:8870", "10.0.0.2:8871"},
+			denied:  []string{"10.0.0.1:8870", "10.0.0.2:8870"},
+		},
+		{
+			name:    "CIDR",
+			allow:   []string{"192.168.0.0/16", "fd00::/8"},
+			deny:    []string{"192.168.1.0/24"},
+			allowed: []string{"192.168.0.5:8870", "192.168.2.1:1", "[fd00::1]:8870"},
+			denied:  []string{"192.168.1.5:8870", "10.0.0.1:8870", "[::1]:8870"},
+		},
+	} {
+
+		t.Run(tc.name, func(t *testing.T) {
+
+			var (
+				n   = new(Node)
+				err error
+			)
+
+			n.allow, err = parseAddressList(tc.allow)
+			assertNil(t, err)
+
+			n.deny, err = parseAddressList(tc.deny)
+			assertNil(t, err)
+
+			for _, addr := range tc.allowed {
+				if err = n.checkAddress(addr); err != nil {
+					t.Errorf("%s is not allowed: %v", addr, err)
+				}
+			}
+
+			for _, addr := range tc.denied {
+				if err = n.checkAddress(addr); err != ErrNotAllowed {
+					t.Errorf("%s is not denied: %v", addr, err)
+				}
+			}
+
+		})
+
+	}
+
+}
+
+func TestConfig_Validate_addressList(t *testing.T) {
+
+	var conf = NewConfig()
+
+	conf.AllowList = Addresses{"192.168.0.0/33"}
+	assertTrue(t, conf.Validate() != nil, "invalid CIDR range")
+
+	conf.AllowList = nil
+	conf.DenyList = Addresses{"example.com:8870"}
+	assertTrue(t, conf.Validate() != nil, "invalid IP address")
+
+}
+
+func TestNode_DenyList(t *testing.T) {
+
+	var sc = getTestConfig("server")
+	sc.DenyList = Addresses{"127.0.0.0/8"}
+
+	var sn, err = NewNode(sc)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var rn = getTestNodeNotListen("client")
+	defer rn.Close()
+
+	_, err = rn.TCP().Connect(sn.TCP().Address())
+	assertTrue(t, err != nil, "connected")
+
+	time.Sleep(TM / 10)
+	assertTrue(t, len(sn.Connections()) == 0, "connection is not closed")
+
+}
+
+func TestNode_AllowList(t *testing.T) {
+
+	var sc = getTestConfig("server")
+	sc.AllowList = Addresses{"127.0.0.1"}
+
+	var sn, err = NewNode(sc)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var rn = getTestNodeNotListen("client")
+	defer rn.Close()
+
+	_, err = rn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+}
@@ -0,0 +1,145 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// a Root waiting for acknowledgments
+type rootAckKey struct {
+	feed  cipher.PubKey
+	nonce uint64
+	seq   uint64
+}
+
+type rootAckWait struct {
+	quorum int
+	peers  map[cipher.PubKey]struct{} // acknowledged
+	done   chan struct{}              // closed if quorum reached
+}
+
+// Root objects waiting for acknowledgments
+type rootAcks struct {
+	mx sync.Mutex
+	ws map[rootAckKey]*rootAckWait
+}
+
+func (r *rootAcks) add(key rootAckKey, quorum int) (w *rootAckWait) {
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.ws == nil {
+		r.ws = make(map[rootAckKey]*rootAckWait)
+	}
+
+	w = &rootAckWait{
+		quorum: quorum,
+		peers:  make(map[cipher.PubKey]struct{}),
+		done:   make(chan struct{}),
+	}
+
+	r.ws[key] = w
+	return
+}
+
+// del returns number of acknowledgments received
+func (r *rootAcks) del(key rootAckKey) (acks int) {
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if w, ok := r.ws[key]; ok == true {
+		acks = len(w.peers)
+		delete(r.ws, key)
+	}
+
+	return
+}
+
+func (r *rootAcks) ack(peer cipher.PubKey, ra *msg.RootAck) {
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	var w, ok = r.ws[rootAckKey{ra.Feed, ra.Nonce, ra.Seq}]
+
+	if ok == false {
+		return // not waiting
+	}
+
+	if _, ok = w.peers[peer]; ok == true {
+		return // already acknowledged
+	}
+
+	w.peers[peer] = struct{}{}
+
+	if len(w.peers) == w.quorum {
+		close(w.done)
+	}
+
+}
+
+func (c *Conn) sendRootAck(r *registry.Root) {
+	c.sendMsg(c.nextSeq(), 0, &msg.RootAck{
+		Feed:  r.Pub,
+		Nonce: r.Nonce,
+		Seq:   r.Seq,
+	})
+}
+
+// PublishAndWait publishes given Root like the Publish
+// method and waits until quorum peers acknowledge that
+// they have stored the Root and all its objects. A peer
+// acknowledges a Root once the Root received from the
+// Node is filled. Thus, a peer that already has the Root
+// never acknowledges it. The PublishAndWait returns
+// *AckTimeoutError if the quorum is not reached in given
+// timeout, and ErrClosed if the Node has been closed.
+// If the quorum is zero, then the PublishAndWait doesn't
+// wait
+func (n *Node) PublishAndWait(
+	r *registry.Root, //       : the Root to publish
+	quorum int, //             : acknowledgments to wait for
+	timeout time.Duration, //  : time limit
+) (
+	err error, //              : an error
+) {
+
+	if quorum <= 0 {
+		return n.Publish(r)
+	}
+
+	if err = validateFeed(r.Pub); err != nil {
+		return
+	}
+
+	var (
+		key = rootAckKey{r.Pub, r.Nonce, r.Seq}
+		w   = n.acks.add(key, quorum)
+		tm  = time.NewTimer(timeout)
+	)
+
+	defer tm.Stop()
+
+	n.Publish(r)
+
+	select {
+	case <-w.done:
+		n.acks.del(key)
+	case <-tm.C:
+		if acks := n.acks.del(key); acks < quorum {
+			err = &AckTimeoutError{acks: acks, quorum: quorum}
+		}
+	case <-n.closeq:
+		n.acks.del(key)
+		err = ErrClosed
+	}
+
+	return
+}
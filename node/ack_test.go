@@ -0,0 +1,72 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_PublishAndWait(t *testing.T) {
+
+	var sn, err = NewNode(getTestConfig("sender"))
+	assertNil(t, err)
+	defer sn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, sn.Share(pk))
+
+	// receivers
+
+	for _, prefix := range []string{"receiver 1", "receiver 2"} {
+
+		var rn *Node
+		rn, err = NewNode(getTestConfigNotListen(prefix))
+		assertNil(t, err)
+		defer rn.Close()
+
+		var c *Conn
+		c, err = rn.TCP().Connect(sn.TCP().Address())
+		assertNil(t, err)
+		assertNil(t, c.Subscribe(pk))
+
+	}
+
+	var (
+		sc  = sn.Container()
+		reg = getTestRegistry()
+	)
+
+	var save = func(name string) (r *registry.Root) {
+
+		var up, err = sc.Unpack(sk, reg)
+		assertNil(t, err)
+		defer up.Close()
+
+		r = new(registry.Root)
+		r.Pub = pk
+		r.Nonce = 9021
+		r.Refs = []registry.Dynamic{
+			dynamicByValue(t, up, "test.User", User{name, 21, nil}),
+		}
+
+		assertNil(t, sc.Save(up, r))
+		return
+	}
+
+	// quorum reached
+
+	assertNil(t, sn.PublishAndWait(save("Alice"), 2, 5*TM))
+
+	// timeout
+
+	err = sn.PublishAndWait(save("Eva"), 3, 5*TM)
+
+	var ate, ok = err.(*AckTimeoutError)
+	assertTrue(t, ok == true, fmt.Sprint("unexpected error: ", err))
+	assertTrue(t, ate.Acks() == 2, fmt.Sprint("wrong acks: ", ate.Acks()))
+	assertTrue(t, ate.Quorum() == 3, "wrong quorum")
+
+}
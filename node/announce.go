@@ -0,0 +1,192 @@
+package node
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A tokenBucket used to pace Root objects
+// the Node sends to a peer
+type tokenBucket struct {
+	rate   float64   // tokens per second
+	burst  float64   // max tokens
+	tokens float64   // available tokens
+	last   time.Time // last refill
+}
+
+func newTokenBucket(rate int) (t *tokenBucket) {
+	t = new(tokenBucket)
+	t.rate = float64(rate)
+	t.burst = float64(rate) // one second
+	t.tokens = t.burst
+	t.last = time.Now()
+	return
+}
+
+func (t *tokenBucket) refill() {
+	var now = time.Now()
+
+	if t.tokens += now.Sub(t.last).Seconds() * t.rate; t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+
+	t.last = now
+}
+
+// wait for a token; it returns false
+// if given quit channel has been closed
+func (t *tokenBucket) wait(quit <-chan struct{}) (ok bool) {
+
+	for {
+
+		t.refill()
+
+		if t.tokens >= 1 {
+			t.tokens--
+			return true
+		}
+
+		var tm = time.NewTimer(
+			time.Duration((1 - t.tokens) / t.rate * float64(time.Second)),
+		)
+
+		select {
+		case <-tm.C:
+		case <-quit:
+			tm.Stop()
+			return false
+		}
+
+	}
+
+}
+
+// head of a feed
+type rootHead struct {
+	feed  cipher.PubKey
+	nonce uint64
+}
+
+// pushRoot adds given Root to queue of Root objects
+// to send; the queue keeps only latest Root of a head
+func (c *Conn) pushRoot(r *registry.Root) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var rh = rootHead{r.Pub, r.Nonce}
+
+	if pr, ok := c.roots[rh]; ok == true {
+		if pr.Seq < r.Seq {
+			c.roots[rh] = r // replace with newer
+		}
+		return
+	}
+
+	c.roots[rh] = r
+	c.rootso = append(c.rootso, rh)
+
+	select {
+	case c.announceq <- struct{}{}:
+	default:
+	}
+
+}
+
+// popRoot returns next Root to send or nil
+func (c *Conn) popRoot() (r *registry.Root) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if len(c.rootso) == 0 {
+		return
+	}
+
+	var rh = c.rootso[0]
+
+	c.rootso = c.rootso[1:]
+	r = c.roots[rh]
+	delete(c.roots, rh)
+
+	if c.synco > 0 {
+		c.synco--
+	}
+
+	return
+}
+
+// pushSyncDone queues the SyncDone message to be sent
+// after Root objects queued now
+func (c *Conn) pushSyncDone() {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.syncq = true
+	c.synco = len(c.rootso)
+
+	select {
+	case c.announceq <- struct{}{}:
+	default:
+	}
+
+}
+
+// popSyncDone returns true if the SyncDone
+// message should be sent now
+func (c *Conn) popSyncDone() (send bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.syncq == true && c.synco == 0 {
+		c.syncq = false
+		return true
+	}
+
+	return
+}
+
+// announcing sends Root objects to peer
+// respecting the AnnounceRate limit
+func (c *Conn) announcing() {
+
+	defer c.await.Done()
+
+	var tb = newTokenBucket(c.n.config.AnnounceRate)
+
+	for {
+
+		select {
+		case <-c.announceq:
+		case <-c.closeq:
+			return
+		}
+
+		for {
+
+			if c.popSyncDone() == true {
+				c.sendSyncDone() // after queued Root objects
+			}
+
+			var r = c.popRoot()
+
+			if r == nil {
+				break
+			}
+
+			if tb.wait(c.closeq) == false {
+				return
+			}
+
+			c.sendRootMsg(r)
+
+		}
+
+	}
+
+}
@@ -0,0 +1,146 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestTokenBucket_wait(t *testing.T) {
+
+	const rate = 50
+
+	var (
+		tb   = newTokenBucket(rate)
+		quit = make(chan struct{})
+		tp   = time.Now()
+	)
+
+	// burst
+
+	for i := 0; i < rate; i++ {
+		assertTrue(t, tb.wait(quit) == true, "unexpected quit")
+	}
+
+	assertTrue(t, time.Since(tp) < TM/5, "burst is paced")
+
+	// paced
+
+	for i := 0; i < rate/2; i++ {
+		assertTrue(t, tb.wait(quit) == true, "unexpected quit")
+	}
+
+	assertTrue(t, time.Since(tp) >= TM*9/10, "not paced")
+
+	// quit
+
+	close(quit)
+	tb.tokens = 0
+	assertTrue(t, tb.wait(quit) == false, "quit ignored")
+
+}
+
+func TestConfig_AnnounceRate(t *testing.T) {
+
+	const (
+		rate  = 10
+		feeds = 30
+	)
+
+	var (
+		sconf = getTestConfig("sender")
+		rconf = getTestConfigNotListen("receiver")
+
+		received = make(chan time.Time, feeds)
+	)
+
+	sconf.AnnounceRate = rate
+	rconf.OnRootReceived = func(*Conn, *registry.Root) (reject error) {
+		received <- time.Now()
+		return ErrUnsubscribe // don't fill
+	}
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var rn *Node
+	rn, err = NewNode(rconf)
+	assertNil(t, err)
+	defer rn.Close()
+
+	var c *Conn
+	c, err = rn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	var (
+		sks = make([]cipher.SecKey, 0, feeds)
+		pks = make([]cipher.PubKey, 0, feeds)
+	)
+
+	for i := 0; i < feeds; i++ {
+		var pk, sk = cipher.GenerateKeyPair()
+		assertNil(t, sn.Share(pk))
+		assertNil(t, c.Subscribe(pk))
+		pks, sks = append(pks, pk), append(sks, sk)
+	}
+
+	// create and publish Root objects
+
+	var (
+		sc  = sn.Container()
+		reg = getTestRegistry()
+		rs  = make([]*registry.Root, 0, feeds)
+	)
+
+	for i, pk := range pks {
+
+		var up, err = sc.Unpack(sks[i], reg)
+		assertNil(t, err)
+
+		var r = new(registry.Root)
+		r.Pub = pk
+		r.Nonce = 9021
+
+		assertNil(t, sc.Save(up, r))
+		assertNil(t, up.Close())
+
+		rs = append(rs, r)
+	}
+
+	var tp = time.Now()
+
+	for _, r := range rs {
+		sn.Publish(r)
+	}
+
+	var (
+		tm      = time.After(feeds/rate*time.Second + 2*time.Second)
+		inFirst int // received in first second
+	)
+
+	for i := 0; i < feeds; i++ {
+		select {
+		case rt := <-received:
+			if rt.Sub(tp) < time.Second {
+				inFirst++
+			}
+		case <-tm:
+			t.Fatalf("slow: received %d of %d", i, feeds)
+		}
+	}
+
+	// burst + rate (+1 for rounding)
+	if inFirst > 2*rate+1 {
+		t.Errorf("not paced: %d Root objects received in first second",
+			inFirst)
+	}
+
+	if time.Since(tp) < time.Duration(feeds/rate-1)*time.Second {
+		t.Error("not paced: received too fast")
+	}
+
+}
@@ -0,0 +1,138 @@
+package node
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// penalties of misbehaving peers (see BanThreshold)
+const (
+	penaltyInvalidMessage  = -10 // can't decode or handle
+	penaltyTooLargeMessage = -10 // see MaxMessageSize
+	penaltyInvalidResponse = -5  // e.g. wrong object
+	penaltyInvalidRoot     = -5  // e.g. wrong signature
+)
+
+// score of a peer
+type peerScore struct {
+	score int       // negative
+	last  time.Time // last penalty
+}
+
+// scores and bans of peers by host
+type peerScores struct {
+	mx     sync.Mutex
+	scores map[string]*peerScore // host -> score
+	bans   map[string]time.Time  // host -> ban expiration
+}
+
+// host of given address (host:port)
+func peerHost(addr string) (host string) {
+
+	var err error
+	if host, _, err = net.SplitHostPort(addr); err != nil {
+		return addr // as is
+	}
+
+	return
+}
+
+// penalize adds given penalty to score of the host; it
+// returns true if the host has been banned; scores of
+// hosts without penalties for the reset time are reset
+// to zero
+func (p *peerScores) penalize(
+	host string, //            : the peer
+	penalty int, //            : negative
+	threshold int, //          : ban threshold
+	duration time.Duration, // : ban duration
+	reset time.Duration, //    : reset score after, zero to keep
+) (
+	banned bool, //            : just banned
+) {
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if p.scores == nil {
+		p.scores = make(map[string]*peerScore)
+		p.bans = make(map[string]time.Time)
+	}
+
+	var now = time.Now()
+
+	if reset > 0 {
+		for h, ps := range p.scores {
+			if now.Sub(ps.last) >= reset {
+				delete(p.scores, h) // quiet enough
+			}
+		}
+	}
+
+	var ps, ok = p.scores[host]
+
+	if ok == false {
+		ps = new(peerScore)
+	}
+
+	if ps.score+penalty >= threshold {
+		ps.score += penalty
+		ps.last = now
+		p.scores[host] = ps
+		return
+	}
+
+	delete(p.scores, host) // start from zero after the ban
+	p.bans[host] = now.Add(duration)
+
+	return true
+}
+
+// isBanned returns true if given host is banned
+func (p *peerScores) isBanned(host string) (banned bool) {
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	var till, ok = p.bans[host]
+
+	if ok == false {
+		return
+	}
+
+	if time.Now().Before(till) == true {
+		return true
+	}
+
+	delete(p.bans, host) // expired
+	return
+}
+
+// penalize the peer of given connection; it returns
+// true if the peer has been banned
+func (n *Node) penalize(c *Conn, penalty int) (banned bool) {
+
+	if n.config.BanThreshold == 0 {
+		return // disabled
+	}
+
+	var host = peerHost(c.Address())
+
+	banned = n.bans.penalize(host, penalty, n.config.BanThreshold,
+		n.config.BanDuration, n.config.BanScoreReset)
+
+	if banned == true {
+		n.Printf("[ERR] [%s] peer banned for %s", c.String(),
+			n.config.BanDuration)
+	}
+
+	return
+}
+
+// IsBanned returns true if peer with given
+// address (IP or host:port) is banned
+// (see BanThreshold)
+func (n *Node) IsBanned(addr string) (banned bool) {
+	return n.bans.isBanned(peerHost(addr))
+}
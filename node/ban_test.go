@@ -0,0 +1,117 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig_BanThreshold(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+
+		reasons = make(chan error, 1)
+	)
+
+	sconf.BanThreshold = -25 // three invalid messages
+	sconf.BanDuration = 2 * TM
+	sconf.OnDisconnect = func(_ *Conn, reason error) {
+		reasons <- reason
+	}
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	// a peer reconnects using new Node every time
+	var connect = func() (c *Conn, err error) {
+		var cn *Node
+		if cn, err = NewNode(getTestConfigNotListen("client")); err != nil {
+			return
+		}
+		defer func() {
+			if err != nil {
+				cn.Close()
+			}
+		}()
+		return cn.TCP().Connect(sn.TCP().Address())
+	}
+
+	// [ 4 seq ][ 4 rseq ][ invalid msg type ]
+	var invalid = []byte{1, 0, 0, 0, 0, 0, 0, 0, 0xff}
+
+	for i := 0; i < 3; i++ {
+
+		assertTrue(t, sn.IsBanned("127.0.0.1") == false, "banned too early")
+
+		var c *Conn
+		c, err = connect()
+		assertNil(t, err)
+		defer c.n.Close()
+
+		c.sendRaw(invalid)
+
+		select {
+		case <-reasons:
+		case <-time.After(TM):
+			t.Fatal("not closed")
+		}
+
+	}
+
+	assertTrue(t, sn.IsBanned("127.0.0.1") == true, "not banned")
+
+	_, err = connect()
+	assertTrue(t, err != nil, "connection of banned peer accepted")
+
+	// the ban expires
+
+	time.Sleep(2 * TM)
+
+	assertTrue(t, sn.IsBanned("127.0.0.1") == false, "still banned")
+
+	var c *Conn
+	c, err = connect()
+	assertNil(t, err)
+	c.n.Close()
+
+}
+
+func TestPeerScores_reset(t *testing.T) {
+
+	const (
+		host      = "127.0.0.1"
+		threshold = -25 // three penalties
+		reset     = TM / 2
+	)
+
+	var penalize = func(ps *peerScores, reset time.Duration) bool {
+		return ps.penalize(host, -10, threshold, TM, reset)
+	}
+
+	var ps peerScores
+
+	assertTrue(t, penalize(&ps, reset) == false, "banned too early")
+	assertTrue(t, penalize(&ps, reset) == false, "banned too early")
+
+	time.Sleep(reset)
+
+	// quiet enough, the score is reset
+
+	assertTrue(t, penalize(&ps, reset) == false, "score is not reset")
+	assertTrue(t, penalize(&ps, reset) == false, "banned too early")
+	assertTrue(t, penalize(&ps, reset) == true, "not banned")
+	assertTrue(t, ps.isBanned(host) == true, "not banned")
+
+	// never reset
+
+	var keep peerScores
+
+	assertTrue(t, penalize(&keep, 0) == false, "banned too early")
+	assertTrue(t, penalize(&keep, 0) == false, "banned too early")
+
+	time.Sleep(reset)
+
+	assertTrue(t, penalize(&keep, 0) == true, "score is reset")
+
+}
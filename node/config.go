@@ -3,11 +3,13 @@ package node
 import (
 	"flag"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/node/log"
+	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
@@ -24,9 +26,31 @@ const (
 	ResponseTimeout time.Duration = 59 * time.Second
 	Pings           time.Duration = 118 * time.Second
 	Public          bool          = false
+
+	MaxInFlightPerPeer int  = 128
+	AnnounceRate       int  = 0 // unlimited
+	AnnounceOnConnect  bool = true
+	AnnounceToOutgoing bool = false
+	RequestWorkers     int  = 64
+	RequestQueue       int  = 1024
+	DedupRequests      bool = true
+
+	IdleTimeout time.Duration = 0 // disabled
+	IdlePings   bool          = false
+
+	MaxMessageSize int = 0 // unlimited
+
+	BanThreshold  int           = 0 // disabled
+	BanDuration   time.Duration = 10 * time.Minute
+	BanScoreReset time.Duration = 10 * time.Minute
+
+	UDPAnnounceAddr     string        = "" // don't announce
+	UDPAnnounceListen   string        = "" // don't receive announces
+	UDPAnnounceInterval time.Duration = 5 * time.Second
 )
 
-// Addresses are discovery addresses
+// Addresses represents list of addresses
+// (see Discovery, AllowList and DenyList)
 type Addresses []string
 
 // String implements flag.Value interface
@@ -102,6 +126,32 @@ type OnSubscribeRemoteFunc func(c *Conn, feed cipher.PubKey) (reject error)
 // only
 type OnUnsubscribeRemoteFunc func(c *Conn, feed cipher.PubKey)
 
+// OnAnnounceFunc represents callback that called
+// when a remote peer announces keys of new objects
+// it has stored (see msg.Announce). The callback
+// have informative role only
+type OnAnnounceFunc func(c *Conn, hashes []cipher.SHA256)
+
+// OnMessageFunc represents callback that called
+// when a custom message (see msg.Register) received.
+// It's possible to terminate connection returning
+// error. Custom messages ignored if the callback is
+// not set. The callback is not called for messages
+// that implement MsgHandler interface
+type OnMessageFunc func(c *Conn, m msg.Msg) (terminate error)
+
+// A MsgHandler is custom message (see msg.Register)
+// that handles itself. If received custom message
+// implements the interface, then its Handle method
+// called instead of the OnMessage callback. Thus, a
+// message type registered once, handles itself
+// everywhere. It's possible to terminate connection
+// returning error
+type MsgHandler interface {
+	msg.Msg
+	Handle(c *Conn) (terminate error)
+}
+
 // NetConfig represents configurations of
 // a TCP or UDP network
 type NetConfig struct {
@@ -187,9 +237,138 @@ type Config struct {
 	// limit.
 	MaxFillingTime time.Duration
 
+	// MaxInFlightPerPeer is limit of requests of a
+	// remote peer that handled concurrently. If a
+	// peer reaches the limit, then the Node replies
+	// with ErrTooManyRequests to new requests of the
+	// peer, instead of queuing them. Set it to zero
+	// to disable the limit.
+	MaxInFlightPerPeer int
+
+	// RequestWorkers is number of workers serving
+	// object requests of all peers. The workers are
+	// started by the Node and serve requests outside
+	// the receiving loop of a connection. Thus it's
+	// limit of object requests served concurrently.
+	// It must be positive.
+	RequestWorkers int
+	// RequestQueue is limit of object requests of
+	// all peers waiting for a free worker (see
+	// RequestWorkers). If the queue is full, then
+	// the Node replies with ErrTooManyRequests.
+	// Zero means that a request is rejected if all
+	// workers are busy.
+	RequestQueue int
+	// DedupRequests turns on deduplication of object
+	// requests. If an object is requested from a peer,
+	// then the Node doesn't request the same object
+	// from other peers, until the first request is
+	// resolved or failed (e.g. timed out). If the first
+	// request fails, then the object is requested again
+	DedupRequests bool
+
+	// IdleTimeout is time limit a connection can be
+	// not used for. If the Node doesn't send and doesn't
+	// receive messages to (from) a peer during the time,
+	// then the connection is closed with ErrIdleTimeout.
+	// Set it to zero to disable the limit.
+	IdleTimeout time.Duration
+	// IdlePings is true if Ping and Pong messages keep
+	// a connection used (see IdleTimeout). By default,
+	// only other messages are considered
+	IdlePings bool
+
+	// MaxMessageSize is max size of a message the Node
+	// accepts from a peer. If a peer sends a larger
+	// message, then the connection is closed with
+	// ErrMessageTooLarge before the message decoded.
+	// The size is size of encoded message including
+	// 8 bytes of the seq and rseq. Set it to zero to
+	// disable the limit. The limit should be greater
+	// then MaxObjectSize of the skyobject.Config,
+	// otherwise peers can't send large objects. The
+	// limit is checked after the transport read the
+	// message, since transports of the Node read whole
+	// frames and have no frame size limit. Thus, the
+	// limit doesn't bound memory allocated for a message,
+	// but large messages are not decoded and handled,
+	// and a peer that sends them is disconnected
+	MaxMessageSize int
+
+	// BanThreshold is negative score of a peer. A peer
+	// gets negative score for every invalid message,
+	// invalid response, too large message or invalid
+	// Root. If score of a peer drops below the threshold,
+	// then the peer is banned for BanDuration. Connections
+	// to and from a banned peer (IP address) are refused
+	// with ErrBanned. Set it to zero to disable banning
+	BanThreshold int
+	// BanDuration is time a peer is banned for
+	// (see BanThreshold)
+	BanDuration time.Duration
+	// BanScoreReset is time without penalties after
+	// which score of a peer is reset to zero. Thus,
+	// rare errors of a long-lived peer don't sum up
+	// to a ban. Set it to zero to keep scores until
+	// a ban (see BanThreshold)
+	BanScoreReset time.Duration
+
+	// AllowList is list of addresses incoming
+	// connections allowed from. An element of the
+	// list can be IP address, exact address with
+	// port (like "192.168.0.1:8870") or CIDR range
+	// (like "192.168.0.0/16"). If the list is not
+	// empty, then connections from other addresses
+	// are closed with ErrNotAllowed. Outgoing
+	// connections are not affected
+	AllowList Addresses
+	// DenyList is list of addresses incoming
+	// connections denied from. Elements of the list
+	// are the same as elements of the AllowList. The
+	// DenyList is checked before the AllowList
+	DenyList Addresses
+
+	// AnnounceRate is limit of Root objects per second
+	// the Node sends to a peer. If the limit reached,
+	// then Root objects will be sent later keeping only
+	// latest Root of a head in the queue. The limit
+	// allows bursts up to AnnounceRate Root objects.
+	// Set it to zero to disable the limit.
+	AnnounceRate int
+	// AnnounceOnConnect turns on sending last Root
+	// objects of feeds a connection is subscribed to
+	// right after the connection has been established,
+	// and last Root of a feed right after a subscription
+	// to the feed (by both sides). If it's false, then
+	// peers have to request Root objects explicitly
+	// (see Preview and NewerRoot methods of Conn). The
+	// SyncDone message is sent anyway. Root objects
+	// published later are sent regardless the flag
+	AnnounceOnConnect bool
+	// AnnounceToOutgoing turns on sending last Root
+	// objects to outgoing connections too. By default
+	// last Root objects are sent on connect and after
+	// a subscription to incoming connections only,
+	// thus a dialing peer gets Root objects of peer it
+	// dials to, but doesn't send its own. Set it to
+	// true for bidirectional sync regardless of who
+	// dialed
+	AnnounceToOutgoing bool
+
 	// RPC is RPC listening address. Empty string
 	// disables RPC.
 	RPC string
+	// RPCToken is token RPC clients must present
+	// before any RPC method is served (see
+	// NewRPCClientToken). Connections with wrong
+	// token are rejected. Empty string disables
+	// the authentication.
+	RPCToken string
+	// ExportDir is directory ExportFeed writes files
+	// to. The ExportFeed accepts name of a file in the
+	// directory, and doesn't overwrite existing files.
+	// Empty string disables exports
+	ExportDir string
 
 	//
 	// Networks
@@ -201,6 +380,29 @@ type Config struct {
 	// UDP configurations
 	UDP NetConfig
 
+	//
+	// UDP announce
+	//
+
+	// UDPAnnounceAddr is address the Node sends UDP
+	// announces to. An announce is a datagram that
+	// contains hashes of last Root objects of feeds the
+	// Node shares and TCP port of the Node. The address
+	// can be a multicast group, a broadcast address or
+	// address of a peer. Receiving an announce, a peer
+	// pulls announced Root objects it doesn't have using
+	// TCP. Thus, the Node should listen TCP with an
+	// explicit port. Blank string disables announcing
+	UDPAnnounceAddr string
+	// UDPAnnounceListen is address the Node receives
+	// UDP announces on. If IP of the address is a
+	// multicast group, then the Node joins the group.
+	// Blank string disables receiving
+	UDPAnnounceListen string
+	// UDPAnnounceInterval is interval of sending
+	// UDP announces (see UDPAnnounceAddr)
+	UDPAnnounceInterval time.Duration
+
 	//
 	// Connection callbacks
 	//
@@ -212,6 +414,10 @@ type Config struct {
 	// connections. See OnDisconnectFunc for details.
 	OnDisconnect OnDisconnectFunc
 
+	// OnMessage is callback for custom messages.
+	// See OnMessageFunc for details.
+	OnMessage OnMessageFunc
+
 	//
 	// Discovery
 	//
@@ -263,6 +469,14 @@ type Config struct {
 	// when new Root object filled and can be
 	// used. See OnRootFilledFunc for details.
 	OnFillingBreaks OnFillingBreaksFunc
+
+	//
+	// Objects related callbacks
+	//
+
+	// OnAnnounce is callback for announced objects.
+	// See OnAnnounceFunc for details
+	OnAnnounce OnAnnounceFunc
 }
 
 // NewConfig returns new Config with
@@ -281,6 +495,19 @@ func NewConfig() (c *Config) {
 	c.MaxConnections = MaxConnections
 	c.MaxFillingTime = MaxFillingTime
 	c.MaxHeads = MaxHeads
+	c.MaxInFlightPerPeer = MaxInFlightPerPeer
+	c.RequestWorkers = RequestWorkers
+	c.RequestQueue = RequestQueue
+	c.DedupRequests = DedupRequests
+	c.AnnounceRate = AnnounceRate
+	c.AnnounceOnConnect = AnnounceOnConnect
+	c.AnnounceToOutgoing = AnnounceToOutgoing
+	c.IdleTimeout = IdleTimeout
+	c.IdlePings = IdlePings
+	c.MaxMessageSize = MaxMessageSize
+	c.BanThreshold = BanThreshold
+	c.BanDuration = BanDuration
+	c.BanScoreReset = BanScoreReset
 
 	c.TCP.Listen = ListenTCP
 	c.TCP.Pings = Pings
@@ -289,6 +516,10 @@ func NewConfig() (c *Config) {
 	c.UDP.Listen = ListenUDP
 	c.UDP.ResponseTimeout = ResponseTimeout
 
+	c.UDPAnnounceAddr = UDPAnnounceAddr
+	c.UDPAnnounceListen = UDPAnnounceListen
+	c.UDPAnnounceInterval = UDPAnnounceInterval
+
 	c.RPC = RPCAddress
 	c.Public = Public
 
@@ -338,11 +569,94 @@ func (c *Config) FromFlags() {
 		c.MaxHeads,
 		"max heads of a feed allowed")
 
+	flag.IntVar(&c.MaxInFlightPerPeer,
+		"max-in-flight-per-peer",
+		c.MaxInFlightPerPeer,
+		"max requests of a peer handled concurrently")
+
+	flag.IntVar(&c.RequestWorkers,
+		"request-workers",
+		c.RequestWorkers,
+		"number of workers serving object requests of all peers")
+
+	flag.IntVar(&c.RequestQueue,
+		"request-queue",
+		c.RequestQueue,
+		"max object requests of all peers waiting for a worker")
+
+	flag.BoolVar(&c.DedupRequests,
+		"dedup-requests",
+		c.DedupRequests,
+		"don't request an object requested from another peer")
+
+	flag.DurationVar(&c.IdleTimeout,
+		"idle-timeout",
+		c.IdleTimeout,
+		"close connections not used for the time")
+
+	flag.BoolVar(&c.IdlePings,
+		"idle-pings",
+		c.IdlePings,
+		"pings keep connections used (see idle-timeout)")
+
+	flag.IntVar(&c.MaxMessageSize,
+		"max-message-size",
+		c.MaxMessageSize,
+		"max size of a received message, zero to disable")
+
+	flag.IntVar(&c.BanThreshold,
+		"ban-threshold",
+		c.BanThreshold,
+		"ban peers with score below, negative, zero to disable")
+
+	flag.DurationVar(&c.BanDuration,
+		"ban-duration",
+		c.BanDuration,
+		"time a peer is banned for")
+
+	flag.DurationVar(&c.BanScoreReset,
+		"ban-score-reset",
+		c.BanScoreReset,
+		"reset score of a peer after this time without penalties")
+
+	flag.Var(&c.AllowList,
+		"allow",
+		"allow incoming connections from address or CIDR range (repeatable)")
+
+	flag.Var(&c.DenyList,
+		"deny",
+		"deny incoming connections from address or CIDR range (repeatable)")
+
+	flag.IntVar(&c.AnnounceRate,
+		"announce-rate",
+		c.AnnounceRate,
+		"max Root objects per second sent to a peer")
+
+	flag.BoolVar(&c.AnnounceOnConnect,
+		"announce-on-connect",
+		c.AnnounceOnConnect,
+		"send last Root objects to a peer after connection and subscription")
+
+	flag.BoolVar(&c.AnnounceToOutgoing,
+		"announce-to-outgoing",
+		c.AnnounceToOutgoing,
+		"send last Root objects to outgoing connections too")
+
 	flag.StringVar(&c.RPC,
 		"rpc",
 		c.RPC,
 		"RPC listening address")
 
+	flag.StringVar(&c.RPCToken,
+		"rpc-token",
+		c.RPCToken,
+		"token RPC clients must present (empty disables)")
+
+	flag.StringVar(&c.ExportDir,
+		"export-dir",
+		c.ExportDir,
+		"directory for exported feeds (empty disables exports)")
+
 	// TCP
 
 	flag.StringVar(&c.TCP.Listen,
@@ -377,6 +691,23 @@ func (c *Config) FromFlags() {
 		c.UDP.Pings,
 		"pings interval of UDP connections")
 
+	// UDP announce
+
+	flag.StringVar(&c.UDPAnnounceAddr,
+		"udp-announce",
+		c.UDPAnnounceAddr,
+		"address to send UDP announces of Root objects to")
+
+	flag.StringVar(&c.UDPAnnounceListen,
+		"udp-announce-listen",
+		c.UDPAnnounceListen,
+		"address to receive UDP announces of Root objects on")
+
+	flag.DurationVar(&c.UDPAnnounceInterval,
+		"udp-announce-interval",
+		c.UDPAnnounceInterval,
+		"interval of sending UDP announces")
+
 	// public
 
 	flag.BoolVar(&c.Public,
@@ -386,8 +717,42 @@ func (c *Config) FromFlags() {
 
 }
 
-// Validate configurations. The Validate doesn't
-// validates addresses (TCP, UDP or RPC)
+// validate listening address, if it's not blank
+func validateAddress(name, address string) (err error) {
+
+	if address == "" {
+		return // disabled
+	}
+
+	if _, _, err = net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("invalid %s address %q: %v", name, address, err)
+	}
+
+	return
+}
+
+// validate configurations of TCP or UDP
+func (n *NetConfig) validate(name string) (err error) {
+
+	if err = validateAddress(name, n.Listen); err != nil {
+		return
+	}
+
+	if n.ResponseTimeout < 0 {
+		return fmt.Errorf("negative %s ResponseTimeout %s",
+			name, n.ResponseTimeout)
+	}
+
+	if n.Pings < 0 {
+		return fmt.Errorf("negative %s Pings %s", name, n.Pings)
+	}
+
+	return
+}
+
+// Validate configurations. The Validate checks only
+// format of listening addresses (TCP, UDP or RPC),
+// it doesn't check that the addresses can be used
 func (c *Config) Validate() (err error) {
 
 	// nothing to validate in the Logger configurations
@@ -399,7 +764,79 @@ func (c *Config) Validate() (err error) {
 		}
 	}
 
-	// nothing to validate in the Node configs
+	if c.MaxConnections < 0 {
+		return fmt.Errorf("negative MaxConnections %d", c.MaxConnections)
+	}
+
+	if c.MaxHeads < 0 {
+		return fmt.Errorf("negative MaxHeads %d", c.MaxHeads)
+	}
+
+	if c.MaxFillingTime < 0 {
+		return fmt.Errorf("negative MaxFillingTime %s", c.MaxFillingTime)
+	}
+
+	if err = c.TCP.validate("TCP"); err != nil {
+		return
+	}
+
+	if err = c.UDP.validate("UDP"); err != nil {
+		return
+	}
+
+	if err = validateAddress("RPC", c.RPC); err != nil {
+		return
+	}
+
+	if c.MaxInFlightPerPeer < 0 {
+		return fmt.Errorf("negative MaxInFlightPerPeer %d",
+			c.MaxInFlightPerPeer)
+	}
+
+	if c.RequestWorkers <= 0 {
+		return fmt.Errorf("not positive RequestWorkers %d", c.RequestWorkers)
+	}
+
+	if c.RequestQueue < 0 {
+		return fmt.Errorf("negative RequestQueue %d", c.RequestQueue)
+	}
+
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("negative IdleTimeout %s", c.IdleTimeout)
+	}
+
+	if c.MaxMessageSize < 0 {
+		return fmt.Errorf("negative MaxMessageSize %d", c.MaxMessageSize)
+	}
+
+	if c.BanThreshold > 0 {
+		return fmt.Errorf("positive BanThreshold %d", c.BanThreshold)
+	}
+
+	if c.BanThreshold < 0 && c.BanDuration <= 0 {
+		return fmt.Errorf("invalid BanDuration %s", c.BanDuration)
+	}
+
+	if c.BanScoreReset < 0 {
+		return fmt.Errorf("negative BanScoreReset %s", c.BanScoreReset)
+	}
+
+	if c.AnnounceRate < 0 {
+		return fmt.Errorf("negative AnnounceRate %d", c.AnnounceRate)
+	}
+
+	if c.UDPAnnounceAddr != "" && c.UDPAnnounceInterval <= 0 {
+		return fmt.Errorf("invalid UDPAnnounceInterval %s",
+			c.UDPAnnounceInterval)
+	}
+
+	if _, err = parseAddressList(c.AllowList); err != nil {
+		return fmt.Errorf("invalid AllowList: %v", err)
+	}
+
+	if _, err = parseAddressList(c.DenyList); err != nil {
+		return fmt.Errorf("invalid DenyList: %v", err)
+	}
 
 	return
 
@@ -0,0 +1,80 @@
+package node
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Validate(t *testing.T) {
+
+	assertNil(t, getTestConfig("test").Validate())
+
+	for _, tt := range []struct {
+		name   string
+		modify func(c *Config)
+		want   string // part of the error
+	}{
+		{"max connections", func(c *Config) {
+			c.MaxConnections = -1
+		}, "MaxConnections"},
+		{"max heads", func(c *Config) {
+			c.MaxHeads = -1
+		}, "MaxHeads"},
+		{"max filling time", func(c *Config) {
+			c.MaxFillingTime = -time.Second
+		}, "MaxFillingTime"},
+		{"tcp listen", func(c *Config) {
+			c.TCP.Listen = "127.0.0.1"
+		}, "TCP address"},
+		{"tcp response timeout", func(c *Config) {
+			c.TCP.ResponseTimeout = -time.Second
+		}, "TCP ResponseTimeout"},
+		{"udp listen", func(c *Config) {
+			c.UDP.Listen = "127.0.0.1:80:80"
+		}, "UDP address"},
+		{"udp pings", func(c *Config) {
+			c.UDP.Pings = -time.Second
+		}, "UDP Pings"},
+		{"ban threshold", func(c *Config) {
+			c.BanThreshold = 10
+		}, "BanThreshold"},
+		{"ban duration", func(c *Config) {
+			c.BanThreshold, c.BanDuration = -10, 0
+		}, "BanDuration"},
+		{"ban score reset", func(c *Config) {
+			c.BanScoreReset = -time.Second
+		}, "BanScoreReset"},
+		{"request workers", func(c *Config) {
+			c.RequestWorkers = 0
+		}, "RequestWorkers"},
+		{"request queue", func(c *Config) {
+			c.RequestQueue = -1
+		}, "RequestQueue"},
+		{"rpc", func(c *Config) {
+			c.RPC = "localhost"
+		}, "RPC address"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var conf = getTestConfig("test")
+			tt.modify(conf)
+
+			var err = conf.Validate()
+
+			if err == nil {
+				t.Fatal("missing error")
+			}
+
+			if strings.Contains(err.Error(), tt.want) == false {
+				t.Errorf("not descriptive error %q, want %q", err, tt.want)
+			}
+
+			if _, err = NewNode(conf); err == nil {
+				t.Error("NewNode accepts invalid configurations")
+			}
+
+		})
+	}
+
+}
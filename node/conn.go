@@ -34,6 +34,20 @@ type Conn struct {
 	seq  uint32                    // messege seq number (for request-response)
 	reqs map[uint32]chan<- msg.Msg // requests
 
+	inflight int32 // requests of the peer handled now
+
+	used int64 // last use, unix nano (see IdleTimeout)
+	seen int64 // last received message, unix nano
+
+	// Root objects to send (see AnnounceRate)
+	roots     map[rootHead]*registry.Root
+	rootso    []rootHead    // order
+	announceq chan struct{} // wake up
+	syncq     bool          // SyncDone queued (see sendEverythingWeHave)
+	synco     int           // Root objects to send before the SyncDone
+
+	synced bool // SyncDone received
+
 	// # stat
 	//
 	// TODO (kostyarin): stat without mutexes to do not slow down the connection
@@ -63,9 +77,14 @@ func (n *Node) newConnection(
 
 	c.reqs = make(map[uint32]chan<- msg.Msg)
 
+	c.roots = make(map[rootHead]*registry.Root)
+	c.announceq = make(chan struct{}, 1)
+
 	c.sendq = fc.GetChanOut()
 	c.closeq = make(chan struct{})
 
+	c.seen = time.Now().UnixNano()
+
 	n.addPendingConn(c)
 
 	//
@@ -79,6 +98,17 @@ func (n *Node) newConnection(
 func (c *Conn) run() {
 	c.await.Add(1)
 	go c.receiving()
+
+	if c.n.config.AnnounceRate > 0 {
+		c.await.Add(1)
+		go c.announcing()
+	}
+
+	if c.n.config.IdleTimeout > 0 {
+		c.use(nil)
+		c.await.Add(1)
+		go c.idling(c.n.config.IdleTimeout)
+	}
 }
 
 func (c *Conn) decodeRaw(raw []byte) (seq, rseq uint32, m msg.Msg, err error) {
@@ -198,6 +228,16 @@ func (c *Conn) RemoteFeeds() (feeds []cipher.PubKey, err error) {
 }
 
 func (c *Conn) sendRoot(r *registry.Root) {
+
+	if c.n.config.AnnounceRate > 0 {
+		c.pushRoot(r) // send later
+		return
+	}
+
+	c.sendRootMsg(r)
+}
+
+func (c *Conn) sendRootMsg(r *registry.Root) {
 	c.sendMsg(c.nextSeq(), 0, &msg.Root{
 		Feed:  r.Pub,
 		Nonce: r.Nonce,
@@ -232,15 +272,41 @@ func (c *Conn) sendLastRoot(pk cipher.PubKey) {
 // the (*Node).Share method. If request fails, then the feed
 // is not removed. E.g. if the Subscribe method returns error
 // then it probably adds given feed to the Node, but request
-// fails. Or it can returns error of the (*Node).Share
+// fails. Or it can returns error of the (*Node).Share.
+// The Subscribe returns ErrBlankFeed or *InvalidFeedError
+// if given feed is blank or malformed. After the
+// subscription both peers push last Root of the feed,
+// if their AnnounceOnConnect is true, but a peer pushes
+// to its outgoing connection only if its
+// AnnounceToOutgoing is true
 func (c *Conn) Subscribe(feed cipher.PubKey) (err error) {
 
+	if err = validateFeed(feed); err != nil {
+		return
+	}
+
 	// add the feed to node
 
 	if err = c.n.Share(feed); err != nil {
 		return
 	}
 
+	// the remote peer pushes its last Root right after
+	// the Ok reply, thus the connection should be added
+	// to the feed before the request, otherwise the Root
+	// can be dropped as received from not subscribed
+	// connection
+
+	if c.n.fs.hasConnFeed(c, feed) == false {
+		c.n.fs.addConnFeed(c, feed)
+
+		defer func() {
+			if err != nil {
+				c.n.fs.delConnFeed(c, feed)
+			}
+		}()
+	}
+
 	var reply msg.Msg
 
 	if reply, err = c.sendRequest(&msg.Sub{Feed: feed}); err != nil {
@@ -264,8 +330,7 @@ func (c *Conn) Subscribe(feed cipher.PubKey) (err error) {
 		return
 	}
 
-	c.n.fs.addConnFeed(c, feed)
-	c.sendLastRoot(feed)
+	c.sendInitialRoot(feed)
 	return
 }
 
@@ -276,8 +341,15 @@ func (c *Conn) unsubscribe(pk cipher.PubKey) {
 	})
 }
 
-// Unsubscribe from given feed of remote peer
-func (c *Conn) Unsubscribe(feed cipher.PubKey) {
+// Unsubscribe from given feed of remote peer. The
+// Unsubscribe returns ErrBlankFeed or *InvalidFeedError
+// if given feed is blank or malformed
+func (c *Conn) Unsubscribe(feed cipher.PubKey) (err error) {
+
+	if err = validateFeed(feed); err != nil {
+		return
+	}
+
 	c.n.fs.delConnFeed(c, feed)
 	c.unsubscribe(feed) // notify peer
 	return
@@ -332,34 +404,72 @@ func (c *Conn) Preview(
 	return
 }
 
-// implements skyobject.Getter
-// wrapping the Conn
-type cget struct {
-	c *Conn
+// NewerRoot requests remote peer for last Root of given
+// feed, that is newer then given seq. The request uses
+// active head of the feed of the remote peer. If the peer
+// doesn't have newer Root, or the connection is not
+// subscribed to the feed, then the Found field of the
+// reply is false. The request has timeout configured by
+// Config. The NewerRoot doesn't pull the Root
+func (c *Conn) NewerRoot(
+	feed cipher.PubKey, // : feed to check
+	seq uint64, //         : newer then this
+) (
+	info *msg.RootInfo, // : reply
+	err error, //          : error if any
+) {
+	return c.requestRootInfo(&msg.RqRoot{Feed: feed, Seq: seq, HasSeq: true})
 }
 
-func (c *cget) Get(key cipher.SHA256) (val []byte, err error) {
+// LastRootInfo is like the NewerRoot, but it requests
+// last Root of given feed regardless its seq. Use it if
+// the Node doesn't have Root objects of the feed yet
+func (c *Conn) LastRootInfo(
+	feed cipher.PubKey, // : feed to check
+) (
+	info *msg.RootInfo, // : reply
+	err error, //          : error if any
+) {
+	return c.requestRootInfo(&msg.RqRoot{Feed: feed})
+}
+
+func (c *Conn) requestRootInfo(
+	rq *msg.RqRoot, //     : the request
+) (
+	info *msg.RootInfo, // : reply
+	err error, //          : error if any
+) {
 
 	var reply msg.Msg
-	if reply, err = c.c.sendRequest(&msg.RqObject{Key: key}); err != nil {
+	if reply, err = c.sendRequest(rq); err != nil {
 		return
 	}
 
 	switch x := reply.(type) {
-	case *msg.Object:
-		if cipher.SumSHA256(x.Value) != key {
-			return nil, errors.New("wrong object received (different hash)")
+	case *msg.RootInfo:
+		if x.Feed != rq.Feed {
+			return nil, ErrInvalidResponse
 		}
-		val = x.Value
+		info = x
 	case *msg.Err:
-		return nil, errors.New("error: " + x.Err)
+		err = errors.New(x.Err)
 	default:
-		return nil, fmt.Errorf("invalid msg type received: %T", reply)
+		err = fmt.Errorf("invalid response type %T", reply)
 	}
 
 	return
 }
 
+// implements skyobject.Getter
+// wrapping the Conn
+type cget struct {
+	c *Conn
+}
+
+func (c *cget) Get(key cipher.SHA256) (val []byte, err error) {
+	return c.c.n.requestObject(c.c, key)
+}
+
 func (c *Conn) getter() (cg skyobject.Getter) {
 	return &cget{c}
 }
@@ -409,6 +519,7 @@ func (c *Conn) sendMsg(seq, rseq uint32, m msg.Msg) {
 
 	c.n.Debugf(MsgSendPin, "[%s] send %d %T", c.String(), rseq, m)
 
+	c.use(m)
 	c.sendRaw(c.encodeMsg(seq, rseq, m))
 }
 
@@ -423,7 +534,15 @@ func (c *Conn) sendRaw(raw []byte) {
 
 func (c *Conn) fatality(args ...interface{}) {
 
-	var err = errors.New(fmt.Sprint(args...))
+	var err error
+
+	if len(args) == 1 {
+		err, _ = args[0].(error) // keep single error
+	}
+
+	if err == nil {
+		err = errors.New(fmt.Sprint(args...))
+	}
 
 	c.n.Print("[ERR] ", err)
 	c.close(err)
@@ -433,13 +552,17 @@ func (c *Conn) receiving() {
 
 	c.n.Debugf(ConnPin, "[%s] receiving", c.String())
 
-	defer c.Close()      //
-	defer c.await.Done() //
+	var reason error // closing reason
+
+	defer func() { c.close(reason) }() //
+	defer c.await.Done()               //
 
 	var (
 		receiveq = c.GetChanIn()
 		closeq   = c.closeq
 
+		maxSize = c.n.config.MaxMessageSize
+
 		seq, rseq uint32
 		m         msg.Msg
 		err       error
@@ -458,10 +581,21 @@ func (c *Conn) receiving() {
 				return // closed
 			}
 
+			if maxSize > 0 && len(raw) > maxSize {
+				c.n.Printf("[ERR] [%s] message is too large: %d",
+					c.String(),
+					len(raw))
+				c.n.penalize(c, penaltyTooLargeMessage)
+				reason = ErrMessageTooLarge // close after the Done
+				return
+			}
+
 			// [ 4 seq ][ 4 rseq ][ 1 msg type ]
 
 			if len(raw) < 9 {
-				c.fatality("invalid messege received: samll size")
+				c.n.penalize(c, penaltyInvalidMessage)
+				reason = errors.New("invalid messege received: samll size")
+				c.n.Print("[ERR] ", reason) // close after the Done
 				return
 			}
 
@@ -474,20 +608,30 @@ func (c *Conn) receiving() {
 			raw = raw[4:]
 
 			if m, err = msg.Decode(raw); err != nil {
-				c.fatality("can't decode received messege: ", err)
+				c.n.penalize(c, penaltyInvalidMessage)
+				reason = fmt.Errorf("can't decode received messege: %v", err)
+				c.n.Print("[ERR] ", reason) // close after the Done
 				return
 			}
 
 			c.n.Debugf(MsgReceivePin, "[%s] receive %T", c.String(), m)
 
+			c.use(m)
+			atomic.StoreInt64(&c.seen, time.Now().UnixNano())
+
 			// the messege can be a response for a request
 			if rq, ok := c.isResponse(rseq); ok == true {
 				rq <- m
 				continue
 			}
 
-			if err = c.handle(seq, m); err != nil {
-				c.fatality("error handling messege: ", err)
+			if err = c.handle(seq, m); err == ErrBanned {
+				reason = ErrBanned // close after the Done
+				return
+			} else if err != nil {
+				c.n.penalize(c, penaltyInvalidMessage)
+				reason = fmt.Errorf("error handling messege: %v", err)
+				c.n.Print("[ERR] ", reason) // close after the Done
 				return
 			}
 
@@ -606,8 +750,14 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	// objects
 
 	case *msg.RqObject: // <- RqO (key, prefetch)
-		c.await.Add(1)
-		go c.handleRqObject(seq, x)
+		if c.acquireInFlight() == false {
+			c.sendErr(seq, ErrTooManyRequests) // throttle
+			return
+		}
+		if c.n.queueRqObject(c, seq, x) == false {
+			c.releaseInFlight()
+			c.sendErr(seq, ErrTooManyRequests) // workers are busy
+		}
 		return
 
 	// preview
@@ -615,6 +765,38 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	case *msg.RqPreview: // -> RqPreview (feed)
 		return c.handleRqPreview(seq, x)
 
+	// root info
+
+	case *msg.RqRoot: // <- RqRoot (feed, seq, has seq)
+		return c.handleRqRoot(seq, x)
+
+	// root acknowledgment
+
+	case *msg.RootAck: // -> RootAck (feed, nonce, seq)
+		c.n.acks.ack(c.PeerID(), x)
+		return
+
+	// pings
+
+	case *msg.Ping: // <- Ping ()
+		c.sendMsg(c.nextSeq(), seq, &msg.Pong{})
+		return
+
+	case *msg.Pong: // -> Pong (delayed)
+		return
+
+	// initial sync
+
+	case *msg.SyncDone: // <- SyncDone ()
+		c.handleSyncDone()
+		return
+
+	// new objects
+
+	case *msg.Announce: // <- Announce (hashes)
+		c.n.onAnnounce(c, x.Hashes)
+		return
+
 	//
 	// delayed messeges (ignore them)
 	//
@@ -626,9 +808,14 @@ func (c *Conn) handle(seq uint32, m msg.Msg) (err error) {
 	case *msg.Err: // -> Err (delayed)
 	case *msg.Ok: // -> Ok (delayed)
 	case *msg.List: // -> List (delayed)
+	case *msg.RootInfo: // -> RootInfo (delayed)
 
 	default:
 
+		if m.Type().IsCustom() == true {
+			return c.n.onMessage(c, m)
+		}
+
 		return fmt.Errorf("invalid messege type %T", m)
 
 	}
@@ -678,7 +865,7 @@ func (c *Conn) handleSub(seq uint32, sub *msg.Sub) (_ error) {
 	c.n.fs.addConnFeed(c, sub.Feed)
 	c.sendOk(seq)
 
-	c.sendLastRoot(sub.Feed) // and push last Root
+	c.sendInitialRoot(sub.Feed) // and push last Root
 
 	return
 }
@@ -747,6 +934,9 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 
 	if err != nil {
 		c.n.Printf("[ERR] [%s] received Root error: %s", c.String(), err)
+		if c.n.penalize(c, penaltyInvalidRoot) == true {
+			return ErrBanned // close the connection
+		}
 		return // keep connection ?
 	}
 
@@ -761,13 +951,44 @@ func (c *Conn) handleRoot(root *msg.Root) (_ error) {
 	return
 }
 
-// async
+// acquireInFlight increments number of requests of
+// the peer handled now; it returns false if the peer
+// reaches the MaxInFlightPerPeer limit
+func (c *Conn) acquireInFlight() (ok bool) {
+
+	var limit = c.n.config.MaxInFlightPerPeer
+
+	if atomic.AddInt32(&c.inflight, 1) > int32(limit) && limit > 0 {
+		atomic.AddInt32(&c.inflight, -1)
+		return false
+	}
+
+	return true
+}
+
+func (c *Conn) releaseInFlight() {
+	atomic.AddInt32(&c.inflight, -1)
+}
+
+// InFlight returns number of requests of
+// the remote peer handled now
+func (c *Conn) InFlight() (n int) {
+	return int(atomic.LoadInt32(&c.inflight))
+}
+
+// called by a worker (see RequestWorkers)
 func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
-	defer c.await.Done()
+	defer c.releaseInFlight()
 
 	c.n.Debugf(MsgReceivePin, "[%s] handleRqObject %s", c.String(),
 		rq.Key.Hex()[:7])
 
+	select {
+	case <-c.closeq:
+		return // closed while the request waits for a worker
+	default:
+	}
+
 	var (
 		gc = make(chan skyobject.Object, 1)
 
@@ -810,6 +1031,8 @@ func (c *Conn) handleRqObject(seq uint32, rq *msg.RqObject) {
 		c.sendMsg(c.nextSeq(), seq, &msg.Err{}) // timeout
 	case <-c.closeq:
 		// closed
+	case <-c.n.closeq:
+		// the Node closed
 	}
 
 	return
@@ -839,3 +1062,33 @@ func (c *Conn) handleRqPreview(seq uint32, rqp *msg.RqPreview) (_ error) {
 
 	return
 }
+
+func (c *Conn) handleRqRoot(seq uint32, rq *msg.RqRoot) (_ error) {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleRqRoot %s/%d", c.String(),
+		rq.Feed.Hex()[:7], rq.Seq)
+
+	var info = &msg.RootInfo{Feed: rq.Feed}
+
+	// if the Node doesn't have the feed or a newer Root,
+	// or the connection is not subscribed to the feed
+	// (don't reveal feeds not shared with the peer),
+	// then the Found is false (that is not an error)
+
+	if c.n.fs.hasConnFeed(c, rq.Feed) == false {
+		c.sendMsg(c.nextSeq(), seq, info)
+		return
+	}
+
+	var r, err = c.n.c.LastRoot(rq.Feed, c.n.c.ActiveHead(rq.Feed))
+
+	if err == nil && (rq.HasSeq == false || r.Seq > rq.Seq) {
+		info.Nonce = r.Nonce
+		info.Seq = r.Seq
+		info.Hash = r.Hash
+		info.Found = true
+	}
+
+	c.sendMsg(c.nextSeq(), seq, info)
+	return
+}
@@ -0,0 +1,627 @@
+package node
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestConn_maxInFlightPerPeer(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		sn    *Node
+		rn    = getTestNodeNotListen("client")
+		err   error
+	)
+
+	defer rn.Close()
+
+	sconf.MaxInFlightPerPeer = 2
+
+	if sn, err = NewNode(sconf); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	var sc *Conn
+	for i := 0; i < 100; i++ {
+		if cs := sn.Connections(); len(cs) == 1 {
+			sc = cs[0]
+			break
+		}
+		time.Sleep(TM / 50)
+	}
+	assertTrue(t, sc != nil, "missing connection")
+
+	// saturate the limit requesting objects the
+	// server doesn't have (they wait for timeout)
+
+	var errs = make(chan error, 2)
+
+	for i := 0; i < 2; i++ {
+		go func(i byte) {
+			var _, err = c.getter().Get(cipher.SHA256{i + 1})
+			errs <- err
+		}(byte(i))
+	}
+
+	for i := 0; i < 100 && sc.InFlight() < 2; i++ {
+		time.Sleep(TM / 50)
+	}
+	assertTrue(t, sc.InFlight() == 2, "limit is not saturated")
+
+	// further request should be throttled
+
+	var tp = time.Now()
+
+	_, err = c.getter().Get(cipher.SHA256{3})
+
+	if err == nil {
+		t.Fatal("missing error")
+	} else if strings.Contains(err.Error(), ErrTooManyRequests.Error()) == false {
+		t.Fatal("unexpected error:", err)
+	}
+
+	assertTrue(t, time.Since(tp) < TM, "request is queued")
+	assertTrue(t, sc.InFlight() == 2, "wrong in-flight")
+
+	// release
+
+	for i := 0; i < 2; i++ {
+		if err = <-errs; err == nil {
+			t.Error("missing error")
+		}
+	}
+
+	for i := 0; i < 100 && sc.InFlight() > 0; i++ {
+		time.Sleep(TM / 50)
+	}
+	assertTrue(t, sc.InFlight() == 0, "in-flight requests are not released")
+
+}
+
+func TestConn_maxInFlightPerPeer_fill(t *testing.T) {
+
+	// the server throttles object requests of the
+	// client, but the client fills the Root anyway
+	// and doesn't close the connection (the filler
+	// requests objects one by one from a connection)
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+
+		filled  = make(chan *registry.Root, 1)
+		reasons = make(chan error, 2)
+	)
+
+	sconf.MaxInFlightPerPeer = 1
+	sconf.RequestWorkers = 1
+	sconf.RequestQueue = 0
+
+	cconf.BanThreshold = -1 // ban for any penalty
+	cconf.OnRootFilled = func(_ *Node, r *registry.Root) { filled <- r }
+	cconf.OnDisconnect = func(_ *Conn, reason error) { reasons <- reason }
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(cconf)
+	assertNil(t, err)
+	defer cn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+
+	var up *skyobject.Unpack
+	up, err = sn.Container().Unpack(sk, getTestRegistry())
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+
+	for i := 0; i < 10; i++ {
+		r.Refs = append(r.Refs, dynamicByValue(t, up, "test.User",
+			User{fmt.Sprint("user ", i), uint32(i), nil}))
+	}
+
+	assertNil(t, sn.Container().Save(up, r))
+
+	// another peer holds the only worker of the server
+	// requesting an object the server doesn't have
+
+	var hn = getTestNodeNotListen("hog")
+	defer hn.Close()
+
+	var hc *Conn
+	hc, err = hn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	go hc.getter().Get(cipher.SHA256{1, 2, 3})
+
+	var busy bool
+	for i := 0; i < 100 && busy == false; i++ {
+		for _, sc := range sn.Connections() {
+			busy = busy || sc.InFlight() == 1
+		}
+		time.Sleep(TM / 50)
+	}
+	assertTrue(t, busy == true, "the worker is not busy")
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, c.Subscribe(pk))
+
+	select {
+	case fr := <-filled:
+		assertTrue(t, fr.Hash == r.Hash, "wrong Root filled")
+	case reason := <-reasons:
+		t.Fatal("connection closed: ", reason)
+	case <-time.After(10 * TM):
+		t.Fatal("slow or missing Root")
+	}
+
+	assertTrue(t, cn.IsBanned(sn.TCP().Address()) == false, "banned")
+	assertTrue(t, len(cn.Connections()) == 1, "connection closed")
+
+}
+
+func TestConn_NewerRoot(t *testing.T) {
+
+	var (
+		sn  = getTestNode("server")
+		rn  = getTestNodeNotListen("client")
+		err error
+	)
+
+	defer sn.Close()
+	defer rn.Close()
+
+	var (
+		pk, sk   = cipher.GenerateKeyPair()
+		opk, osk = cipher.GenerateKeyPair() // not shared with the client
+	)
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, sn.Share(opk))
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	// no Root objects
+
+	var info *msg.RootInfo
+	info, err = c.NewerRoot(pk, 0)
+	assertNil(t, err)
+	assertTrue(t, info.Found == false, "found")
+	assertTrue(t, info.Feed == pk, "wrong feed")
+
+	info, err = c.LastRootInfo(pk)
+	assertNil(t, err)
+	assertTrue(t, info.Found == false, "found")
+
+	// two Root objects
+
+	var (
+		sc = sn.Container()
+		up *skyobject.Unpack
+	)
+
+	up, err = sc.Unpack(sk, getTestRegistry())
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+
+	r.Nonce = 9021
+	r.Pub = pk
+
+	assertNil(t, sc.Save(up, r)) // seq 0
+
+	// any Root, the seq 0 is valid
+
+	info, err = c.LastRootInfo(pk)
+	assertNil(t, err)
+	assertTrue(t, info.Found == true, "not found")
+	assertTrue(t, info.Seq == 0, "wrong seq")
+	assertTrue(t, info.Hash == r.Hash, "wrong hash")
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}))
+
+	assertNil(t, sc.Save(up, r)) // seq 1
+
+	for _, seq := range []uint64{0, 1} {
+
+		info, err = c.NewerRoot(pk, seq)
+		assertNil(t, err)
+
+		if seq < r.Seq {
+			assertTrue(t, info.Found == true, "not found")
+			assertTrue(t, info.Seq == r.Seq, "wrong seq")
+			assertTrue(t, info.Nonce == r.Nonce, "wrong nonce")
+			assertTrue(t, info.Hash == r.Hash, "wrong hash")
+		} else {
+			assertTrue(t, info.Found == false, "found")
+		}
+
+	}
+
+	// feed the connection is not subscribed to
+
+	var oup *skyobject.Unpack
+	oup, err = sc.Unpack(osk, getTestRegistry())
+	assertNil(t, err)
+	defer oup.Close()
+
+	var or = new(registry.Root)
+	or.Nonce, or.Pub = 9021, opk
+
+	assertNil(t, sc.Save(oup, or))
+
+	info, err = c.LastRootInfo(opk)
+	assertNil(t, err)
+	assertTrue(t, info.Found == false, "feed is revealed")
+
+	// unknown feed
+
+	var unknown, _ = cipher.GenerateKeyPair()
+
+	info, err = c.NewerRoot(unknown, 0)
+	assertNil(t, err)
+	assertTrue(t, info.Found == false, "found")
+
+}
+
+func TestConn_Subscribe_bothDirections(t *testing.T) {
+
+	// only the client dials, but Root objects
+	// (and objects) propagate in both directions
+
+	var (
+		sc = getTestConfig("server")
+		cc = getTestConfigNotListen("client")
+
+		sfilled = make(chan *registry.Root, 1)
+		cfilled = make(chan *registry.Root, 1)
+	)
+
+	cc.AnnounceToOutgoing = true
+
+	sc.OnRootFilled = func(_ *Node, r *registry.Root) { sfilled <- r }
+	cc.OnRootFilled = func(_ *Node, r *registry.Root) { cfilled <- r }
+
+	var sn, err = NewNode(sc)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(cc)
+	assertNil(t, err)
+	defer cn.Close()
+
+	var (
+		spk, ssk = cipher.GenerateKeyPair()
+		cpk, csk = cipher.GenerateKeyPair()
+	)
+
+	for _, n := range []*Node{sn, cn} {
+		assertNil(t, n.Share(spk))
+		assertNil(t, n.Share(cpk))
+	}
+
+	// Root objects saved before the connection
+
+	var save = func(n *Node, pk cipher.PubKey, sk cipher.SecKey,
+		name string) (r *registry.Root, dr registry.Dynamic) {
+
+		var up, err = n.Container().Unpack(sk, getTestRegistry())
+		assertNil(t, err)
+		defer up.Close()
+
+		dr = dynamicByValue(t, up, "test.User", User{name, 19, nil})
+
+		r = new(registry.Root)
+		r.Pub, r.Nonce = pk, 1
+		r.Refs = []registry.Dynamic{dr}
+
+		assertNil(t, n.Container().Save(up, r))
+		return
+	}
+
+	var (
+		sr, sd = save(sn, spk, ssk, "Alice")
+		cr, cd = save(cn, cpk, csk, "Bob")
+	)
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, c.Subscribe(spk))
+
+	// the incoming connection of the server
+
+	var scs = sn.ConnectionsOfFeed(spk)
+	assertTrue(t, len(scs) == 1, "wrong number of connections")
+	assertTrue(t, scs[0].IsIncoming() == true, "not incoming")
+
+	assertNil(t, scs[0].Subscribe(cpk))
+
+	var check = func(n *Node, filled <-chan *registry.Root,
+		r *registry.Root, dr registry.Dynamic) {
+
+		select {
+		case fr := <-filled:
+			assertTrue(t, fr.Hash == r.Hash, "wrong Root filled")
+		case <-time.After(TM):
+			t.Fatal("slow or missing Root")
+		}
+
+		var _, _, err = n.Container().Get(dr.Hash, 0)
+		assertNil(t, err)
+	}
+
+	check(cn, cfilled, sr, sd) // server -> client
+	check(sn, sfilled, cr, cd) // client -> server
+
+}
+
+func TestConn_invalidFeed(t *testing.T) {
+
+	var sn, err = NewNode(getTestConfig("server"))
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(getTestConfigNotListen("client"))
+	assertNil(t, err)
+	defer cn.Close()
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	// blank
+
+	assertTrue(t, c.Subscribe(cipher.PubKey{}) == ErrBlankFeed,
+		"Subscribe: missing ErrBlankFeed")
+	assertTrue(t, c.Unsubscribe(cipher.PubKey{}) == ErrBlankFeed,
+		"Unsubscribe: missing ErrBlankFeed")
+
+	// malformed
+
+	var malformed = cipher.PubKey{1, 2, 3}
+
+	err = c.Subscribe(malformed)
+	if ife, ok := err.(*InvalidFeedError); ok == false {
+		t.Error("Subscribe: unexpected error:", err)
+	} else if ife.Feed() != malformed {
+		t.Error("wrong feed of the error")
+	}
+
+	_, ok := c.Unsubscribe(malformed).(*InvalidFeedError)
+	assertTrue(t, ok == true, "Unsubscribe: missing *InvalidFeedError")
+
+	assertTrue(t, len(cn.Feeds()) == 0, "feed added")
+
+}
+
+func TestConn_RequestWorkers(t *testing.T) {
+
+	var sc = getTestConfig("server")
+	sc.RequestWorkers = 2
+	sc.RequestQueue = 2
+
+	var sn, err = NewNode(sc)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var rn = getTestNodeNotListen("client")
+	defer rn.Close()
+
+	var c *Conn
+	c, err = rn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	// requests of objects the server doesn't have hold
+	// workers up to the response timeout; requests above
+	// the workers and the queue are rejected
+
+	const (
+		requests = 20
+		served   = 4 // workers and queue
+	)
+
+	var done = make(chan error, requests)
+
+	for i := 0; i < requests; i++ {
+		go func(i int) {
+			var _, err = c.getter().Get(cipher.SumSHA256(
+				[]byte(fmt.Sprint(time.Now(), i))))
+			done <- err
+		}(i)
+	}
+
+	for i := 0; i < requests-served; i++ {
+		select {
+		case err = <-done:
+			assertTrue(t, err != nil, "missing error")
+			assertTrue(t, strings.Contains(err.Error(),
+				ErrTooManyRequests.Error()), fmt.Sprint("wrong error: ", err))
+		case <-time.After(TM):
+			t.Fatal("request is not rejected")
+		}
+	}
+
+	assertTrue(t, len(sn.rqq) == sc.RequestQueue, "wrong queue")
+
+	// the connection is responsive
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	for i := 0; i < 5; i++ {
+
+		var start = time.Now()
+
+		_, err = c.NewerRoot(pk, 0)
+		assertNil(t, err)
+
+		assertTrue(t, time.Since(start) < TM, "starved")
+
+	}
+
+	// close
+
+	assertNil(t, c.Close())
+
+	for i := 0; i < served; i++ {
+		select {
+		case err = <-done:
+			assertTrue(t, err != nil, "missing error")
+		case <-time.After(TM):
+			t.Fatal("request is not canceled")
+		}
+	}
+
+}
+
+func TestConfig_MaxMessageSize(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+
+		reasons = make(chan error, 1)
+	)
+
+	sconf.MaxMessageSize = 1024
+	sconf.OnDisconnect = func(_ *Conn, reason error) {
+		reasons <- reason
+	}
+
+	var gossip = onMessageToChannel(t, sconf)
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(getTestConfigNotListen("client"))
+	assertNil(t, err)
+	defer cn.Close()
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	// fits
+
+	c.sendMsg(c.nextSeq(), 0, &testGossip{string(make([]byte, 512))})
+
+	select {
+	case <-gossip:
+	case reason := <-reasons:
+		t.Fatal("closed:", reason)
+	case <-time.After(TM):
+		t.Fatal("slow or message is not handled")
+	}
+
+	// too large, the message is not decoded and handled
+
+	c.sendMsg(c.nextSeq(), 0, &testGossip{string(make([]byte, 2048))})
+
+	select {
+	case reason := <-reasons:
+		assertTrue(t, reason == ErrMessageTooLarge, "wrong reason")
+	case <-time.After(TM):
+		t.Fatal("not closed")
+	}
+
+	select {
+	case <-gossip:
+		t.Fatal("too large message handled")
+	default:
+	}
+
+}
+
+func TestConfig_AnnounceToOutgoing(t *testing.T) {
+
+	// the flag is off, thus the client doesn't push
+	// Root objects through its outgoing connection
+
+	var (
+		sc = getTestConfig("server")
+		cc = getTestConfigNotListen("client")
+
+		rr, onRootReceived = onRootReceivedToChannel(1)
+	)
+
+	sc.OnRootReceived = onRootReceived
+
+	var sn, err = NewNode(sc)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(cc)
+	assertNil(t, err)
+	defer cn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var up *skyobject.Unpack
+	up, err = cn.Container().Unpack(sk, getTestRegistry())
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}),
+	}
+
+	assertNil(t, cn.Container().Save(up, r))
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, c.Subscribe(pk))
+
+	var scs = sn.ConnectionsOfFeed(pk)
+	assertTrue(t, len(scs) == 1, "wrong number of connections")
+
+	select {
+	case <-rr:
+		t.Fatal("Root announced to outgoing connection")
+	case <-time.After(TM):
+	}
+
+}
@@ -0,0 +1,76 @@
+package node
+
+import (
+	"sync"
+)
+
+// disconnect reasons (see DisconnectStats)
+const (
+	DisconnectManual = "manual" // closed by Close
+	DisconnectOther  = "other"  // closed by an unknown error
+)
+
+// disconnects by reason
+type disconnectStats struct {
+	mx sync.Mutex
+	rs map[string]int
+}
+
+// disconnectReason returns reason name of given
+// error: DisconnectManual for nil, text of known
+// error of the package, or DisconnectOther
+func disconnectReason(reason error) string {
+
+	switch reason {
+	case nil:
+		return DisconnectManual
+	case ErrTimeout,
+		ErrClosed,
+		ErrInvalidResponse,
+		ErrNotAllowed,
+		ErrTooManyRequests,
+		ErrIdleTimeout,
+		ErrMessageTooLarge,
+		ErrShuttingDown,
+		ErrBanned:
+		return reason.Error()
+	}
+
+	return DisconnectOther
+}
+
+func (d *disconnectStats) add(reason error) {
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	if d.rs == nil {
+		d.rs = make(map[string]int)
+	}
+
+	d.rs[disconnectReason(reason)]++
+}
+
+func (d *disconnectStats) copy() (rs map[string]int) {
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	rs = make(map[string]int, len(d.rs))
+
+	for reason, n := range d.rs {
+		rs[reason] = n
+	}
+
+	return
+}
+
+// DisconnectStats returns number of closed connections
+// by reason. A connection closed by the Close method
+// is counted as DisconnectManual. Known errors of the
+// package (e.g. ErrTimeout) are counted by text of the
+// error. Other errors (e.g. a connection that sends
+// invalid messages) are counted as DisconnectOther
+func (n *Node) DisconnectStats() map[string]int {
+	return n.dcs.copy()
+}
@@ -0,0 +1,54 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNode_DisconnectStats(t *testing.T) {
+
+	var sn, err = NewNode(getTestConfig("server"))
+	assertNil(t, err)
+	defer sn.Close()
+
+	var ds = sn.DisconnectStats()
+	assertTrue(t, len(ds) == 0, fmt.Sprint("unexpected stats: ", ds))
+
+	for _, prefix := range []string{"client 1", "client 2"} {
+
+		var cn *Node
+		cn, err = NewNode(getTestConfigNotListen(prefix))
+		assertNil(t, err)
+		defer cn.Close()
+
+		_, err = cn.TCP().Connect(sn.TCP().Address())
+		assertNil(t, err)
+
+	}
+
+	time.Sleep(TM)
+
+	var cs = sn.Connections()
+	assertTrue(t, len(cs) == 2, fmt.Sprint("wrong connections: ", len(cs)))
+
+	assertNil(t, cs[0].Close()) // manual
+	cs[1].close(ErrTimeout)     // timeout
+
+	ds = sn.DisconnectStats()
+
+	assertTrue(t, len(ds) == 2, fmt.Sprint("unexpected stats: ", ds))
+	assertTrue(t, ds[DisconnectManual] == 1, "wrong manual disconnects")
+	assertTrue(t, ds[ErrTimeout.Error()] == 1, "wrong timeout disconnects")
+
+	// a copy
+
+	ds[DisconnectManual] = 10
+	assertTrue(t, sn.DisconnectStats()[DisconnectManual] == 1, "not a copy")
+
+	// other
+
+	assertTrue(t, disconnectReason(fmt.Errorf("some error")) ==
+		DisconnectOther, "wrong reason")
+
+}
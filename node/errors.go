@@ -2,6 +2,9 @@ package node
 
 import (
 	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
 )
 
 // common errors
@@ -16,4 +19,80 @@ var (
 	ErrMaxHeadsLimit           = errors.New("max heads limit")
 	ErrUnsubscribe             = errors.New("unsubscribe")
 	ErrBlankFeed               = errors.New("blank feed")
+	ErrTooManyRequests         = errors.New("too many requests")
+	ErrNotAllowed              = errors.New("address is not allowed")
+	ErrIdleTimeout             = errors.New("idle timeout")
+	ErrNoAddresses             = errors.New("no addresses")
+	ErrMessageTooLarge         = errors.New("message is too large")
+	ErrRPCHandlerExists        = errors.New("RPC handler already exists")
+	ErrNoSuchRPCHandler        = errors.New("no such RPC handler")
+	ErrShuttingDown            = errors.New("shutting down")
+	ErrRPCAuth                 = errors.New("RPC authentication failed")
+	ErrBanned                  = errors.New("peer is banned")
+	ErrExportDisabled          = errors.New("exports disabled (see ExportDir)")
+	ErrInvalidExportName       = errors.New("invalid name of file to export to")
 )
+
+// An InvalidFeedError occurs when public key of
+// a feed is malformed. A blank feed causes the
+// ErrBlankFeed error instead
+type InvalidFeedError struct {
+	feed cipher.PubKey
+	err  error
+}
+
+// Feed returns the malformed public key
+func (i *InvalidFeedError) Feed() cipher.PubKey {
+	return i.feed
+}
+
+// Err returns reason of the error
+func (i *InvalidFeedError) Err() error {
+	return i.err
+}
+
+// Error implements error interface
+func (i *InvalidFeedError) Error() string {
+	return fmt.Sprintf("invalid feed %s: %v", i.feed.Hex(), i.err)
+}
+
+// validateFeed returns ErrBlankFeed if given
+// feed is blank, or *InvalidFeedError if the
+// feed is malformed
+func validateFeed(feed cipher.PubKey) (err error) {
+
+	if feed == (cipher.PubKey{}) {
+		return ErrBlankFeed
+	}
+
+	if err = feed.Verify(); err != nil {
+		return &InvalidFeedError{feed, err}
+	}
+
+	return
+}
+
+// An AckTimeoutError occurs when the PublishAndWait
+// method of the Node doesn't receive required number
+// of acknowledgments in time. The error contains
+// number of received acknowledgments and the quorum
+type AckTimeoutError struct {
+	acks   int
+	quorum int
+}
+
+// Acks returns number of received acknowledgments
+func (a *AckTimeoutError) Acks() int {
+	return a.acks
+}
+
+// Quorum returns required number of acknowledgments
+func (a *AckTimeoutError) Quorum() int {
+	return a.quorum
+}
+
+// Error implements error interface
+func (a *AckTimeoutError) Error() string {
+	return fmt.Sprintf("timeout: %d of %d acknowledgments received",
+		a.acks, a.quorum)
+}
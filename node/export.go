@@ -0,0 +1,105 @@
+package node
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// ExportFeed exports last Root of given feed and all
+// objects of the Root to file with given name in the
+// ExportDir (see Config and (*skyobject.Container).Export
+// for details). The name must not contain a directory.
+// The ExportFeed doesn't overwrite existing files, and
+// it removes the file if the export fails. The export
+// performed by separate goroutine, and the ExportFeed
+// waits it. The ExportFeed returns number of exported
+// objects, or ErrClosed if the Node closed
+// during the export. It returns ErrExportDisabled if
+// the ExportDir is not set, and ErrInvalidExportName
+// if given name is not a name of file
+func (n *Node) ExportFeed(
+	feed cipher.PubKey, // : the feed
+	name string, //        : name of file in the ExportDir
+) (
+	objects int, //        : number of exported objects
+	err error, //          : an error
+) {
+
+	if n.config.ExportDir == "" {
+		return 0, ErrExportDisabled
+	}
+
+	if name == "" || name == "." || name == ".." ||
+		filepath.Base(name) != name {
+
+		return 0, ErrInvalidExportName
+	}
+
+	var path = filepath.Join(n.config.ExportDir, name)
+
+	var r *registry.Root
+	if r, err = n.c.LastRoot(feed, n.c.ActiveHead(feed)); err != nil {
+		return
+	}
+
+	type result struct {
+		objects int
+		err     error
+	}
+
+	var rc = make(chan result, 1)
+
+	n.await.Add(1)
+	go func() {
+		defer n.await.Done()
+
+		var res result
+		res.objects, res.err = n.exportRoot(r, path)
+		rc <- res
+	}()
+
+	select {
+	case res := <-rc:
+		return res.objects, res.err
+	case <-n.closeq:
+		return 0, ErrClosed
+	}
+
+}
+
+func (n *Node) exportRoot(
+	r *registry.Root, // : the Root
+	path string, //      : path to file
+) (
+	objects int, //      : number of exported objects
+	err error, //        : an error
+) {
+
+	var fl *os.File
+	fl, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return // including existing file
+	}
+	defer func() {
+		if cerr := fl.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(path) // don't keep partial file
+		}
+	}()
+
+	var bw = bufio.NewWriter(fl)
+
+	if objects, err = n.c.Export(bw, r); err != nil {
+		return
+	}
+
+	err = bw.Flush()
+	return
+}
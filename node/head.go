@@ -3,11 +3,11 @@ package node
 import (
 	"container/list"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
-	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/statutil"
 )
@@ -92,6 +92,10 @@ func (n *nodeHead) node() *Node {
 	return n.n.fs.n
 }
 
+// time to wait before an object request throttled
+// by remote peer (ErrTooManyRequests) is repeated
+const throttleDelay = 100 * time.Millisecond
+
 type failedRequest struct {
 	c   *Conn         // connection
 	seq uint64        // seq of the filling Root
@@ -239,6 +243,7 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 	case ErrInvalidResponse:
 
 		// close connections that sends invalid responses
+		f.node().penalize(fr.c, penaltyInvalidResponse)
 		go fr.c.fatality(fr.err)
 		delete(f.cs, fr.c) // remove connection
 
@@ -252,6 +257,13 @@ func (f *fillHead) handleRequestFailure(fr failedRequest) {
 		// probably don't have object we're requesting anymore
 		f.cs.removeKnown(fr.c, fr.seq)
 
+	case ErrTooManyRequests:
+
+		// the peer is busy, but it's not misbehaving; the
+		// request has been delayed (see throttleDelay), thus
+		// the connection can be used again
+		f.fc.PushBack(fr.c)
+
 	default:
 
 		// skyobject.ErrTerminated or other error
@@ -267,6 +279,14 @@ func (f *fillHead) handleReceivedRoot(cr connRoot) {
 	f.node().Debugln(FillPin, "[fill] handleReceivedRoot",
 		cr.c.String(), cr.r.Short())
 
+	// don't fill new Root objects shutting down
+
+	if f.node().isDraining() == true {
+		if f.r.r == nil || cr.r.Seq != f.r.r.Seq {
+			return
+		}
+	}
+
 	// there are a filling Root
 
 	if f.r.r != nil {
@@ -342,6 +362,8 @@ func (f *fillHead) createFiller(cr connRoot) {
 
 	f.tp = time.Now() // time point
 
+	atomic.AddInt32(&f.node().filling, 1)
+
 	if ft := f.node().config.MaxFillingTime; ft > 0 {
 		f.ft = time.NewTimer(ft)
 		f.tc = f.ft.C
@@ -380,6 +402,9 @@ func (f *fillHead) closeFiller() {
 	}
 
 	f.f.Close()
+	f.f = nil
+
+	atomic.AddInt32(&f.node().filling, -1)
 
 	f.rqo, f.fc, f.rq = nil, nil, nil
 
@@ -396,6 +421,10 @@ func (f *fillHead) handleFillingResult(err error) {
 		f.node().onRootFilled(f.r.r)     // callback
 		f.favg.Add(time.Now().Sub(f.tp)) // average time
 		f.cs.moveForward(f.r.r.Seq + 1)  // move forward
+
+		if f.r.c != nil {
+			f.r.c.sendRootAck(f.r.r) // the Root is stored
+		}
 	} else {
 		f.node().onFillingBreaks(f.r.r, err) // callback
 	}
@@ -406,7 +435,7 @@ func (f *fillHead) handleFillingResult(err error) {
 
 	// no
 
-	if f.p == (connRoot{}) {
+	if f.p == (connRoot{}) || f.node().isDraining() == true {
 		return
 	}
 
@@ -479,34 +508,40 @@ func (f *fillHead) request(c *Conn, seq uint64, key cipher.SHA256) {
 	f.node().Debugf(FillPin, "[fill] request from [%s] %d %s", c.String(), seq,
 		key.Hex()[:7])
 
-	var reply, err = c.sendRequest(&msg.RqObject{Key: key})
+	var val, err = f.node().requestObject(c, key)
 
-	if err != nil {
-		f.failureq <- failedRequest{c, seq, key, err}
-		return
+	if _, ok := err.(errorReply); ok == true {
+		err = ErrInvalidResponse
 	}
 
-	switch x := reply.(type) {
-	case *msg.Object:
-		var rk = cipher.SumSHA256(x.Value)
+	if err == ErrTooManyRequests {
 
-		if rk != key {
-			f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
-			return
-		}
+		// don't repeat the request immediately
+
+		var tm = time.NewTimer(throttleDelay)
+		defer tm.Stop()
 
-		// incremented by the Want call(s)
-		if _, err := f.node().c.SetWanted(key, x.Value); err != nil {
-			f.node().Fatal("DB failure:", err)
+		select {
+		case <-tm.C:
+		case <-f.closeq:
 			return
 		}
 
-		f.successq <- c
+	}
 
-	default:
-		f.failureq <- failedRequest{c, seq, key, ErrInvalidResponse}
+	if err != nil {
+		f.failureq <- failedRequest{c, seq, key, err}
+		return
 	}
 
+	// incremented by the Want call(s)
+	if _, err := f.node().c.SetWanted(key, val); err != nil {
+		f.node().Fatal("DB failure:", err)
+		return
+	}
+
+	f.successq <- c
+
 }
 
 func (f *fillHead) handleDelConn(c *Conn) {
@@ -0,0 +1,74 @@
+package node
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// HealthTimeout is time limit for the
+// responsiveness check of the Health method
+const HealthTimeout time.Duration = 5 * time.Second
+
+// A Health represents result of
+// the (*Node).Health method
+type Health struct {
+	Start time.Time // check started at
+	DB    time.Time // DB read done at
+	Node  time.Time // the Node responded at
+}
+
+// Health checks that the Node is alive. It performs
+// trivial DB read and checks that the Node is not
+// blocked (acquiring lock of the Node). The Health
+// returns ErrTimeout if the Node doesn't respond
+// during HealthTimeout, and ErrClosed if the Node
+// closed
+func (n *Node) Health() (h *Health, err error) {
+
+	select {
+	case <-n.closeq:
+		return nil, ErrClosed
+	default:
+	}
+
+	h = new(Health)
+	h.Start = time.Now()
+
+	// DB (not found is ok)
+
+	_, _, err = n.c.DB().CXDS().Get(cipher.SHA256{}, 0)
+
+	if err != nil && err != data.ErrNotFound {
+		return nil, err
+	}
+
+	h.DB = time.Now()
+
+	// responsiveness
+
+	var lockq = make(chan struct{})
+
+	go func() {
+		n.mx.Lock()
+		n.mx.Unlock()
+		close(lockq)
+	}()
+
+	var tm = time.NewTimer(HealthTimeout)
+	defer tm.Stop()
+
+	select {
+	case <-lockq:
+	case <-n.closeq:
+		return nil, ErrClosed
+	case <-tm.C:
+		return nil, ErrTimeout
+	}
+
+	h.Node = time.Now()
+
+	return h, nil
+}
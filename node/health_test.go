@@ -0,0 +1,52 @@
+package node
+
+import (
+	"testing"
+)
+
+func TestNode_Health(t *testing.T) {
+
+	var conf = getTestConfigNotListen("health")
+	conf.RPC = "127.0.0.1:0"
+
+	var n, err = NewNode(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+
+	var h *Health
+	if h, err = n.Health(); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTrue(t, h.Start.IsZero() == false, "zero Start")
+	assertTrue(t, h.DB.Before(h.Start) == false, "wrong DB")
+	assertTrue(t, h.Node.Before(h.DB) == false, "wrong Node")
+
+	// rpc
+
+	var rc *RPCClient
+	if rc, err = NewRPCClient(n.rpc.Address()); err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	if h, err = rc.Node().Health(); err != nil {
+		t.Fatal(err)
+	}
+	assertTrue(t, h.Node.IsZero() == false, "zero Node")
+
+	// closed
+
+	assertTrue(t, n.Close() == nil, "closing error")
+
+	if _, err = n.Health(); err != ErrClosed {
+		t.Error("unexpected error:", err)
+	}
+
+	if _, err = rc.Node().Health(); err == nil {
+		t.Error("missing error")
+	}
+
+}
@@ -0,0 +1,57 @@
+package node
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// use marks the connection used by given message;
+// pings are ignored if IdlePings is false
+func (c *Conn) use(m msg.Msg) {
+
+	if c.n.config.IdleTimeout <= 0 {
+		return
+	}
+
+	if c.n.config.IdlePings == false {
+		switch m.(type) {
+		case *msg.Ping, *msg.Pong:
+			return
+		}
+	}
+
+	atomic.StoreInt64(&c.used, time.Now().UnixNano())
+}
+
+// close the connection with ErrIdleTimeout
+// if it's not used during given timeout
+func (c *Conn) idling(timeout time.Duration) {
+
+	defer c.await.Done()
+
+	var tm = time.NewTimer(timeout)
+	defer tm.Stop()
+
+	for {
+
+		select {
+		case <-tm.C:
+		case <-c.closeq:
+			return
+		}
+
+		var idle = time.Since(time.Unix(0, atomic.LoadInt64(&c.used)))
+
+		if idle >= timeout {
+			c.n.Debugf(ConnPin, "[%s] idle %s", c.String(), idle)
+			go c.close(ErrIdleTimeout) // the close waits for the idling
+			return
+		}
+
+		tm.Reset(timeout - idle)
+
+	}
+
+}
@@ -0,0 +1,42 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNode_IdleTimeout(t *testing.T) {
+
+	var sconf = getTestConfig("server")
+	sconf.IdleTimeout = TM
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	// silent peer
+
+	var cn *Node
+	cn, err = NewNode(getTestConfigNotListen("client"))
+	assertNil(t, err)
+	defer cn.Close()
+
+	_, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	time.Sleep(TM / 2)
+
+	var cs = sn.Connections()
+	assertTrue(t, len(cs) == 1, "closed too early")
+
+	time.Sleep(2 * TM)
+
+	cs = sn.Connections()
+	assertTrue(t, len(cs) == 0, fmt.Sprint("not closed: ", len(cs)))
+
+	var ds = sn.DisconnectStats()
+	assertTrue(t, ds[ErrIdleTimeout.Error()] == 1,
+		fmt.Sprint("wrong disconnect stats: ", ds))
+
+}
@@ -0,0 +1,75 @@
+package msg
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// CustomType is first Type of custom messages.
+// Types from CustomType up to 255 can be used by
+// applications for their own messages (see Register)
+const CustomType Type = 128
+
+var customRegistry struct {
+	mx    sync.RWMutex
+	types map[Type]reflect.Type
+}
+
+// Register custom message. Given Type must be
+// CustomType or greater. The Register panics if the
+// Type is less then CustomType or already registered.
+// The Encode method of a custom message should return
+// the message encoded using the Encode function of this
+// package, e.g.
+//
+//     func (m *MyMsg) Encode() []byte { return msg.Encode(m) }
+//
+func Register(typ Type, m Msg) {
+
+	if typ < CustomType {
+		panic(fmt.Sprintf("can't register %T: type %d is reserved", m, typ))
+	}
+
+	if m.Type() != typ {
+		panic(fmt.Sprintf("can't register %T: type mismatch %d vs %d",
+			m, m.Type(), typ))
+	}
+
+	customRegistry.mx.Lock()
+	defer customRegistry.mx.Unlock()
+
+	if customRegistry.types == nil {
+		customRegistry.types = make(map[Type]reflect.Type)
+	}
+
+	if rt, ok := customRegistry.types[typ]; ok == true {
+		panic(fmt.Sprintf("can't register %T: type %d already registered by %s",
+			m, typ, rt.String()))
+	}
+
+	customRegistry.types[typ] = reflect.Indirect(reflect.ValueOf(m)).Type()
+}
+
+// IsCustom returns true if given Type is type
+// of a custom message
+func (m Type) IsCustom() bool {
+	return m >= CustomType
+}
+
+// custom type by Type
+func customType(typ Type) (rt reflect.Type, ok bool) {
+
+	customRegistry.mx.RLock()
+	defer customRegistry.mx.RUnlock()
+
+	rt, ok = customRegistry.types[typ]
+	return
+}
+
+// Encode given message to []byte prefixed by Type.
+// The Encode can be used to implement Encode method
+// of a custom message
+func Encode(m Msg) (p []byte) {
+	return encode(m)
+}
@@ -17,7 +17,7 @@ import (
 //
 
 // Version is current protocol version
-const Version uint16 = 3
+const Version uint16 = 7
 
 // be sure that all messages implements Msg interface compiler time
 var (
@@ -59,6 +59,15 @@ var (
 	// preview
 
 	_ Msg = &RqPreview{} // -> RqPreview (feed)
+
+	// root info
+
+	_ Msg = &RqRoot{}   // <- RqRoot   (feed, seq, has seq)
+	_ Msg = &RootInfo{} // -> RootInfo (feed, nonce, seq, hash, found)
+
+	// root acknowledgment
+
+	_ Msg = &RootAck{} // -> RootAck (feed, nonce, seq)
 )
 
 //
@@ -291,6 +300,100 @@ func (*RqPreview) Type() Type { return RqPreviewType }
 // Encode the RqPreview
 func (r *RqPreview) Encode() []byte { return encode(r) }
 
+//
+// root info
+//
+
+// A RqRoot is request for information about last
+// Root of a feed that is newer then given seq. The
+// request uses active head of the feed. If the HasSeq
+// is false, then the Seq is ignored and last Root is
+// requested regardless its seq
+type RqRoot struct {
+	Feed   cipher.PubKey
+	Seq    uint64 // newer then this
+	HasSeq bool   // use the Seq
+}
+
+// Type implements Msg interface
+func (*RqRoot) Type() Type { return RqRootType }
+
+// Encode the RqRoot
+func (r *RqRoot) Encode() []byte { return encode(r) }
+
+// A RootInfo is reply for the RqRoot. If remote
+// peer has not a Root newer then requested, then
+// the Found field is false and other fields (except
+// the Feed) are blank
+type RootInfo struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+	Seq   uint64
+	Hash  cipher.SHA256
+	Found bool
+}
+
+// Type implements Msg interface
+func (*RootInfo) Type() Type { return RootInfoType }
+
+// Encode the RootInfo
+func (r *RootInfo) Encode() []byte { return encode(r) }
+
+//
+// root acknowledgment
+//
+
+// A RootAck is sent by a peer that has received a
+// Root and has stored the Root and all its objects
+type RootAck struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+	Seq   uint64
+}
+
+// Type implements Msg interface
+func (*RootAck) Type() Type { return RootAckType }
+
+// Encode the RootAck
+func (r *RootAck) Encode() []byte { return encode(r) }
+
+//
+// initial sync
+//
+
+// A SyncDone is sent by a peer after it has sent
+// last Root objects of feeds the connection is
+// subscribed to, right after the connection
+// has been established
+type SyncDone struct{}
+
+// Type implements Msg interface
+func (*SyncDone) Type() Type { return SyncDoneType }
+
+// Encode the SyncDone
+func (*SyncDone) Encode() []byte {
+	return []byte{
+		byte(SyncDoneType),
+	}
+}
+
+//
+// announce
+//
+
+// An Announce is sent by a peer when it saves new
+// objects after initial sync. It contains keys of
+// the objects of feeds the receiver subscribed to
+type Announce struct {
+	Hashes []cipher.SHA256
+}
+
+// Type implements Msg interface
+func (*Announce) Type() Type { return AnnounceType }
+
+// Encode the Announce
+func (a *Announce) Encode() []byte { return encode(a) }
+
 //
 // Type / Encode / Deocode / String()
 //
@@ -321,6 +424,15 @@ const (
 	ObjectType   // 13
 
 	RqPreviewType // 14
+
+	RqRootType   // 15
+	RootInfoType // 16
+
+	RootAckType // 17
+
+	SyncDoneType // 18
+
+	AnnounceType // 19
 )
 
 // Type to string mapping
@@ -346,6 +458,15 @@ var msgTypeString = [...]string{
 	ObjectType:   "Object",
 
 	RqPreviewType: "RqPreview",
+
+	RqRootType:   "RqRoot",
+	RootInfoType: "RootInfo",
+
+	RootAckType: "RootAck",
+
+	SyncDoneType: "SyncDone",
+
+	AnnounceType: "Announce",
 }
 
 // String implements fmt.Stringer interface
@@ -353,6 +474,9 @@ func (m Type) String() string {
 	if im := int(m); im > 0 && im < len(msgTypeString) {
 		return msgTypeString[im]
 	}
+	if rt, ok := customType(m); ok == true {
+		return rt.Name()
+	}
 	return fmt.Sprintf("Type<%d>", m)
 }
 
@@ -378,6 +502,15 @@ var forwardRegistry = [...]reflect.Type{
 	ObjectType:   reflect.TypeOf(Object{}),
 
 	RqPreviewType: reflect.TypeOf(RqPreview{}),
+
+	RqRootType:   reflect.TypeOf(RqRoot{}),
+	RootInfoType: reflect.TypeOf(RootInfo{}),
+
+	RootAckType: reflect.TypeOf(RootAck{}),
+
+	SyncDoneType: reflect.TypeOf(SyncDone{}),
+
+	AnnounceType: reflect.TypeOf(Announce{}),
 }
 
 // An InvalidTypeError represents decoding error when
@@ -418,7 +551,8 @@ func encode(msg Msg) (p []byte) {
 }
 
 // Decode encoded Type-prefixed data to message.
-// It can returns encoding errors or InvalidTypeError
+// It can returns encoding errors or InvalidTypeError.
+// The Decode decodes registered custom messages too
 func Decode(p []byte) (msg Msg, err error) {
 
 	if len(p) < 1 {
@@ -426,15 +560,25 @@ func Decode(p []byte) (msg Msg, err error) {
 		return
 	}
 
-	var mt = Type(p[0])
+	var (
+		mt  = Type(p[0])
+		typ reflect.Type
+		ok  bool
+	)
 
-	if mt <= 0 || int(mt) >= len(forwardRegistry) {
+	if mt.IsCustom() == true {
+		if typ, ok = customType(mt); ok == false {
+			err = InvalidTypeError{mt}
+			return
+		}
+	} else if mt <= 0 || int(mt) >= len(forwardRegistry) {
 		err = InvalidTypeError{mt}
 		return
+	} else {
+		typ = forwardRegistry[mt]
 	}
 
 	var (
-		typ = forwardRegistry[mt]
 		val = reflect.New(typ)
 
 		n int
@@ -10,6 +10,7 @@ import (
 	discovery "github.com/skycoin/net/skycoin-messenger/factory"
 
 	"github.com/skycoin/cxo/node/log"
+	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 	"github.com/skycoin/cxo/skyobject/statutil"
@@ -62,6 +63,31 @@ type Node struct {
 
 	fillavg *statutil.Duration // filling average
 
+	//
+	// requests
+	//
+
+	rqq chan rqObject  // requests of peers (see RequestWorkers)
+	rqs objectRequests // object requests in flight (see DedupRequests)
+
+	// AllowList and DenyList
+	allow, deny *addressList
+
+	// UDP announce
+	gossip udpGossip
+
+	// Root objects waiting for acknowledgments
+	acks rootAcks
+
+	// new objects announcing
+	announcer objectsAnnouncer
+
+	// closed connections by reason
+	dcs disconnectStats
+
+	// scores and bans of peers
+	bans peerScores
+
 	//
 	// rpc
 	//
@@ -72,6 +98,9 @@ type Node struct {
 	//  closing
 	//
 
+	draining int32 // shutting down (see Shutdown)
+	filling  int32 // running fillers (see Shutdown)
+
 	await  sync.WaitGroup // wait for goroutines
 	closeo sync.Once      // close once
 	closeq chan struct{}  // closed
@@ -136,6 +165,11 @@ func NewNodeContainer(
 	n.fillavg = statutil.NewDuration(conf.Config.RollAvgSamples)
 	n.closeq = make(chan struct{})
 
+	n.allow, _ = parseAddressList(conf.AllowList) // validated
+	n.deny, _ = parseAddressList(conf.DenyList)   // validated
+
+	n.startRequestWorkers() // serve object requests of peers
+
 	//
 	// create
 	//
@@ -160,6 +194,17 @@ func NewNodeContainer(
 		}
 	}
 
+	// UDP announce
+
+	if err = n.initUDPAnnounce(); err != nil {
+		n.Close()
+		return
+	}
+
+	// announce new objects
+
+	n.announcer.start(n)
+
 	// rpc
 
 	if conf.RPC != "" {
@@ -217,6 +262,16 @@ func (n *Node) Container() (c *skyobject.Container) {
 	return n.c
 }
 
+// RPC returns RPC of the Node that can be used to
+// register custom RPC handlers. The RPC returns nil
+// if RPC is disabled by configurations
+func (n *Node) RPC() (r *RPC) {
+	if n.rpc != nil {
+		r = n.rpc.h
+	}
+	return
+}
+
 // Publish sends given Root object to peers that
 // subscribed to feed of the Root. The Publish used
 // to publish new Root objects. E.g. the Node sends
@@ -224,9 +279,36 @@ func (n *Node) Container() (c *skyobject.Container) {
 // the Node knows nothing about new Root objects.
 // And to share an updated Root, call the Publish.
 // And don't call the publish for Root objects that
-// alredy saved (that saved before subscription)
-func (n *Node) Publish(r *registry.Root) {
+// alredy saved (that saved before subscription).
+// The Publish returns ErrBlankFeed or *InvalidFeedError
+// if feed of the Root is blank or malformed
+func (n *Node) Publish(r *registry.Root) (err error) {
+
+	if err = validateFeed(r.Pub); err != nil {
+		return
+	}
+
 	n.fs.broadcastRoot(connRoot{nil, r})
+	return
+}
+
+// Broadcast given message to all established
+// connections. The message should be a custom
+// message (see msg.Register). The Broadcast returns
+// ErrClosed if the Node closed
+func (n *Node) Broadcast(m msg.Msg) (err error) {
+
+	select {
+	case <-n.closeq:
+		return ErrClosed
+	default:
+	}
+
+	for _, c := range n.Connections() {
+		c.sendMsg(c.nextSeq(), 0, m)
+	}
+
+	return
 }
 
 // ConnectionsOfFeed returns list of connections of given
@@ -365,6 +447,8 @@ func (n *Node) onConnect(c *Conn) {
 
 func (n *Node) onDisconenct(c *Conn, reason error) {
 
+	n.dcs.add(reason)
+
 	if odc := n.config.OnDisconnect; odc != nil {
 		odc(c, reason)
 	}
@@ -382,7 +466,15 @@ func (n *Node) acceptConnection(fc *factory.Connection) {
 	n.Debugf(NewInConnPin, "[%s] accept",
 		connString(true, fc.IsTCP(), fc.GetRemoteAddr().String()))
 
-	if _, err := n.wrapConnection(fc, true); err != nil {
+	_, err := n.wrapConnection(fc, true)
+
+	if err == ErrNotAllowed || err == ErrShuttingDown {
+
+		n.Debugf(NewInConnPin, "[%s] rejected: %v",
+			connString(true, fc.IsTCP(), fc.GetRemoteAddr().String()),
+			err)
+
+	} else if err != nil {
 
 		n.Printf("[ERR] [%s] handshake error: %v",
 			connString(true, fc.IsTCP(), fc.GetRemoteAddr().String()),
@@ -417,6 +509,24 @@ func (n *Node) wrapConnection(
 
 	c = n.newConnection(fc, isIncoming) // adds to pending
 
+	if n.IsBanned(fc.GetRemoteAddr().String()) == true {
+		err = ErrBanned
+		n.delPendingConnClose(c)
+		return
+	}
+
+	if isIncoming == true {
+		if err = n.checkAddress(fc.GetRemoteAddr().String()); err != nil {
+			n.delPendingConnClose(c)
+			return
+		}
+		if n.isDraining() == true {
+			err = ErrShuttingDown
+			n.delPendingConnClose(c)
+			return
+		}
+	}
+
 	// handshake
 	if err = c.handshake(n.closeq); err != nil {
 		n.delPendingConnClose(c)
@@ -442,6 +552,8 @@ func (n *Node) wrapConnection(
 	c.run()
 	n.onConnect(c)
 
+	c.sendEverythingWeHave()
+
 	return
 
 }
@@ -487,11 +599,13 @@ func (n *Node) updateServiceDiscovery() {
 // method. The method never return an error if given
 // feed is already shared. The share never associate
 // the feed with a connection. You should to call
-// (*Conn).Subscribe to do that.
+// (*Conn).Subscribe to do that. The Share returns
+// ErrBlankFeed or *InvalidFeedError if given feed is
+// blank or malformed
 func (n *Node) Share(feed cipher.PubKey) (err error) {
 
-	if feed == (cipher.PubKey{}) {
-		return ErrBlankFeed
+	if err = validateFeed(feed); err != nil {
+		return
 	}
 
 	// add to the Container
@@ -528,6 +642,20 @@ func (n *Node) DontShare(feed cipher.PubKey) (err error) {
 	return
 }
 
+// DropFeed stops sharing given feed (see DontShare)
+// and removes the feed from underlying Container with
+// all its Root objects. The DropFeed returns
+// data.ErrNoSuchFeed if the Container doesn't have
+// the feed
+func (n *Node) DropFeed(feed cipher.PubKey) (err error) {
+
+	if err = n.DontShare(feed); err != nil {
+		return
+	}
+
+	return n.c.DelFeed(feed)
+}
+
 func (n *Node) onSubscribeRemote(c *Conn, feed cipher.PubKey) (reject error) {
 
 	if osr := n.config.OnSubscribeRemote; osr != nil {
@@ -537,6 +665,27 @@ func (n *Node) onSubscribeRemote(c *Conn, feed cipher.PubKey) (reject error) {
 	return
 }
 
+func (n *Node) onMessage(c *Conn, m msg.Msg) (terminate error) {
+
+	if mh, ok := m.(MsgHandler); ok == true {
+		return mh.Handle(c)
+	}
+
+	if om := n.config.OnMessage; om != nil {
+		return om(c, m)
+	}
+
+	return
+}
+
+func (n *Node) onAnnounce(c *Conn, hashes []cipher.SHA256) {
+
+	if oa := n.config.OnAnnounce; oa != nil {
+		oa(c, hashes)
+	}
+
+}
+
 func (n *Node) onUnsubscribeRemote(c *Conn, feed cipher.PubKey) {
 
 	if ousr := n.config.OnUnsubscribeRemote; ousr != nil {
@@ -614,6 +763,9 @@ func (n *Node) Close() (err error) {
 
 		close(n.closeq)
 
+		n.gossip.close()    // before the lock
+		n.announcer.close() // before the lock
+
 		n.mx.Lock()
 		defer n.mx.Unlock()
 
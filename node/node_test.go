@@ -6,6 +6,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/node/msg"
 	"github.com/skycoin/cxo/skyobject"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
@@ -348,6 +349,273 @@ func TestNode_Publish(t *testing.T) {
 
 }
 
+type testGossip struct {
+	Text string
+}
+
+const testGossipType = msg.CustomType
+
+func init() {
+	msg.Register(testGossipType, &testGossip{})
+}
+
+func (*testGossip) Type() msg.Type { return testGossipType }
+
+func (t *testGossip) Encode() []byte { return msg.Encode(t) }
+
+func onMessageToChannel(
+	t *testing.T,
+	conf *Config,
+) (
+	gossip <-chan string,
+) {
+
+	var gc = make(chan string, 2)
+
+	conf.OnMessage = func(c *Conn, m msg.Msg) (_ error) {
+		if tg, ok := m.(*testGossip); ok == true {
+			gc <- tg.Text
+		} else {
+			t.Errorf("unexpected message %T", m)
+		}
+		return
+	}
+
+	return gc
+}
+
+func TestNode_Publish_invalidFeed(t *testing.T) {
+
+	var n, err = NewNode(getTestConfigNotListen("node"))
+	assertNil(t, err)
+	defer n.Close()
+
+	var r = new(registry.Root)
+
+	assertTrue(t, n.Publish(r) == ErrBlankFeed, "missing ErrBlankFeed")
+	assertTrue(t, n.PublishAndWait(r, 1, TM) == ErrBlankFeed,
+		"missing ErrBlankFeed")
+
+	r.Pub = cipher.PubKey{1, 2, 3}
+
+	_, ok := n.Publish(r).(*InvalidFeedError)
+	assertTrue(t, ok == true, "missing *InvalidFeedError")
+
+	assertTrue(t, n.Share(cipher.PubKey{}) == ErrBlankFeed,
+		"missing ErrBlankFeed")
+
+}
+
+func TestNode_Broadcast(t *testing.T) {
+	// (m msg.Msg) (err error)
+
+	var (
+		sconf = getTestConfig("server")
+		rconf = getTestConfigNotListen("client")
+
+		sgossip = onMessageToChannel(t, sconf)
+		rgossip = onMessageToChannel(t, rconf)
+
+		sn, rn *Node
+		err    error
+	)
+
+	if sn, err = NewNode(sconf); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	if rn, err = NewNode(rconf); err != nil {
+		t.Fatal(err)
+	}
+	defer rn.Close()
+
+	if _, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100 && len(sn.Connections()) == 0; i++ {
+		time.Sleep(TM / 50)
+	}
+	assertTrue(t, len(sn.Connections()) == 1, "missing connection")
+
+	for _, tc := range []struct {
+		from   *Node
+		gossip <-chan string
+		text   string
+	}{
+		{sn, rgossip, "from server"},
+		{rn, sgossip, "from client"},
+	} {
+
+		if err = tc.from.Broadcast(&testGossip{tc.text}); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case text := <-tc.gossip:
+			assertTrue(t, text == tc.text, "wrong message")
+		case <-time.After(TM):
+			t.Fatal("slow or missing message")
+		}
+
+	}
+
+	rn.Close()
+
+	assertTrue(t, rn.Broadcast(&testGossip{"closed"}) == ErrClosed,
+		"missing ErrClosed")
+
+}
+
+type testHandled struct {
+	Text string
+}
+
+const testHandledType = msg.CustomType + 1
+
+func init() {
+	msg.Register(testHandledType, &testHandled{})
+}
+
+var testHandledq = make(chan string, 1)
+
+func (*testHandled) Type() msg.Type { return testHandledType }
+
+func (t *testHandled) Encode() []byte { return msg.Encode(t) }
+
+func (t *testHandled) Handle(c *Conn) (_ error) {
+	testHandledq <- t.Text + " by " + c.Node().Config().TCP.Listen
+	return
+}
+
+func TestNode_MsgHandler(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		rconf = getTestConfigNotListen("client")
+
+		sgossip = onMessageToChannel(t, sconf)
+
+		sn, rn *Node
+		err    error
+	)
+
+	if sn, err = NewNode(sconf); err != nil {
+		t.Fatal(err)
+	}
+	defer sn.Close()
+
+	if rn, err = NewNode(rconf); err != nil {
+		t.Fatal(err)
+	}
+	defer rn.Close()
+
+	if _, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, rn.Broadcast(&testHandled{"handled"}))
+
+	select {
+	case text := <-testHandledq:
+		assertTrue(t, text == "handled by "+sconf.TCP.Listen, "wrong message")
+	case <-time.After(TM):
+		t.Fatal("slow or missing message")
+	}
+
+	select {
+	case text := <-sgossip:
+		t.Fatal("OnMessage called:", text)
+	default:
+	}
+
+}
+
+func TestNode_onRootReceived(t *testing.T) {
+
+	// the OnRootReceived callback called
+	// only for new Root objects
+
+	var (
+		ln = getTestNode("server")
+
+		rc = getTestConfigNotListen("client")
+
+		received = make(chan *registry.Root, 10)
+		filled   = make(chan *registry.Root, 10)
+	)
+
+	defer ln.Close()
+
+	rc.OnRootReceived = func(_ *Conn, r *registry.Root) (_ error) {
+		received <- r
+		return
+	}
+
+	rc.OnRootFilled = func(_ *Node, r *registry.Root) {
+		filled <- r
+	}
+
+	var rn, err = NewNode(rc)
+	assertNil(t, err)
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, ln.Share(pk))
+	assertNil(t, rn.Share(pk))
+
+	var c *Conn
+	c, err = rn.TCP().Connect(ln.TCP().Address())
+	assertNil(t, err)
+	assertNil(t, c.Subscribe(pk))
+
+	var up *skyobject.Unpack
+	up, err = ln.Container().Unpack(sk, getTestRegistry())
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+
+	assertNil(t, ln.Container().Save(up, r)) // blank Root
+
+	ln.Publish(r)
+
+	select {
+	case fr := <-filled:
+		assertTrue(t, fr.Hash == r.Hash, "wrong Root filled")
+	case <-time.After(TM):
+		t.Fatal("slow or missing Root")
+	}
+
+	// publish the same Root again
+
+	ln.Publish(r)
+
+	// the rn handles Root objects of a connection in
+	// order, thus a Root of another head is a barrier
+
+	var mark = new(registry.Root)
+	mark.Pub, mark.Nonce = pk, 2
+
+	assertNil(t, ln.Container().Save(up, mark))
+	ln.Publish(mark)
+
+	select {
+	case fr := <-filled:
+		assertTrue(t, fr.Hash == mark.Hash, "wrong Root filled")
+	case <-time.After(TM):
+		t.Fatal("slow or missing Root")
+	}
+
+	assertTrue(t, len(received) == 2, "wrong number of calls")
+	assertTrue(t, (<-received).Hash == r.Hash, "wrong Root received")
+	assertTrue(t, (<-received).Hash == mark.Hash, "wrong Root received")
+
+}
+
 func TestNode_ConnectionsOfFeed(t *testing.T) {
 	// (feed cipher.PubKey) (cs []*Conn)
 
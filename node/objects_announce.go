@@ -0,0 +1,109 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+	"github.com/skycoin/cxo/skyobject"
+)
+
+// announceObjectsLimit is max number of
+// keys the Node sends in one msg.Announce
+const announceObjectsLimit int = 128
+
+// An objectsAnnouncer broadcasts keys of objects
+// the Container stores after initial sync of
+// connections (see msg.Announce)
+type objectsAnnouncer struct {
+	quit   chan struct{}
+	await  sync.WaitGroup
+	closeo sync.Once
+}
+
+func (o *objectsAnnouncer) start(n *Node) {
+	o.quit = make(chan struct{})
+
+	o.await.Add(1)
+	go n.announceObjects(n.c.Committed())
+}
+
+func (o *objectsAnnouncer) close() {
+	o.closeo.Do(func() {
+
+		if o.quit == nil {
+			return // not started
+		}
+
+		close(o.quit)
+		o.await.Wait()
+
+	})
+}
+
+// announceObjects reads keys of new objects and sends
+// them to peers subscribed to feeds of the objects
+func (n *Node) announceObjects(
+	committed <-chan skyobject.CommittedObject,
+) {
+
+	defer n.announcer.await.Done()
+
+	var objs []skyobject.CommittedObject
+
+	for {
+
+		select {
+		case obj := <-committed:
+			objs = append(objs[:0], obj)
+		case <-n.announcer.quit:
+			return
+		}
+
+		// drain keys available, if any
+
+	Drain:
+		for len(objs) < announceObjectsLimit {
+			select {
+			case obj := <-committed:
+				objs = append(objs, obj)
+			default:
+				break Drain
+			}
+		}
+
+		n.broadcastAnnounce(objs)
+
+	}
+
+}
+
+// send keys of given objects to peers subscribed
+// to feeds of the objects; a peer receives keys of
+// objects of its feeds only
+func (n *Node) broadcastAnnounce(objs []skyobject.CommittedObject) {
+
+	var byFeed = make(map[cipher.PubKey][]cipher.SHA256)
+
+	for _, obj := range objs {
+		byFeed[obj.Feed] = append(byFeed[obj.Feed], obj.Key)
+	}
+
+	for _, c := range n.Connections() {
+
+		var hashes []cipher.SHA256
+
+		for _, pk := range n.fs.feedsOfConnection(c) {
+			hashes = append(hashes, byFeed[pk]...)
+		}
+
+		if len(hashes) == 0 {
+			continue // not subscribed to the feeds
+		}
+
+		c.sendMsg(c.nextSeq(), 0, &msg.Announce{Hashes: hashes})
+
+	}
+
+}
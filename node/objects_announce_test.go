@@ -0,0 +1,115 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_announceObjects(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+
+		announced = make(chan []cipher.SHA256, 10)
+		echoed    = make(chan []cipher.SHA256, 10)
+		filled    = make(chan *registry.Root, 10)
+	)
+
+	cconf.OnAnnounce = func(c *Conn, hashes []cipher.SHA256) {
+		announced <- hashes
+	}
+	cconf.OnRootFilled = func(_ *Node, r *registry.Root) {
+		filled <- r
+	}
+	sconf.OnAnnounce = func(c *Conn, hashes []cipher.SHA256) {
+		echoed <- hashes
+	}
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(cconf)
+	assertNil(t, err)
+	defer cn.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		opk, _ = cipher.GenerateKeyPair() // other feed
+	)
+
+	for _, feed := range []cipher.PubKey{pk, opk} {
+		assertNil(t, sn.Share(feed))
+		assertNil(t, cn.Share(feed))
+	}
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, c.Subscribe(pk)) // but not the opk
+
+	var (
+		sc  = sn.Container()
+		reg = getTestRegistry()
+	)
+
+	var up, uerr = sc.Unpack(sk, reg)
+	assertNil(t, uerr)
+	defer up.Close()
+
+	// objects of feed the peer is not subscribed to
+
+	var or = new(registry.Root)
+	or.Pub, or.Nonce = opk, 1
+	or.Refs = []registry.Dynamic{
+		dynamicByValue(t, up, "test.User", User{"Eve", 66, nil}),
+	}
+	assertNil(t, sc.Save(up, or))
+
+	select {
+	case <-announced:
+		t.Fatal("object of other feed announced")
+	case <-time.After(TM / 5):
+	}
+
+	// new object of the feed
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		dynamicByValue(t, up, "test.User", User{"Alice", 21, nil}),
+	}
+	assertNil(t, sc.Save(up, r))
+
+	select {
+	case hashes := <-announced:
+		assertTrue(t, len(hashes) == 1 && hashes[0] == r.Refs[0].Hash,
+			"wrong announce")
+	case <-time.After(TM):
+		t.Fatal("slow or missing announce")
+	}
+
+	// filled objects are not announced back
+
+	assertNil(t, sn.Publish(r))
+
+	select {
+	case <-filled:
+	case <-time.After(TM):
+		t.Fatal("slow or not filled")
+	}
+
+	select {
+	case <-echoed:
+		t.Fatal("filled objects announced")
+	case <-time.After(TM / 5):
+	}
+
+}
@@ -0,0 +1,162 @@
+package node
+
+import (
+	"sync/atomic"
+	"time"
+
+	discovery "github.com/skycoin/net/skycoin-messenger/factory"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// known addresses of a peer
+type peerAddresses struct {
+	id        cipher.PubKey // blank if unknown
+	addresses []string      // primary first
+}
+
+// groupByPeer groups addresses of given nodes by
+// id of the nodes keeping order; nodes with blank
+// id are not grouped
+func groupByPeer(nis []*discovery.NodeInfo) (ps []*peerAddresses) {
+
+	var byID = make(map[cipher.PubKey]*peerAddresses)
+
+	for _, ni := range nis {
+
+		if ni == nil {
+			continue // never happens
+		}
+
+		if pa, ok := byID[ni.PubKey]; ok == true {
+			pa.addresses = append(pa.addresses, ni.Address)
+			continue
+		}
+
+		var pa = &peerAddresses{ni.PubKey, []string{ni.Address}}
+
+		if ni.PubKey != (cipher.PubKey{}) {
+			byID[ni.PubKey] = pa
+		}
+
+		ps = append(ps, pa)
+
+	}
+
+	return
+}
+
+// connectToPeer returns existing connection to peer
+// with given id, or tries given addresses in order
+// until a connection is established
+func (n *Node) connectToPeer(
+	connect func(address string) (*Conn, error), // : TCP or UDP
+	id cipher.PubKey, //                           : id of the peer
+	addresses []string, //                         : addresses
+) (
+	c *Conn, //                                    : the connection
+	err error, //                                  : last error
+) {
+
+	if id != (cipher.PubKey{}) {
+		var ok bool
+		if c, ok = n.hasPeer(id); ok == true {
+			return // already connected
+		}
+	}
+
+	if len(addresses) == 0 {
+		return nil, ErrNoAddresses
+	}
+
+	for _, address := range addresses {
+
+		if c, err = connect(address); err == nil {
+			return // connected
+		}
+
+		n.Debugf(ConnPin, "can't connect to %q: %v (try next address)",
+			address, err)
+
+	}
+
+	return
+}
+
+// ConnectToPeer connects to a peer that has given
+// addresses. The addresses are tried in order, and
+// an alternate is used only if previous ones fail.
+// The ConnectToPeer returns existing connection to
+// the peer if the id is known (not blank) and the
+// Node already has connection to the peer. It returns
+// error of the last address if all addresses fail,
+// and ErrNoAddresses if the list is empty
+func (t *TCP) ConnectToPeer(
+	id cipher.PubKey, //       : id of the peer or blank
+	addresses ...string, //    : addresses, primary first
+) (
+	c *Conn, //                : the connection
+	err error, //              : an error
+) {
+	return t.n.connectToPeer(t.Connect, id, addresses)
+}
+
+// ConnectToPeer connects to a peer that has given
+// addresses (see ConnectToPeer method of the TCP)
+func (u *UDP) ConnectToPeer(
+	id cipher.PubKey, //       : id of the peer or blank
+	addresses ...string, //    : addresses, primary first
+) (
+	c *Conn, //                : the connection
+	err error, //              : an error
+) {
+	return u.n.connectToPeer(u.Connect, id, addresses)
+}
+
+// LastSeen returns time of last message
+// received from the peer, or time of
+// creation of the connection if the peer
+// has not sent anything yet
+func (c *Conn) LastSeen() (seen time.Time) {
+	return time.Unix(0, atomic.LoadInt64(&c.seen))
+}
+
+// A PeerInfo represents state of a connection
+// to a peer (see Peers method of the Node)
+type PeerInfo struct {
+	Address   string    // remote address
+	TCP       bool      // TCP or UDP
+	Connected bool      // established or pending (handshake)
+	Incoming  bool      // direction
+	LastSeen  time.Time // last received message
+}
+
+// Peers returns state of all connections of the Node,
+// including pending ones. The Node doesn't keep peers
+// it has been disconnected from, thus the Peers doesn't
+// return them
+func (n *Node) Peers() (ps []PeerInfo) {
+
+	n.mx.Lock()
+	defer n.mx.Unlock()
+
+	ps = make([]PeerInfo, 0, len(n.pc)+len(n.ic))
+
+	for c := range n.pc {
+		ps = append(ps, c.peerInfo(false))
+	}
+
+	for _, c := range n.ic {
+		ps = append(ps, c.peerInfo(true))
+	}
+
+	return
+}
+
+func (c *Conn) peerInfo(connected bool) (pi PeerInfo) {
+	pi.Address = c.Address()
+	pi.TCP = c.IsTCP()
+	pi.Connected = connected
+	pi.Incoming = c.IsIncoming()
+	pi.LastSeen = c.LastSeen()
+	return
+}
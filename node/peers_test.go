@@ -0,0 +1,71 @@
+package node
+
+import (
+	"testing"
+
+	discovery "github.com/skycoin/net/skycoin-messenger/factory"
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func Test_groupByPeer(t *testing.T) {
+
+	var (
+		a, _ = cipher.GenerateKeyPair()
+		b, _ = cipher.GenerateKeyPair()
+
+		ps = groupByPeer([]*discovery.NodeInfo{
+			{PubKey: a, Address: "1"},
+			{PubKey: b, Address: "2"},
+			{PubKey: a, Address: "3"},
+		})
+	)
+
+	assertTrue(t, len(ps) == 2, "wrong number of peers")
+	assertTrue(t, ps[0].id == a && len(ps[0].addresses) == 2,
+		"wrong first peer")
+	assertTrue(t, ps[0].addresses[0] == "1" && ps[0].addresses[1] == "3",
+		"wrong order of addresses")
+	assertTrue(t, ps[1].id == b && len(ps[1].addresses) == 1,
+		"wrong second peer")
+
+}
+
+func TestTCP_ConnectToPeer(t *testing.T) {
+
+	var sn, err = NewNode(getTestConfig("server"))
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(getTestConfigNotListen("client"))
+	assertNil(t, err)
+	defer cn.Close()
+
+	_, err = cn.TCP().ConnectToPeer(sn.ID())
+	assertTrue(t, err == ErrNoAddresses, "missing ErrNoAddresses")
+
+	t.Run("fallback", func(t *testing.T) {
+
+		var c *Conn
+		c, err = cn.TCP().ConnectToPeer(sn.ID(), "127.0.0.1:1",
+			sn.TCP().Address())
+		assertNil(t, err)
+
+		assertTrue(t, c.Address() == sn.TCP().Address(),
+			"alternate address is not used")
+
+	})
+
+	t.Run("existing", func(t *testing.T) {
+
+		var c *Conn
+		c, err = cn.TCP().ConnectToPeer(sn.ID(), "127.0.0.1:1")
+		assertNil(t, err)
+
+		assertTrue(t, c.Address() == sn.TCP().Address(),
+			"existing connection is not used")
+		assertTrue(t, len(cn.Connections()) == 1, "wrong connections")
+
+	})
+
+}
@@ -0,0 +1,157 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// an objectRequest is a request of an object
+// sent to a peer and not resolved yet
+type objectRequest struct {
+	done chan struct{} // closed after the request
+	val  []byte        // the object
+	err  error         // or an error
+}
+
+// requests of objects in flight by key
+// (see DedupRequests)
+type objectRequests struct {
+	mx sync.Mutex
+	rs map[cipher.SHA256]*objectRequest
+}
+
+// acquire returns existing request or creates new;
+// the first is true if the request has been created
+func (o *objectRequests) acquire(
+	key cipher.SHA256, // : key of the object
+) (
+	rq *objectRequest, // : the request
+	first bool, //        : created
+) {
+
+	o.mx.Lock()
+	defer o.mx.Unlock()
+
+	if rq = o.rs[key]; rq != nil {
+		return
+	}
+
+	if o.rs == nil {
+		o.rs = make(map[cipher.SHA256]*objectRequest)
+	}
+
+	rq = &objectRequest{done: make(chan struct{})}
+	o.rs[key] = rq
+
+	return rq, true
+}
+
+// release resolves given request
+func (o *objectRequests) release(
+	key cipher.SHA256,
+	rq *objectRequest,
+	val []byte,
+	err error,
+) {
+
+	o.mx.Lock()
+	defer o.mx.Unlock()
+
+	delete(o.rs, key)
+
+	rq.val, rq.err = val, err
+	close(rq.done)
+}
+
+// inFlight returns true if there is
+// a request of object with given key
+func (o *objectRequests) inFlight(key cipher.SHA256) (ok bool) {
+
+	o.mx.Lock()
+	defer o.mx.Unlock()
+
+	_, ok = o.rs[key]
+	return
+}
+
+// an errorReply is msg.Err reply of a peer
+type errorReply string
+
+// Error implements error interface
+func (e errorReply) Error() string {
+	return "error: " + string(e)
+}
+
+// requestObject requests object with given key from
+// the peer; the requestObject returns errorReply if
+// the peer replies with msg.Err, and ErrInvalidResponse
+// if the peer replies with something other then the
+// object
+func (c *Conn) requestObject(key cipher.SHA256) (val []byte, err error) {
+
+	var reply msg.Msg
+	if reply, err = c.sendRequest(&msg.RqObject{Key: key}); err != nil {
+		return
+	}
+
+	switch x := reply.(type) {
+	case *msg.Object:
+		if cipher.SumSHA256(x.Value) == key {
+			return x.Value, nil
+		}
+	case *msg.Err:
+		if x.Err == ErrTooManyRequests.Error() {
+			return nil, ErrTooManyRequests // throttled, not invalid
+		}
+		return nil, errorReply(x.Err)
+	}
+
+	return nil, ErrInvalidResponse
+}
+
+// requestObject requests object with given key from
+// given peer. If the DedupRequests is true, and the
+// object is already requested from a peer, then the
+// requestObject doesn't send new request and waits
+// for the first one. If the first request fails,
+// then the requestObject tries again
+func (n *Node) requestObject(
+	c *Conn, //           : the peer
+	key cipher.SHA256, // : key of the object
+) (
+	val []byte, //        : the object
+	err error, //         : an error
+) {
+
+	if n.config.DedupRequests == false {
+		return c.requestObject(key)
+	}
+
+	for {
+
+		var rq, first = n.rqs.acquire(key)
+
+		if first == true {
+			val, err = c.requestObject(key)
+			n.rqs.release(key, rq, val, err)
+			return
+		}
+
+		select {
+		case <-rq.done:
+		case <-c.closeq:
+			return nil, ErrClosed
+		}
+
+		if rq.err == nil {
+			return rq.val, nil
+		}
+
+		// the first request fails, try again
+
+	}
+
+}
@@ -0,0 +1,102 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+func TestConfig_DedupRequests(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("server")
+
+		announced = make(chan *Conn, 3)
+	)
+
+	sconf.OnAnnounce = func(c *Conn, _ []cipher.SHA256) {
+		announced <- c
+	}
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var (
+		val = []byte("the object")
+		key = cipher.SumSHA256(val)
+
+		peers []*Node
+		conns []*Conn // connections of the peers
+	)
+
+	// the peers announce the same object they don't have yet
+
+	for i := 0; i < 3; i++ {
+
+		var cn *Node
+		cn, err = NewNode(getTestConfigNotListen("client"))
+		assertNil(t, err)
+		defer cn.Close()
+
+		var c *Conn
+		c, err = cn.TCP().Connect(sn.TCP().Address())
+		assertNil(t, err)
+
+		c.sendMsg(c.nextSeq(), 0, &msg.Announce{Hashes: []cipher.SHA256{key}})
+
+		peers, conns = append(peers, cn), append(conns, c)
+	}
+
+	type result struct {
+		val []byte
+		err error
+	}
+
+	var results = make(chan result, 3)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case c := <-announced:
+			go func() {
+				var val, err = sn.requestObject(c, key)
+				results <- result{val, err}
+			}()
+		case <-time.After(TM):
+			t.Fatal("slow")
+		}
+	}
+
+	time.Sleep(TM / 5)
+
+	var inFlight int
+	for _, c := range conns {
+		inFlight += c.InFlight()
+	}
+
+	assertTrue(t, inFlight == 1, "wrong number of requests in flight")
+	assertTrue(t, sn.rqs.inFlight(key) == true, "not tracked")
+
+	// resolve the request
+
+	for _, cn := range peers {
+		_, err = cn.Container().Set(key, val, 1)
+		assertNil(t, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case res := <-results:
+			assertNil(t, res.err)
+			assertTrue(t, string(res.val) == string(val), "wrong object")
+		case <-time.After(TM):
+			t.Fatal("slow")
+		}
+	}
+
+	assertTrue(t, sn.rqs.inFlight(key) == false, "not released")
+
+}
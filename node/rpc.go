@@ -1,20 +1,33 @@
 package node
 
 import (
+	"crypto/subtle"
+	"encoding/binary"
 	"errors"
+	"io"
+	"math"
 	"net"
 	"net/rpc"
+	"sync"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
+// RPC authentication (see RPCToken)
+const (
+	rpcAuthTimeout = 10 * time.Second // time to present token
+	rpcAuthMagic   = "CXOA"           // start of authentication
+)
+
 // wrap the RPC
 type rpcServer struct {
 	l net.Listener // underlying listener
 	r *rpc.Server  //
 	n *Node        // back reference
+	h *RPC         // node.* methods and custom handlers
 }
 
 // create RPC server
@@ -22,12 +35,13 @@ func (n *Node) newRPC() (r *rpcServer) {
 	r = new(rpcServer)
 	r.n = n
 	r.r = rpc.NewServer()
+	r.h = &RPC{n: n}
 	return
 }
 
 func (r *rpcServer) Listen(address string) (err error) {
 
-	r.r.RegisterName("node", &RPC{r.n})
+	r.r.RegisterName("node", r.h)
 
 	r.r.RegisterName("tcp", &TCPRPC{r.n})
 	r.r.RegisterName("udp", &UDPRPC{r.n})
@@ -46,7 +60,98 @@ func (r *rpcServer) Listen(address string) (err error) {
 
 func (r *rpcServer) run() {
 	defer r.n.await.Done()
-	r.r.Accept(r.l)
+
+	var token = r.n.config.RPCToken
+
+	if token == "" {
+		r.r.Accept(r.l) // no authentication
+		return
+	}
+
+	for {
+		var conn, err = r.l.Accept()
+		if err != nil {
+			return // closed
+		}
+		go r.serveAuth(conn, token)
+	}
+}
+
+// serveAuth serves given connection if it
+// presents given token (see rpcAuth)
+func (r *rpcServer) serveAuth(conn net.Conn, token string) {
+
+	conn.SetDeadline(time.Now().Add(rpcAuthTimeout))
+
+	if err := rpcAuthServer(conn, token); err != nil {
+		r.n.Printf("[ERR] [%s] RPC: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	conn.SetDeadline(time.Time{}) // clear
+	r.r.ServeConn(conn)
+}
+
+// rpcAuthServer reads token and compares it with
+// given one; the client sends the rpcAuthMagic, length
+// of the token (little-endian uint16) and the token;
+// the server replies with single byte, 1 if the token
+// is valid; the magic is used to reject clients that
+// don't present a token at all immediately
+func rpcAuthServer(conn net.Conn, token string) (err error) {
+
+	var head [len(rpcAuthMagic) + 2]byte
+	if _, err = io.ReadFull(conn, head[:]); err != nil {
+		return
+	}
+
+	if string(head[:len(rpcAuthMagic)]) != rpcAuthMagic {
+		return ErrRPCAuth // no token
+	}
+
+	var got = make([]byte,
+		binary.LittleEndian.Uint16(head[len(rpcAuthMagic):]))
+	if _, err = io.ReadFull(conn, got); err != nil {
+		return
+	}
+
+	if subtle.ConstantTimeCompare(got, []byte(token)) != 1 {
+		conn.Write([]byte{0})
+		return ErrRPCAuth
+	}
+
+	_, err = conn.Write([]byte{1})
+	return
+}
+
+// rpcAuthClient sends given token (see rpcAuthServer)
+func rpcAuthClient(conn net.Conn, token string) (err error) {
+
+	if len(token) > math.MaxUint16 {
+		return ErrRPCAuth
+	}
+
+	var msg = make([]byte, len(rpcAuthMagic)+2+len(token))
+
+	copy(msg, rpcAuthMagic)
+	binary.LittleEndian.PutUint16(msg[len(rpcAuthMagic):], uint16(len(token)))
+	copy(msg[len(rpcAuthMagic)+2:], token)
+
+	if _, err = conn.Write(msg); err != nil {
+		return
+	}
+
+	var reply [1]byte
+	if _, err = io.ReadFull(conn, reply[:]); err != nil {
+		return
+	}
+
+	if reply[0] != 1 {
+		return ErrRPCAuth
+	}
+
+	return
 }
 
 func (r *rpcServer) Address() (address string) {
@@ -69,9 +174,71 @@ func (r *rpcServer) Close() (err error) {
 // registered by the rpc package. The RPC
 // can't be used outside the Node.
 //
-// Short words, the RPC is internal
+// Short words, the RPC is internal. Except
+// the Register method that allows to add custom
+// handlers (see (*Node).RPC)
 type RPC struct {
 	n *Node // back reference
+
+	mx sync.Mutex
+	hs map[string]RPCHandler // custom handlers
+}
+
+// An RPCHandler represents custom RPC handler.
+// It receives encoded arguments and returns
+// encoded reply. Encoding is up to the handler
+type RPCHandler func(args []byte) (reply []byte, err error)
+
+// Register custom RPC handler with given name. Use
+// (*RPCClientNode).Call to call the handler. The
+// Register returns ErrRPCHandlerExists if a handler
+// with the same name already registered
+func (r *RPC) Register(
+	name string, //        : name of the handler
+	handler RPCHandler, // : the handler
+) (
+	err error, //          : an error
+) {
+
+	if handler == nil {
+		panic("nil handler") // for developers
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if _, ok := r.hs[name]; ok == true {
+		return ErrRPCHandlerExists
+	}
+
+	if r.hs == nil {
+		r.hs = make(map[string]RPCHandler)
+	}
+
+	r.hs[name] = handler
+	return
+}
+
+// An RPCCall represents name of
+// custom RPC handler and arguments
+type RPCCall struct {
+	Name string
+	Args []byte
+}
+
+// Call is RPC method
+func (r *RPC) Call(call RPCCall, reply *[]byte) (err error) {
+
+	r.mx.Lock()
+	var handler, ok = r.hs[call.Name]
+	r.mx.Unlock()
+
+	if ok == false {
+		return ErrNoSuchRPCHandler
+	}
+
+	*reply, err = handler(call.Args)
+	return
 }
 
 // Share is RPC method
@@ -84,6 +251,11 @@ func (r *RPC) DontShare(pk cipher.PubKey, _ *struct{}) (err error) {
 	return r.n.DontShare(pk)
 }
 
+// DropFeed is RPC method
+func (r *RPC) DropFeed(pk cipher.PubKey, _ *struct{}) (err error) {
+	return r.n.DropFeed(pk)
+}
+
 // Feeds is RPC method
 func (r *RPC) Feeds(_ struct{}, fs *[]cipher.PubKey) (_ error) {
 	*fs = r.n.Feeds()
@@ -120,6 +292,12 @@ func (r *RPC) Connections(_ struct{}, cs *[]string) (_ error) {
 	return
 }
 
+// Peers is RPC method
+func (r *RPC) Peers(_ struct{}, ps *[]PeerInfo) (_ error) {
+	*ps = r.n.Peers()
+	return
+}
+
 // ConnectionsOfFeed is RPC method
 func (r *RPC) ConnectionsOfFeed(feed cipher.PubKey, cs *[]string) (_ error) {
 	var cf = r.n.ConnectionsOfFeed(feed)
@@ -152,6 +330,29 @@ func (r *RPC) Stat(_ struct{}, stat *Stat) (err error) {
 	return
 }
 
+// Health is RPC method
+func (r *RPC) Health(_ struct{}, health *Health) (err error) {
+	var h *Health
+	if h, err = r.n.Health(); err != nil {
+		return
+	}
+	*health = *h
+	return
+}
+
+// A FeedPath represents feed and name
+// of file in the ExportDir (see ExportFeed)
+type FeedPath struct {
+	Feed cipher.PubKey
+	Path string
+}
+
+// ExportFeed is RPC method
+func (r *RPC) ExportFeed(fp FeedPath, objects *int) (err error) {
+	*objects, err = r.n.ExportFeed(fp.Feed, fp.Path)
+	return
+}
+
 // A TCPRPC represents RPC object
 // of TCP transport of the Node
 type TCPRPC struct {
@@ -1,6 +1,7 @@
 package node
 
 import (
+	"net"
 	"net/rpc"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -51,6 +52,27 @@ func NewRPCClient(address string) (rc *RPCClient, err error) {
 	return
 }
 
+// NewRPCClientToken creates RPC client connected to RPC
+// server with given address, presenting given token (see
+// RPCToken field of Config). It returns ErrRPCAuth if the
+// server rejects the token
+func NewRPCClientToken(address, token string) (rc *RPCClient, err error) {
+
+	var conn net.Conn
+	if conn, err = net.Dial("tcp", address); err != nil {
+		return
+	}
+
+	if err = rpcAuthClient(conn, token); err != nil {
+		conn.Close()
+		return
+	}
+
+	rc = new(RPCClient)
+	rc.c = rpc.NewClient(conn)
+	return
+}
+
 // An RPCClientNode implements RPC
 // methods related to the Node
 type RPCClientNode struct {
@@ -67,6 +89,12 @@ func (r *RPCClientNode) DontShare(pk cipher.PubKey) (err error) {
 	return r.r.c.Call("node.DontShare", pk, &struct{}{})
 }
 
+// DropFeed stops sharing given feed and
+// removes it from DB of the Node
+func (r *RPCClientNode) DropFeed(pk cipher.PubKey) (err error) {
+	return r.r.c.Call("node.DropFeed", pk, &struct{}{})
+}
+
 // Feeds that the Node is shareing
 func (r *RPCClientNode) Feeds() (fs []cipher.PubKey, err error) {
 	err = r.r.c.Call("node.Feeds", struct{}{}, &fs)
@@ -85,6 +113,13 @@ func (r *RPCClientNode) Connections() (cs []string, err error) {
 	return
 }
 
+// Peers returns state of connections of
+// the Node (see (*Node).Peers for details)
+func (r *RPCClientNode) Peers() (ps []PeerInfo, err error) {
+	err = r.r.c.Call("node.Peers", struct{}{}, &ps)
+	return
+}
+
 // ConnectionsOfFeed of the Node
 func (r *RPCClientNode) ConnectionsOfFeed(
 	pk cipher.PubKey, // :
@@ -115,6 +150,37 @@ func (r *RPCClientNode) Stat() (stat *Stat, err error) {
 	return &s, nil
 }
 
+// Health checks that the Node is alive
+// (see (*Node).Health for details)
+func (r *RPCClientNode) Health() (health *Health, err error) {
+	var h Health
+	if err = r.r.c.Call("node.Health", struct{}{}, &h); err != nil {
+		return
+	}
+	return &h, nil
+}
+
+// ExportFeed exports last Root of given feed to file
+// with given name in ExportDir on host of the Node and
+// returns number of exported objects (see (*Node).ExportFeed)
+func (r *RPCClientNode) ExportFeed(
+	feed cipher.PubKey, // : the feed
+	name string, //        : name of file in the ExportDir
+) (
+	objects int, //        : number of exported objects
+	err error, //          : an error
+) {
+	err = r.r.c.Call("node.ExportFeed", FeedPath{feed, name}, &objects)
+	return
+}
+
+// Call custom RPC handler registered using
+// (*RPC).Register with given name
+func (r *RPCClientNode) Call(name string, args []byte) (reply []byte, err error) {
+	err = r.r.c.Call("node.Call", RPCCall{Name: name, Args: args}, &reply)
+	return
+}
+
 // A RPCClientTCP implements RPC
 // methods related to TCP transport
 type RPCClientTCP struct {
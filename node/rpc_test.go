@@ -0,0 +1,336 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func getTestRPCNode(t *testing.T) (n *Node, rc *RPCClient) {
+
+	var conf = getTestConfigNotListen("rpc")
+	conf.RPC = "127.0.0.1:0"
+
+	var err error
+	if n, err = NewNode(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	if rc, err = NewRPCClient(n.rpc.Address()); err != nil {
+		n.Close()
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestRPCClientNode_feeds(t *testing.T) {
+
+	var n, rc = getTestRPCNode(t)
+	defer n.Close()
+	defer rc.Close()
+
+	var (
+		pk, _ = cipher.GenerateKeyPair()
+		rn    = rc.Node()
+
+		fs  []cipher.PubKey
+		yep bool
+		err error
+	)
+
+	// share
+
+	assertNil(t, rn.Share(pk))
+
+	fs, err = rn.Feeds()
+	assertNil(t, err)
+	assertTrue(t, len(fs) == 1 && fs[0] == pk, "wrong feeds")
+
+	yep, err = rn.IsSharing(pk)
+	assertNil(t, err)
+	assertTrue(t, yep, "not sharing")
+	assertTrue(t, n.Container().HasFeed(pk), "not saved")
+
+	// don't share (keep in DB)
+
+	assertNil(t, rn.DontShare(pk))
+
+	fs, err = rn.Feeds()
+	assertNil(t, err)
+	assertTrue(t, len(fs) == 0, "wrong feeds")
+
+	yep, err = rn.IsSharing(pk)
+	assertNil(t, err)
+	assertTrue(t, yep == false, "sharing")
+	assertTrue(t, n.Container().HasFeed(pk), "removed from DB")
+
+	// share and drop
+
+	assertNil(t, rn.Share(pk))
+	assertNil(t, rn.DropFeed(pk))
+
+	yep, err = rn.IsSharing(pk)
+	assertNil(t, err)
+	assertTrue(t, yep == false, "sharing")
+	assertTrue(t, n.Container().HasFeed(pk) == false, "not removed from DB")
+
+	assertTrue(t, rn.DropFeed(pk) != nil, "missing error")
+
+}
+
+func TestRPCClientNode_Peers(t *testing.T) {
+
+	var n, rc = getTestRPCNode(t)
+	defer n.Close()
+	defer rc.Close()
+
+	var sn, err = NewNode(getTestConfig("server"))
+	assertNil(t, err)
+	defer sn.Close()
+
+	var ps []PeerInfo
+
+	ps, err = rc.Node().Peers()
+	assertNil(t, err)
+	assertTrue(t, len(ps) == 0, "unexpected peers")
+
+	var tp = time.Now()
+
+	_, err = n.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	ps, err = rc.Node().Peers()
+	assertNil(t, err)
+	assertTrue(t, len(ps) == 1, "wrong number of peers")
+
+	var pi = ps[0]
+
+	assertTrue(t, pi.Address == sn.TCP().Address(), "wrong address")
+	assertTrue(t, pi.TCP == true, "not TCP")
+	assertTrue(t, pi.Connected == true, "not connected")
+	assertTrue(t, pi.Incoming == false, "wrong direction")
+	assertTrue(t, pi.LastSeen.Before(tp) == false, "wrong last seen")
+
+	// the other side
+
+	ps = sn.Peers()
+	assertTrue(t, len(ps) == 1, "wrong number of peers")
+	assertTrue(t, ps[0].Incoming == true, "wrong direction")
+
+}
+
+func TestRPCClientNode_Call(t *testing.T) {
+
+	var n, rc = getTestRPCNode(t)
+	defer n.Close()
+	defer rc.Close()
+
+	var echo = func(args []byte) (reply []byte, err error) {
+		if len(args) == 0 {
+			return nil, errors.New("empty args")
+		}
+		return append([]byte("echo: "), args...), nil
+	}
+
+	assertNil(t, n.RPC().Register("echo", echo))
+	assertTrue(t, n.RPC().Register("echo", echo) == ErrRPCHandlerExists,
+		"missing or wrong error")
+
+	var reply, err = rc.Node().Call("echo", []byte("hello"))
+	assertNil(t, err)
+	assertTrue(t, string(reply) == "echo: hello", "wrong reply")
+
+	_, err = rc.Node().Call("echo", nil)
+	assertTrue(t, err != nil && err.Error() == "empty args",
+		"missing or wrong error")
+
+	_, err = rc.Node().Call("unknown", nil)
+	assertTrue(t, err != nil && err.Error() == ErrNoSuchRPCHandler.Error(),
+		"missing or wrong error")
+
+}
+
+func TestRPCClientNode_ExportFeed(t *testing.T) {
+
+	var n, rc = getTestRPCNode(t)
+	defer n.Close()
+	defer rc.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		reg    = getTestRegistry()
+		c      = n.Container()
+	)
+
+	assertNil(t, n.Share(pk))
+
+	var up, err = c.Unpack(sk, reg)
+	assertNil(t, err)
+	defer up.Close()
+
+	var feed Feed
+	assertNil(t, feed.Posts.AppendValues(up,
+		Post{"first", "hello", 1}, Post{"second", "bye", 2}))
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	r.Refs = []registry.Dynamic{
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}),
+		dynamicByValue(t, up, "test.Feed", feed),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var dir string
+	dir, err = ioutil.TempDir("", "export")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		path    = filepath.Join(dir, "feed.cxo")
+		objects int
+	)
+
+	// disabled
+
+	_, err = rc.Node().ExportFeed(pk, "feed.cxo")
+	assertTrue(t, err != nil && err.Error() == ErrExportDisabled.Error(),
+		fmt.Sprint("wrong error: ", err))
+
+	n.config.ExportDir = dir
+
+	// not a name of file
+
+	for _, name := range []string{path, "../feed.cxo", "", ".."} {
+		_, err = rc.Node().ExportFeed(pk, name)
+		assertTrue(t, err != nil && err.Error() == ErrInvalidExportName.Error(),
+			fmt.Sprint("wrong error: ", err))
+	}
+
+	objects, err = rc.Node().ExportFeed(pk, "feed.cxo")
+	assertNil(t, err)
+
+	// registry, user, feed, refs, 2 posts
+	assertTrue(t, objects == 6, fmt.Sprint("wrong number of objects: ",
+		objects))
+
+	// import into fresh container
+
+	var conf = skyobject.NewConfig()
+	conf.InMemoryDB = true
+
+	var fresh *skyobject.Container
+	fresh, err = skyobject.NewContainer(conf)
+	assertNil(t, err)
+	defer fresh.Close()
+
+	assertNil(t, fresh.AddFeed(pk))
+
+	var fl *os.File
+	fl, err = os.Open(path)
+	assertNil(t, err)
+	defer fl.Close()
+
+	var ir *registry.Root
+	ir, err = fresh.Import(fl)
+	assertNil(t, err)
+	assertTrue(t, ir.Hash == r.Hash, "wrong Root")
+
+	var complete bool
+	complete, _, err = fresh.RootComplete(ir)
+	assertNil(t, err)
+	assertTrue(t, complete == true, "not complete")
+
+	var lr *registry.Root
+	lr, err = fresh.LastRoot(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "the Root is not saved")
+
+	// existing file is not overwritten
+
+	_, err = rc.Node().ExportFeed(pk, "feed.cxo")
+	assertTrue(t, err != nil, "existing file overwritten")
+
+	var info os.FileInfo
+	info, err = os.Stat(path)
+	assertNil(t, err)
+	assertTrue(t, info.Size() > 0, "existing file truncated")
+
+	// no such feed
+
+	var other, _ = cipher.GenerateKeyPair()
+	_, err = rc.Node().ExportFeed(other, "other.cxo")
+	assertTrue(t, err != nil, "missing error")
+
+	// failed export doesn't keep partial file
+
+	var broken = *r
+	broken.Refs = append([]registry.Dynamic(nil), r.Refs...)
+	broken.Refs[1].Hash = cipher.SumSHA256([]byte("missing"))
+
+	var brokenPath = filepath.Join(dir, "broken.cxo")
+
+	_, err = n.exportRoot(&broken, brokenPath)
+	assertTrue(t, err != nil, "missing error")
+
+	_, err = os.Stat(brokenPath)
+	assertTrue(t, os.IsNotExist(err) == true, "partial file kept")
+
+	objects, err = n.exportRoot(r, brokenPath)
+	assertNil(t, err)
+	assertTrue(t, objects == 6, fmt.Sprint("wrong number of objects: ",
+		objects))
+
+}
+
+func TestConfig_RPCToken(t *testing.T) {
+
+	var conf = getTestConfigNotListen("rpc")
+	conf.RPC = "127.0.0.1:0"
+	conf.RPCToken = "secret"
+
+	var n, err = NewNode(conf)
+	assertNil(t, err)
+	defer n.Close()
+
+	var address = n.rpc.Address()
+
+	// without token
+
+	var rc *RPCClient
+	rc, err = NewRPCClient(address)
+	assertNil(t, err)
+	defer rc.Close()
+
+	_, err = rc.Node().Feeds()
+	assertTrue(t, err != nil, "unauthenticated call served")
+
+	// wrong token
+
+	_, err = NewRPCClientToken(address, "wrong")
+	assertTrue(t, err == ErrRPCAuth, fmt.Sprint("wrong error: ", err))
+
+	// valid token
+
+	var pk, _ = cipher.GenerateKeyPair()
+	assertNil(t, n.Share(pk))
+
+	var ac *RPCClient
+	ac, err = NewRPCClientToken(address, "secret")
+	assertNil(t, err)
+	defer ac.Close()
+
+	var fs []cipher.PubKey
+	fs, err = ac.Node().Feeds()
+	assertNil(t, err)
+	assertTrue(t, len(fs) == 1 && fs[0] == pk, "wrong feeds")
+
+}
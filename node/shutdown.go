@@ -0,0 +1,69 @@
+package node
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// interval of checking in-flight work (see Shutdown)
+const shutdownCheckInterval = 50 * time.Millisecond
+
+// Shutdown closes the Node gracefully. It stops
+// accepting new work: incoming connections are
+// rejected with ErrShuttingDown and new Root objects
+// are not filled. Then it waits for Root objects
+// being filled, requests of remote peers handled now
+// and messages queued to be sent, until given context
+// is done. After all, it closes the Node. The Shutdown
+// returns error of the context if the context is done
+// before the in-flight work finished, or error of the
+// Close method. The Close method is hard stop that
+// doesn't wait for in-flight work
+func (n *Node) Shutdown(ctx context.Context) (err error) {
+
+	atomic.StoreInt32(&n.draining, 1)
+
+	var tk = time.NewTicker(shutdownCheckInterval)
+	defer tk.Stop()
+
+	for err == nil {
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-n.closeq:
+			return n.Close() // closed by Close
+		case <-tk.C:
+			if n.isBusy() == false {
+				return n.Close()
+			}
+		}
+
+	}
+
+	n.Close()
+	return
+}
+
+func (n *Node) isDraining() bool {
+	return atomic.LoadInt32(&n.draining) == 1
+}
+
+// isBusy returns true if there are Root objects being
+// filled, requests of peers handled now or messages
+// queued to be sent
+func (n *Node) isBusy() (busy bool) {
+
+	if atomic.LoadInt32(&n.filling) > 0 {
+		return true
+	}
+
+	for _, c := range n.Connections() {
+		if c.InFlight() > 0 || len(c.sendq) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// testShutdown starts given stop function when the receiver gets
+// a Root from the sender, and returns true if the Root filled
+func testShutdown(t *testing.T, stop func(rn *Node)) (filled bool) {
+	t.Helper()
+
+	var (
+		fr, onRootFilled = onRootFilledToChannel(1)
+		sn               = getTestNode("sender")
+		rconf            = getTestConfig("receiver")
+
+		rn *Node
+	)
+
+	defer sn.Close()
+
+	rconf.TCP.Listen, rconf.UDP.Listen = "", "" // don't listen
+	rconf.OnRootFilled = onRootFilled
+	rconf.OnRootReceived = func(*Conn, *registry.Root) (_ error) {
+		stop(rn)
+		return
+	}
+
+	var err error
+	if rn, err = NewNode(rconf); err != nil {
+		t.Fatal(err)
+	}
+	defer rn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.Share(pk))
+
+	var (
+		reg = getTestRegistry()
+		sc  = sn.Container()
+
+		up *skyobject.Unpack
+	)
+
+	if up, err = sc.Unpack(sk, reg); err != nil {
+		t.Fatal(err)
+	}
+
+	var r = new(registry.Root)
+	r.Nonce, r.Pub = 1, pk
+
+	for _, name := range []string{"Alice", "Bob", "Eva", "Ammy"} {
+		r.Refs = append(r.Refs,
+			dynamicByValue(t, up, "test.User", User{name, 19, nil}))
+	}
+
+	assertNil(t, sc.Save(up, r))
+
+	var c *Conn
+	if c, err = rn.TCP().Connect(sn.TCP().Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNil(t, c.Subscribe(pk))
+
+	select {
+	case <-fr:
+		return true
+	case <-rn.closeq:
+		select {
+		case <-fr:
+			return true
+		case <-time.After(TM):
+			return false
+		}
+	case <-time.After(4 * TM):
+		t.Fatal("slow")
+	}
+
+	return
+}
+
+func TestNode_Shutdown(t *testing.T) {
+
+	t.Run("shutdown", func(t *testing.T) {
+
+		var shutdown = make(chan error, 1)
+
+		var filled = testShutdown(t, func(rn *Node) {
+			go func() {
+				var ctx, cancel = context.WithTimeout(context.Background(),
+					4*TM)
+				defer cancel()
+				shutdown <- rn.Shutdown(ctx)
+			}()
+		})
+
+		assertTrue(t, filled == true, "not filled")
+
+		select {
+		case err := <-shutdown:
+			assertNil(t, err)
+		case <-time.After(4 * TM):
+			t.Fatal("slow")
+		}
+
+	})
+
+	t.Run("close", func(t *testing.T) {
+
+		var filled = testShutdown(t, func(rn *Node) {
+			go rn.Close()
+			<-rn.closeq // wait for the closing
+		})
+
+		assertTrue(t, filled == false, "filled")
+
+	})
+
+}
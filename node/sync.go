@@ -0,0 +1,98 @@
+package node
+
+import (
+	"net"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// sendEverythingWeHave sends last Root objects of feeds
+// the connection is subscribed to (e.g. subscribed by
+// the OnConnect callback) and the SyncDone message
+// after; it's called right after the connection has
+// been established; the Root objects are not sent
+// if the AnnounceOnConnect is false, or if the
+// connection is outgoing and the AnnounceToOutgoing
+// is false; if the AnnounceRate is set, then the Root
+// objects are paced and the SyncDone message is queued
+// after them
+func (c *Conn) sendEverythingWeHave() {
+
+	for _, pk := range c.n.fs.feedsOfConnection(c) {
+		c.sendInitialRoot(pk)
+	}
+
+	if c.n.config.AnnounceRate > 0 {
+		c.pushSyncDone() // after the Root objects (see AnnounceRate)
+		return
+	}
+
+	c.sendSyncDone()
+}
+
+func (c *Conn) sendSyncDone() {
+	c.sendMsg(c.nextSeq(), 0, &msg.SyncDone{})
+}
+
+// isAnnounced returns true if last Root objects are
+// sent to the peer on connect and after a subscription;
+// they are not sent if the AnnounceOnConnect is false,
+// and they are sent to outgoing connections only if
+// the AnnounceToOutgoing is true
+func (c *Conn) isAnnounced() bool {
+
+	if c.n.config.AnnounceOnConnect == false {
+		return false
+	}
+
+	return c.incoming == true || c.n.config.AnnounceToOutgoing == true
+}
+
+// sendInitialRoot sends last Root of given feed
+// to the peer if the connection is announced
+func (c *Conn) sendInitialRoot(pk cipher.PubKey) {
+	if c.isAnnounced() == true {
+		c.sendLastRoot(pk)
+	}
+}
+
+func (c *Conn) handleSyncDone() {
+
+	c.n.Debugf(MsgReceivePin, "[%s] handleSyncDone", c.String())
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.synced = true
+}
+
+// IsSynced returns true if the peer has sent its
+// initial announce stream (see PeerSynced)
+func (c *Conn) IsSynced() (synced bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.synced
+}
+
+// PeerSynced returns true if a peer with given address
+// has sent its initial announce stream. Right after a
+// connection established, a peer sends last Root
+// objects of feeds the connection is subscribed to
+// and the SyncDone message. The PeerSynced returns
+// false if there is not a connection to the peer
+func (n *Node) PeerSynced(addr net.Addr) (synced bool) {
+
+	var address = addr.String()
+
+	for _, c := range n.Connections() {
+		if c.Address() == address {
+			return c.IsSynced()
+		}
+	}
+
+	return
+}
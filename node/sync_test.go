@@ -0,0 +1,228 @@
+package node
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_PeerSynced(t *testing.T) {
+
+	var sn, err = NewNode(getTestConfig("server"))
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(getTestConfigNotListen("client"))
+	assertNil(t, err)
+	defer cn.Close()
+
+	var addr net.Addr
+	addr, err = net.ResolveTCPAddr("tcp", sn.TCP().Address())
+	assertNil(t, err)
+
+	assertTrue(t, cn.PeerSynced(addr) == false, "synced without connection")
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	var tm = time.Now().Add(TM)
+
+	for cn.PeerSynced(addr) == false {
+		if time.Now().After(tm) == true {
+			t.Fatal("not synced")
+		}
+		time.Sleep(TM / 50)
+	}
+
+	assertTrue(t, c.IsSynced() == true, "connection is not synced")
+
+}
+
+func TestNode_PeerSynced_announceRate(t *testing.T) {
+
+	const (
+		rate  = 2
+		feeds = 4 // two of them are paced
+	)
+
+	var (
+		sconf = getTestConfig("server")
+		cconf = getTestConfigNotListen("client")
+
+		received = make(chan *registry.Root, feeds)
+
+		pks = make([]cipher.PubKey, 0, feeds)
+		sks = make([]cipher.SecKey, 0, feeds)
+	)
+
+	for i := 0; i < feeds; i++ {
+		var pk, sk = cipher.GenerateKeyPair()
+		pks, sks = append(pks, pk), append(sks, sk)
+	}
+
+	sconf.AnnounceRate = rate
+
+	// the server subscribes the connection to the
+	// feeds before the initial announce
+	sconf.OnConnect = func(c *Conn) (err error) {
+		for _, pk := range pks {
+			if err = c.Subscribe(pk); err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	cconf.OnRootReceived = func(_ *Conn, r *registry.Root) (_ error) {
+		received <- r
+		return ErrUnsubscribe // don't fill
+	}
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var cn *Node
+	cn, err = NewNode(cconf)
+	assertNil(t, err)
+	defer cn.Close()
+
+	for i, pk := range pks {
+
+		assertNil(t, sn.Share(pk))
+		assertNil(t, cn.Share(pk))
+
+		var up *skyobject.Unpack
+		up, err = sn.Container().Unpack(sks[i], getTestRegistry())
+		assertNil(t, err)
+
+		var r = new(registry.Root)
+		r.Pub, r.Nonce = pk, 1
+
+		assertNil(t, sn.Container().Save(up, r))
+		assertNil(t, up.Close())
+
+	}
+
+	var addr net.Addr
+	addr, err = net.ResolveTCPAddr("tcp", sn.TCP().Address())
+	assertNil(t, err)
+
+	_, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	var tm = time.Now().Add(8 * TM)
+
+	for cn.PeerSynced(addr) == false {
+		if time.Now().After(tm) == true {
+			t.Fatal("not synced")
+		}
+		time.Sleep(TM / 50)
+	}
+
+	// all Root objects have been received before the SyncDone,
+	// the OnRootReceived is called a bit later
+
+	time.Sleep(TM / 5)
+
+	assertTrue(t, len(received) == feeds,
+		fmt.Sprint("synced before all Root objects: ", len(received)))
+
+}
+
+func TestConfig_AnnounceOnConnect(t *testing.T) {
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	var sconf = getTestConfig("server")
+	sconf.AnnounceOnConnect = false
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var (
+		rr, onRootReceived = onRootReceivedToChannel(1)
+		cconf              = getTestConfigNotListen("client")
+	)
+
+	cconf.OnRootReceived = onRootReceived
+
+	var cn *Node
+	cn, err = NewNode(cconf)
+	assertNil(t, err)
+	defer cn.Close()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, cn.Share(pk))
+
+	var up *skyobject.Unpack
+	up, err = sn.Container().Unpack(sk, getTestRegistry())
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Nonce = 9021
+	r.Pub = pk
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Alice", 19, nil}))
+
+	assertNil(t, sn.Container().Save(up, r))
+
+	var c *Conn
+	c, err = cn.TCP().Connect(sn.TCP().Address())
+	assertNil(t, err)
+
+	assertNil(t, c.Subscribe(pk))
+
+	var tm = time.Now().Add(TM)
+
+	for c.IsSynced() == false {
+		if time.Now().After(tm) == true {
+			t.Fatal("not synced")
+		}
+		time.Sleep(TM / 50)
+	}
+
+	select {
+	case <-rr:
+		t.Fatal("Root announced on connect or subscription")
+	case <-time.After(TM):
+	}
+
+	// explicit request
+
+	var preview *registry.Root
+	err = c.Preview(pk, func(_ registry.Pack, r *registry.Root) (_ bool) {
+		preview = r
+		return
+	})
+	assertNil(t, err)
+
+	assertTrue(t, preview != nil, "missing Root")
+	assertTrue(t, preview.Hash == r.Hash, "wrong Root received")
+
+	// Root objects published later are sent
+
+	r.Refs = append(r.Refs,
+		dynamicByValue(t, up, "test.User", User{"Bob", 21, nil}))
+
+	assertNil(t, sn.Container().Save(up, r))
+	sn.Publish(r)
+
+	select {
+	case rc := <-rr:
+		assertTrue(t, rc.Hash == r.Hash, "wrong Root received")
+	case <-time.After(TM):
+		t.Fatal("slow or missing Root")
+	}
+
+}
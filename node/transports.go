@@ -184,24 +184,17 @@ func (t *TCP) findServiceNodes(resp *discovery.QueryResp) {
 			continue // sometimes it happens, TODO (kostyarin): ask about it
 		}
 
-		for _, ni := range si.Nodes {
+		// the same node can have many addresses
 
-			if ni == nil {
-				continue // never happens
-			}
+		for _, pa := range groupByPeer(si.Nodes) {
+
+			var c, err = t.ConnectToPeer(pa.id, pa.addresses...) // block
 
-			var (
-				c, yep = t.n.hasPeer(ni.PubKey)
-				err    error
-			)
-
-			if yep == false {
-				if c, err = t.Connect(ni.Address); err != nil { // block
-					t.n.Debugf(DiscoveryPin, "can't Connect to tcp://%q: %v",
-						ni.Address,
-						err)
-					continue
-				}
+			if err != nil {
+				t.n.Debugf(DiscoveryPin, "can't Connect to tcp://%q: %v",
+					pa.addresses,
+					err)
+				continue
 			}
 
 			// block
@@ -462,24 +455,17 @@ func (u *UDP) findServiceNodes(resp *discovery.QueryResp) {
 			continue // sometimes it happens, TODO (kostyarin): ask about it
 		}
 
-		for _, ni := range si.Nodes {
+		// the same node can have many addresses
 
-			if ni == nil {
-				continue // never happens
-			}
+		for _, pa := range groupByPeer(si.Nodes) {
+
+			var c, err = u.ConnectToPeer(pa.id, pa.addresses...) // block
 
-			var (
-				c, yep = u.n.hasPeer(ni.PubKey)
-				err    error
-			)
-
-			if yep == false {
-				if c, err = u.Connect(ni.Address); err != nil { // block
-					u.n.Debugf(DiscoveryPin, "can't Connect to udp://%q: %v",
-						ni.Address,
-						err)
-					continue
-				}
+			if err != nil {
+				u.n.Debugf(DiscoveryPin, "can't Connect to udp://%q: %v",
+					pa.addresses,
+					err)
+				continue
 			}
 
 			// block
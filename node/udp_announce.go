@@ -0,0 +1,357 @@
+package node
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// max Root objects per datagram; a datagram
+// with 16 Root objects is about 1.3K
+const udpAnnounceRoots int = 16
+
+// max pending pulls; announces received when the
+// queue is full are dropped
+const udpAnnouncePulls int = 16
+
+// An udpAnnounce is datagram the Node sends
+// periodically to UDPAnnounceAddr. The datagram
+// contains last Root objects of active heads of
+// feeds the Node shares and TCP port to pull them
+type udpAnnounce struct {
+	ID    cipher.PubKey     // node id, to skip own announces
+	Port  uint16            // TCP listening port
+	Roots []udpAnnounceRoot // last Root objects
+}
+
+type udpAnnounceRoot struct {
+	Feed  cipher.PubKey
+	Nonce uint64
+	Seq   uint64
+	Hash  cipher.SHA256
+}
+
+// a pull of announced Root objects
+type udpPull struct {
+	address string          // TCP address of the announcer
+	id      cipher.PubKey   // announced id of the announcer
+	feeds   []cipher.PubKey // feeds to subscribe to
+}
+
+// UDP announce gossip of the Node
+type udpGossip struct {
+	to    *net.UDPAddr  // send to
+	send  *net.UDPConn  // nil if announcing disabled
+	recv  *net.UDPConn  // nil if receiving disabled
+	pullq chan *udpPull // pull announced Root objects
+
+	await  sync.WaitGroup
+	closeo sync.Once
+}
+
+func (n *Node) initUDPAnnounce() (err error) {
+
+	var g = &n.gossip
+
+	if n.config.UDPAnnounceListen != "" {
+
+		var addr *net.UDPAddr
+		addr, err = net.ResolveUDPAddr("udp", n.config.UDPAnnounceListen)
+
+		if err != nil {
+			return
+		}
+
+		if addr.IP.IsMulticast() == true {
+			g.recv, err = net.ListenMulticastUDP("udp", nil, addr)
+		} else {
+			g.recv, err = net.ListenUDP("udp", addr)
+		}
+
+		if err != nil {
+			return
+		}
+
+		g.pullq = make(chan *udpPull, udpAnnouncePulls)
+
+		g.await.Add(2)
+		go n.receiveUDPAnnounces()
+		go n.pullUDPAnnounces()
+
+	}
+
+	if n.config.UDPAnnounceAddr != "" {
+
+		g.to, err = net.ResolveUDPAddr("udp", n.config.UDPAnnounceAddr)
+
+		if err != nil {
+			return
+		}
+
+		if g.send, err = net.ListenUDP("udp", nil); err != nil {
+			return
+		}
+
+		g.await.Add(1)
+		go n.sendUDPAnnounces(n.config.UDPAnnounceInterval)
+
+	}
+
+	return
+}
+
+func (g *udpGossip) close() {
+	g.closeo.Do(func() {
+
+		if g.recv != nil {
+			g.recv.Close()
+		}
+
+		if g.send != nil {
+			g.send.Close()
+		}
+
+		g.await.Wait()
+
+	})
+}
+
+// TCP listening port of the Node or
+// zero if the Node doesn't listen
+func (n *Node) tcpPort() (port uint16) {
+
+	var t = n.getTCP()
+
+	if t == nil {
+		return
+	}
+
+	var _, sp, err = net.SplitHostPort(t.Address())
+
+	if err != nil {
+		return
+	}
+
+	var p uint64
+	if p, err = strconv.ParseUint(sp, 10, 16); err != nil {
+		return
+	}
+
+	return uint16(p)
+}
+
+func (n *Node) sendUDPAnnounces(interval time.Duration) {
+
+	defer n.gossip.await.Done()
+
+	var tk = time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			if err := n.sendUDPAnnounce(); err != nil {
+				n.Debugln(DiscoveryPin, "sending UDP announce:", err)
+			}
+		case <-n.closeq:
+			return
+		}
+	}
+
+}
+
+// send last Root objects of active heads
+func (n *Node) sendUDPAnnounce() (err error) {
+
+	var ua = udpAnnounce{ID: n.idpk}
+
+	if ua.Port = n.tcpPort(); ua.Port == 0 {
+		return // can't be pulled
+	}
+
+	for _, pk := range n.Feeds() {
+
+		var r, lerr = n.c.LastRoot(pk, n.c.ActiveHead(pk))
+
+		if lerr != nil {
+			continue // no Root objects
+		}
+
+		ua.Roots = append(ua.Roots, udpAnnounceRoot{
+			Feed:  r.Pub,
+			Nonce: r.Nonce,
+			Seq:   r.Seq,
+			Hash:  r.Hash,
+		})
+
+	}
+
+	var roots = ua.Roots
+
+	for len(roots) > 0 {
+
+		if len(roots) > udpAnnounceRoots {
+			ua.Roots, roots = roots[:udpAnnounceRoots], roots[udpAnnounceRoots:]
+		} else {
+			ua.Roots, roots = roots, nil
+		}
+
+		_, err = n.gossip.send.WriteToUDP(encoder.Serialize(&ua), n.gossip.to)
+
+		if err != nil {
+			return
+		}
+
+	}
+
+	return
+}
+
+func (n *Node) receiveUDPAnnounces() {
+
+	defer n.gossip.await.Done()
+
+	var buf = make([]byte, 64*1024)
+
+	for {
+
+		var ln, from, err = n.gossip.recv.ReadFromUDP(buf)
+
+		if err != nil {
+
+			select {
+			case <-n.closeq:
+				return
+			default:
+			}
+
+			n.Debugln(DiscoveryPin, "receiving UDP announce:", err)
+			continue
+
+		}
+
+		var ua udpAnnounce
+
+		if err = encoder.DeserializeRaw(buf[:ln], &ua); err != nil {
+			n.Debugln(DiscoveryPin, "invalid UDP announce from", from, err)
+			continue
+		}
+
+		if ua.ID == n.idpk {
+			continue // own
+		}
+
+		n.handleUDPAnnounce(from, &ua)
+
+	}
+
+}
+
+// handle announced Root objects the Node doesn't
+// have; the handleUDPAnnounce checks address of the
+// announcer and queues the pull, since connecting
+// blocks (see pullUDPAnnounces)
+func (n *Node) handleUDPAnnounce(from *net.UDPAddr, ua *udpAnnounce) {
+
+	var feeds []cipher.PubKey
+
+	for _, ar := range ua.Roots {
+
+		if n.IsSharing(ar.Feed) == false {
+			continue // not interested
+		}
+
+		var seq, err = n.c.LastRootSeq(ar.Feed, ar.Nonce)
+
+		if err == nil && seq >= ar.Seq {
+			continue // already have
+		}
+
+		feeds = append(feeds, ar.Feed)
+
+	}
+
+	if len(feeds) == 0 || ua.Port == 0 {
+		return
+	}
+
+	var address = net.JoinHostPort(from.IP.String(),
+		strconv.Itoa(int(ua.Port)))
+
+	if err := n.checkAddress(address); err != nil {
+		n.Debugln(DiscoveryPin, "announcer", address, "is not allowed")
+		return
+	}
+
+	select {
+	case n.gossip.pullq <- &udpPull{address: address, id: ua.ID, feeds: feeds}:
+	default:
+		n.Debugln(DiscoveryPin, "too many announces, drop from", address)
+	}
+
+}
+
+func (n *Node) pullUDPAnnounces() {
+
+	defer n.gossip.await.Done()
+
+	for {
+		select {
+		case up := <-n.gossip.pullq:
+			n.pullUDPAnnounce(up)
+		case <-n.closeq:
+			return
+		}
+	}
+
+}
+
+// pull announced Root objects using TCP
+// connection to the announcer; the announcer
+// must have announced id
+func (n *Node) pullUDPAnnounce(up *udpPull) {
+
+	var c, ok = n.hasPeer(up.id)
+
+	if ok == false {
+
+		var (
+			t       = n.TCP()
+			existed = t.getConn(up.address) != nil
+			err     error
+		)
+
+		if c, err = t.Connect(up.address); err != nil {
+			n.Debugln(DiscoveryPin, "connecting to announcer", up.address, err)
+			return
+		}
+
+		if c.PeerID() != up.id {
+			n.Debugln(DiscoveryPin, "announcer", up.address,
+				"has another id")
+			if existed == false {
+				c.Close() // created for the announce
+			}
+			return
+		}
+
+	}
+
+	for _, pk := range up.feeds {
+
+		if n.fs.hasConnFeed(c, pk) == true {
+			continue // the peer pushes new Root objects itself
+		}
+
+		if err := c.Subscribe(pk); err != nil {
+			n.Debugf(DiscoveryPin, "[%s] subscribing to announced %s: %v",
+				c.String(), pk.Hex()[:7], err)
+		}
+
+	}
+
+}
@@ -0,0 +1,140 @@
+package node
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNode_UDPAnnounce(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("sender")
+		rconf = getTestConfigNotListen("receiver")
+
+		filled = make(chan *registry.Root, 1)
+	)
+
+	rconf.UDPAnnounceListen = "127.0.0.1:0" // any free port
+	rconf.OnRootFilled = func(_ *Node, r *registry.Root) {
+		filled <- r
+	}
+
+	var rn, err = NewNode(rconf)
+	assertNil(t, err)
+	defer rn.Close()
+
+	sconf.UDPAnnounceAddr = rn.gossip.recv.LocalAddr().String()
+	sconf.UDPAnnounceInterval = TM / 10
+
+	var sn *Node
+	sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+
+	assertNil(t, sn.Share(pk))
+	assertNil(t, rn.Share(pk)) // interested in the feed
+
+	// create Root
+
+	var (
+		sc  = sn.Container()
+		reg = getTestRegistry()
+	)
+
+	var up, uerr = sc.Unpack(sk, reg)
+	assertNil(t, uerr)
+
+	var r = new(registry.Root)
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		dynamicByValue(t, up, "test.User", User{"Alice", 21, nil}),
+	}
+
+	assertNil(t, sc.Save(up, r))
+	assertNil(t, up.Close())
+
+	// no TCP connections, the receiver pulls the Root
+	// using TCP after an UDP announce
+
+	assertTrue(t, len(rn.Connections()) == 0, "unexpected connections")
+
+	select {
+	case fr := <-filled:
+		assertTrue(t, fr.Hash == r.Hash, "wrong Root filled")
+	case <-time.After(5 * TM):
+		t.Fatal("slow or announce is not received")
+	}
+
+	var cs = rn.Connections()
+
+	assertTrue(t, len(cs) == 1, "missing TCP connection")
+	assertTrue(t, cs[0].IsTCP() == true, "not a TCP connection")
+
+}
+
+func TestNode_handleUDPAnnounce(t *testing.T) {
+
+	var (
+		sconf = getTestConfig("sender")
+		rconf = getTestConfigNotListen("receiver")
+
+		pk, _ = cipher.GenerateKeyPair()
+	)
+
+	rconf.UDPAnnounceListen = "127.0.0.1:0"
+
+	var sn, err = NewNode(sconf)
+	assertNil(t, err)
+	defer sn.Close()
+
+	var rn *Node
+	rn, err = NewNode(rconf)
+	assertNil(t, err)
+	defer rn.Close()
+
+	assertNil(t, rn.Share(pk))
+
+	var (
+		from = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+		ua   = &udpAnnounce{
+			ID:    sn.ID(),
+			Port:  sn.tcpPort(),
+			Roots: []udpAnnounceRoot{{Feed: pk, Nonce: 1, Seq: 1}},
+		}
+	)
+
+	t.Run("deny", func(t *testing.T) {
+
+		rn.deny, _ = parseAddressList(Addresses{"127.0.0.0/8"})
+		defer func() { rn.deny = nil }()
+
+		rn.handleUDPAnnounce(from, ua)
+		assertTrue(t, len(rn.gossip.pullq) == 0, "pull queued")
+
+	})
+
+	t.Run("wrong id", func(t *testing.T) {
+
+		var id, _ = cipher.GenerateKeyPair()
+
+		rn.pullUDPAnnounce(&udpPull{
+			address: net.JoinHostPort("127.0.0.1",
+				strconv.Itoa(int(ua.Port))),
+			id:    id,
+			feeds: []cipher.PubKey{pk},
+		})
+
+		assertTrue(t, len(rn.Connections()) == 0, "connection is not closed")
+
+	})
+
+}
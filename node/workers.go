@@ -0,0 +1,62 @@
+package node
+
+import (
+	"github.com/skycoin/cxo/node/msg"
+)
+
+// an rqObject is object request of a remote
+// peer waiting for a worker (see RequestWorkers)
+type rqObject struct {
+	c   *Conn         // the peer
+	seq uint32        // seq of the request
+	rq  *msg.RqObject // the request
+}
+
+// startRequestWorkers starts RequestWorkers
+// workers serving object requests of peers
+func (n *Node) startRequestWorkers() {
+
+	n.rqq = make(chan rqObject, n.config.RequestQueue)
+
+	for i := 0; i < n.config.RequestWorkers; i++ {
+		n.await.Add(1)
+		go n.requestWorker()
+	}
+
+}
+
+// requestWorker serves object requests
+// of peers until the Node closed
+func (n *Node) requestWorker() {
+	defer n.await.Done()
+
+	for {
+		select {
+		case ro := <-n.rqq:
+			ro.c.handleRqObject(ro.seq, ro.rq)
+		case <-n.closeq:
+			return
+		}
+	}
+
+}
+
+// queueRqObject puts given request to the queue
+// of the workers; it returns false if the queue
+// is full and all workers are busy
+func (n *Node) queueRqObject(
+	c *Conn, //            : the peer
+	seq uint32, //         : seq of the request
+	rq *msg.RqObject, //   : the request
+) (
+	ok bool, //            : queued
+) {
+
+	select {
+	case n.rqq <- rqObject{c, seq, rq}:
+		return true
+	default:
+		return false
+	}
+
+}
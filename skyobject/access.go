@@ -0,0 +1,144 @@
+package skyobject
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// last access time of objects (under lock of the Cache)
+type access struct {
+	enable bool // tracking enabled
+	limit  int  // max number of access times
+	timeline
+}
+
+func (a *access) init(enable bool, limit int) {
+	a.enable, a.limit = enable, limit
+	if enable == true {
+		a.timeline.init()
+	}
+}
+
+func (a *access) touch(key cipher.SHA256) {
+
+	if a.enable == false {
+		return
+	}
+
+	a.timeline.touch(key, a.limit)
+}
+
+// del access time of removed object
+func (a *access) del(key cipher.SHA256) {
+
+	if a.enable == false {
+		return
+	}
+
+	a.timeline.del(key)
+}
+
+// min-heap of access times (see HotObjects)
+type accessHeap []timelineItem
+
+func (a accessHeap) Len() int            { return len(a) }
+func (a accessHeap) Less(i, j int) bool  { return a[i].ts < a[j].ts }
+func (a accessHeap) Swap(i, j int)       { a[i], a[j] = a[j], a[i] }
+func (a *accessHeap) Push(x interface{}) { *a = append(*a, x.(timelineItem)) }
+
+func (a *accessHeap) Pop() (x interface{}) {
+	var old = *a
+	x, *a = old[len(old)-1], old[:len(old)-1]
+	return
+}
+
+// TrackAccess turns tracking of last access time of
+// objects on or off. Turning the tracking off, the
+// TrackAccess drops all collected timestamps. See also
+// TrackAccess field of the Config and the HotObjects
+// method
+func (c *Cache) TrackAccess(on bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.access.enable == on {
+		return
+	}
+
+	c.access.init(on, c.c.conf.MaxAccess)
+
+	if on == false {
+		c.access.ts, c.access.order = nil, nil // GC
+	}
+}
+
+// IsTrackingAccess returns true if the
+// Cache tracks last access time of objects
+func (c *Cache) IsTrackingAccess() (yep bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.access.enable
+}
+
+// LastAccess returns last access time of object with
+// given key. It returns false if the object has not
+// been accessed since tracking started
+func (c *Cache) LastAccess(key cipher.SHA256) (t time.Time, ok bool) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	var ts int64
+	if ts, ok = c.access.ts[key]; ok == true {
+		t = time.Unix(0, ts)
+	}
+	return
+}
+
+// HotObjects returns up to n hashes of most recently
+// accessed objects, starting from the most recent one.
+// An object is accessed if it has been got or set.
+// The HotObjects returns nil if the tracking is turned
+// off (see TrackAccess). Removed objects (see DelObject)
+// are not returned. Access times of least recently
+// accessed objects are dropped if there are more then
+// MaxAccess (see Config)
+func (c *Cache) HotObjects(n int) (hot []cipher.SHA256) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if n <= 0 || len(c.access.ts) == 0 {
+		return
+	}
+
+	if n > len(c.access.ts) {
+		n = len(c.access.ts)
+	}
+
+	// keep n most recent in the min-heap
+
+	var ah = make(accessHeap, 0, n)
+
+	for key, ts := range c.access.ts {
+		if len(ah) < n {
+			heap.Push(&ah, timelineItem{key, ts})
+		} else if ts > ah[0].ts {
+			ah[0] = timelineItem{key, ts}
+			heap.Fix(&ah, 0)
+		}
+	}
+
+	hot = make([]cipher.SHA256, len(ah))
+
+	for i := len(hot) - 1; i >= 0; i-- {
+		hot[i] = heap.Pop(&ah).(timelineItem).key
+	}
+
+	return
+}
@@ -0,0 +1,116 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestCache_HotObjects(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		keys []cipher.SHA256
+		err  error
+	)
+
+	for _, val := range []string{"one", "two", "three", "four"} {
+		var key = cipher.SumSHA256([]byte(val))
+		_, err = c.Set(key, []byte(val), 1)
+		assertNil(t, err)
+		keys = append(keys, key)
+	}
+
+	// disabled by default
+
+	assertTrue(t, c.IsTrackingAccess() == false, "tracking enabled")
+	assertTrue(t, len(c.HotObjects(10)) == 0, "tracking disabled")
+
+	c.TrackAccess(true)
+	assertTrue(t, c.IsTrackingAccess() == true, "tracking disabled")
+
+	// access: two, four, one, two
+
+	for _, i := range []int{1, 3, 0, 1} {
+		_, _, err = c.Get(keys[i], 0)
+		assertNil(t, err)
+	}
+
+	var hot = c.HotObjects(10)
+
+	assertTrue(t, len(hot) == 3, "wrong length")
+	assertTrue(t, hot[0] == keys[1], "wrong order")
+	assertTrue(t, hot[1] == keys[0], "wrong order")
+	assertTrue(t, hot[2] == keys[3], "wrong order")
+
+	hot = c.HotObjects(2)
+
+	assertTrue(t, len(hot) == 2, "wrong length")
+	assertTrue(t, hot[0] == keys[1] && hot[1] == keys[0], "wrong order")
+
+	var _, ok = c.LastAccess(keys[2])
+	assertTrue(t, ok == false, "not accessed object has access time")
+
+	// turn off
+
+	c.TrackAccess(false)
+
+	_, _, err = c.Get(keys[2], 0)
+	assertNil(t, err)
+
+	assertTrue(t, len(c.HotObjects(10)) == 0, "tracking disabled")
+
+	// config
+
+	var conf = getTestConfig()
+	conf.TrackAccess = true
+
+	var tc *Container
+	tc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer tc.Close()
+
+	assertTrue(t, tc.IsTrackingAccess() == true, "tracking disabled")
+
+}
+
+func TestCache_HotObjects_limit(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.TrackAccess = true
+	conf.MaxAccess = 2
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var keys []cipher.SHA256
+
+	for _, val := range []string{"one", "two", "three"} {
+		var key = cipher.SumSHA256([]byte(val))
+		_, err = c.Set(key, []byte(val), 1)
+		assertNil(t, err)
+		keys = append(keys, key)
+	}
+
+	var hot = c.HotObjects(10)
+
+	assertTrue(t, len(hot) == 2, "wrong length")
+	assertTrue(t, hot[0] == keys[2] && hot[1] == keys[1], "wrong order")
+
+	var _, ok = c.LastAccess(keys[0])
+	assertTrue(t, ok == false, "access time is not dropped")
+
+	// removed
+
+	_, err = c.Inc(keys[2], -1)
+	assertNil(t, err)
+	assertNil(t, c.DelObject(keys[2]))
+
+	hot = c.HotObjects(10)
+
+	assertTrue(t, len(hot) == 1 && hot[0] == keys[1], "removed object is hot")
+
+}
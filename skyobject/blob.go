@@ -0,0 +1,109 @@
+package skyobject
+
+import (
+	"io"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// StoreBlob reads given reader until io.EOF splitting
+// the stream into chunks. Every chunk is object with
+// raw bytes (not encoded). The chunkSize must be
+// greater then zero and not greater then MaxObjectSize
+// of the Config. The StoreBlob returns Refs with
+// ordered chunks. Use OpenBlob to read the blob. The
+// Refs can be used as is, but to keep the chunks in
+// DB, the Refs should be saved as a part of a Root
+func (u *Unpack) StoreBlob(
+	r io.Reader, //         : the blob
+	chunkSize int, //       : max size of a chunk
+) (
+	refs registry.Refs, //  : the chunks
+	err error, //           : reading or saving error
+) {
+
+	if chunkSize <= 0 || chunkSize > u.c.conf.MaxObjectSize {
+		err = ErrInvalidChunkSize
+		return
+	}
+
+	var (
+		buf    = make([]byte, chunkSize)
+		hashes []cipher.SHA256
+		hash   cipher.SHA256
+		n      int
+	)
+
+	for {
+
+		n, err = io.ReadFull(r, buf)
+
+		if n > 0 {
+			var chunk = make([]byte, n)
+			copy(chunk, buf[:n])
+
+			if hash, err = u.Add(chunk); err != nil {
+				return
+			}
+			hashes = append(hashes, hash)
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if err != nil {
+			return
+		}
+
+	}
+
+	err = refs.AppendHashes(u, hashes...)
+	return
+}
+
+// OpenBlob returns reader of a blob stored by the
+// StoreBlob method of an Unpack. The reader gets chunks
+// of the blob from DB one by one, when they needed
+func (p *Pack) OpenBlob(refs *registry.Refs) (r io.Reader, err error) {
+
+	var hashes []cipher.SHA256
+
+	if hashes, err = refs.Hashes(p); err != nil {
+		return
+	}
+
+	return &blobReader{p: p, hashes: hashes}, nil
+}
+
+// sequential reader of chunks
+type blobReader struct {
+	p      *Pack
+	hashes []cipher.SHA256 // chunks left
+	chunk  []byte          // current chunk
+}
+
+// Read implements io.Reader interface
+func (b *blobReader) Read(p []byte) (n int, err error) {
+
+	for len(b.chunk) == 0 {
+
+		if len(b.hashes) == 0 {
+			return 0, io.EOF
+		}
+
+		if b.chunk, err = b.p.Get(b.hashes[0]); err != nil {
+			return
+		}
+
+		b.hashes = b.hashes[1:]
+
+	}
+
+	n = copy(p, b.chunk)
+	b.chunk = b.chunk[n:]
+
+	return
+}
@@ -0,0 +1,71 @@
+package skyobject
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestUnpack_StoreBlob(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		_, sk  = cipher.GenerateKeyPair()
+		blob   = make([]byte, 10*1024+17) // 10 full chunks and a tail
+		up     *Unpack
+		refs   registry.Refs
+		err    error
+		ln     int
+		reader io.Reader
+	)
+
+	defer c.Close()
+
+	rand.Read(blob)
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	_, err = up.StoreBlob(bytes.NewReader(blob), 0)
+	assertTrue(t, err == ErrInvalidChunkSize, "missing ErrInvalidChunkSize")
+
+	_, err = up.StoreBlob(bytes.NewReader(blob), c.Config().MaxObjectSize+1)
+	assertTrue(t, err == ErrInvalidChunkSize, "missing ErrInvalidChunkSize")
+
+	refs, err = up.StoreBlob(bytes.NewReader(blob), 1024)
+	assertNil(t, err)
+
+	ln, err = refs.Len(up)
+	assertNil(t, err)
+	assertTrue(t, ln == 11, "wrong number of chunks")
+
+	reader, err = up.OpenBlob(&refs)
+	assertNil(t, err)
+
+	var got []byte
+	got, err = ioutil.ReadAll(reader)
+	assertNil(t, err)
+
+	assertTrue(t, bytes.Equal(got, blob), "wrong blob")
+
+	// empty blob
+
+	refs, err = up.StoreBlob(bytes.NewReader(nil), 1024)
+	assertNil(t, err)
+	assertTrue(t, refs.IsEmpty() == true, "not empty")
+
+	reader, err = up.OpenBlob(&refs)
+	assertNil(t, err)
+
+	got, err = ioutil.ReadAll(reader)
+	assertNil(t, err)
+	assertTrue(t, len(got) == 0, "not empty")
+
+}
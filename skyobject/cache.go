@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -98,6 +99,8 @@ type itemRegistry struct {
 // A Cache is internal and used by Container.
 // The Cache can't be created and used outside
 type Cache struct {
+	hits, misses uint64 // Get calls (see Stat), first for alignment
+
 	mx sync.Mutex
 
 	c      *Container // back reference
@@ -114,6 +117,9 @@ type Cache struct {
 
 	stat *cxdsStat
 
+	access access // last access time of objects
+	stored stored // store time of objects
+
 	closeo sync.Once
 }
 
@@ -139,6 +145,9 @@ func (c *Container) initCache() {
 		c.conf.CacheRegistries)
 
 	c.Cache.stat = newCxdsStat(c.conf.RollAvgSamples)
+
+	c.Cache.access.init(c.conf.TrackAccess, c.conf.MaxAccess)
+	c.Cache.stored.init(c.conf.TrackStored, c.conf.MaxStored)
 }
 
 func (c *Cache) amountVolume() (a, v int) {
@@ -321,6 +330,42 @@ func (c *Cache) delete(key cipher.SHA256, it *item) (err error) {
 	return
 }
 
+// flush writes cached rc of given objects to DB
+// keeping the objects in the Cache; e.g. the flush
+// makes the rc durable (see WAL)
+func (c *Cache) flush(keys []cipher.SHA256) (err error) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, key := range keys {
+
+		var it, ok = c.is[key]
+
+		if ok == false || it.isFilling() == true {
+			continue // not cached (or nothing to flush)
+		}
+
+		var inc = it.cc - it.rc // real rc
+
+		if inc == 0 {
+			continue
+		}
+
+		_, err = c.db().Inc(key, inc)
+		c.stat.addWritingDBRequest() // write DB
+
+		if err != nil {
+			return
+		}
+
+		it.rc = it.cc // synchronized
+
+	}
+
+	return
+}
+
 // clean the Cache down to lower boundary
 func (c *Cache) cleanDown(vol int) (err error) {
 
@@ -620,9 +665,12 @@ func (c *Cache) get(
 		}
 
 		if it.isFilling() == true {
+			atomic.AddUint64(&c.misses, 1)
 			return c.getFilling(key, inc, it)
 		}
 
+		atomic.AddUint64(&c.hits, 1)
+
 		// the delete below can clean the val field
 		val = it.val
 
@@ -636,11 +684,14 @@ func (c *Cache) get(
 
 		rc = it.cc - it.fc // hard rc
 
+		c.access.touch(key)
 		return
 	}
 
 	// not found in the Cache
 
+	atomic.AddUint64(&c.misses, 1)
+
 	var urc uint32
 	val, urc, err = c.db().Get(key, inc)
 	c.stat.addDBGet(inc)
@@ -651,6 +702,8 @@ func (c *Cache) get(
 
 	rc = int(urc) // hard rc
 
+	c.access.touch(key)
+
 	err = c.putItem(key, val, rc)
 	return
 }
@@ -696,6 +749,11 @@ func (c *Cache) Get(
 	err error,
 ) {
 
+	if inc != 0 && c.c.conf.ReadOnly == true {
+		err = ErrViewOnlyTree
+		return
+	}
+
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
@@ -744,13 +802,17 @@ func (c *Cache) setWanted(
 
 	// save
 	var urc uint32
-	urc, err = c.db().Set(key, val, inc+wincs)
+	urc, err = c.setDB(key, val, inc+wincs)
 	c.stat.addWritingDBRequest()
 
 	if err != nil {
 		return // DB failure
 	}
 
+	if urc == uint32(inc+wincs) {
+		c.stored.touch(key) // new object
+	}
+
 	rc = int(urc) - (wincs + it.fc) // hard rc
 
 	// send hard rc to wanters
@@ -808,9 +870,16 @@ func (c *Cache) Set(
 		panic("invalid inc argument of Set method: " + fmt.Sprint(inc))
 	}
 
+	if c.c.conf.ReadOnly == true {
+		err = ErrViewOnlyTree
+		return
+	}
+
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
+	c.access.touch(key)
+
 	var it, ok = c.is[key]
 
 	if ok == true {
@@ -847,13 +916,17 @@ func (c *Cache) Set(
 	}
 
 	var urc uint32
-	urc, err = c.db().Set(key, val, inc)
+	urc, err = c.setDB(key, val, inc)
 	c.stat.addWritingDBRequest()
 
 	if err != nil {
 		return
 	}
 
+	if urc == uint32(inc) {
+		c.stored.touch(key) // new object
+	}
+
 	rc = int(urc)
 	err = c.putItem(key, val, rc)
 	return
@@ -970,6 +1043,11 @@ func (c *Cache) Inc(
 	err error, //         :
 ) {
 
+	if c.c.conf.ReadOnly == true {
+		err = ErrViewOnlyTree
+		return
+	}
+
 	c.mx.Lock()
 	defer c.mx.Unlock()
 
@@ -0,0 +1,391 @@
+package skyobject
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(obj interface{}) ([]byte, error) {
+	return json.Marshal(obj)
+}
+
+func (jsonCodec) Unmarshal(val []byte, obj interface{}) error {
+	return json.Unmarshal(val, obj)
+}
+
+// Registry of objects encoded by the jsonCodec
+var jsonRegistry = registry.NewRegistry(func(r *registry.Reg) {
+	r.UseCodec(jsonCodec{})
+	r.Register("test.User", User{})
+	r.Register("test.Feed", Feed{})
+	r.Register("test.Post", Post{})
+})
+
+func TestContainer_Codec(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	assertTrue(t, c.Codec() == registry.DefaultCodec, "wrong default codec")
+
+	var conf = getTestConfig()
+	conf.Codec = jsonCodec{}
+
+	var jc, err = NewContainer(conf)
+	assertNil(t, err)
+	defer jc.Close()
+
+	assertTrue(t, jc.Codec().Name() == "json", "wrong codec")
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		up     *Unpack
+		usr    = User{"Alice", 19}
+	)
+
+	assertNil(t, jc.AddFeed(pk))
+
+	up, err = jc.Unpack(sk, jsonRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, jsonRegistry, "test.User", &usr),
+	}
+
+	assertNil(t, jc.Save(up, r))
+
+	// codec-specific hash
+
+	var val, _ = json.Marshal(usr)
+
+	assertTrue(t, r.Refs[0].Hash == cipher.SumSHA256(val),
+		"hash is not codec-specific")
+	assertTrue(t, r.Refs[0].Hash != cipher.SumSHA256(encoder.Serialize(usr)),
+		"encoded by default codec")
+
+	// round-trip
+
+	var pack *Pack
+	pack, err = jc.Pack(r, nil)
+	assertNil(t, err)
+
+	var dec User
+	assertNil(t, r.Refs[0].Value(pack, &dec))
+	assertTrue(t, dec == usr, "wrong value")
+
+	// the Root is still encoded by skycoin encoder
+
+	var lr *registry.Root
+	lr, err = jc.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "wrong Root")
+
+}
+
+func TestPack_Rehash(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.Codec = jsonCodec{}
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		up     *Unpack
+		usr    = User{"Alice", 19}
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	up, err = c.Unpack(sk, jsonRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	// stored with non-canonical encoding
+
+	var (
+		raw     = []byte(`{"Name": "Alice", "Age": 19}`)
+		rawHash = cipher.SumSHA256(raw)
+
+		sch registry.Schema
+	)
+
+	assertNil(t, up.Set(rawHash, raw))
+
+	sch, err = jsonRegistry.SchemaByName("test.User")
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, jsonRegistry, "test.User", &usr), // actual
+		{Hash: rawHash, Schema: sch.Reference()},           // corrupted
+		{},                                                 // blank
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var (
+		actual = r.Refs[0].Hash
+		saved  = *r // shares the Refs
+
+		rcOf = func(key cipher.SHA256) uint32 {
+			var _, rc, err = c.DB().CXDS().Get(key, 0)
+			assertNil(t, err)
+			return rc
+		}
+	)
+
+	var changed int
+	changed, err = up.Rehash(r)
+	assertNil(t, err)
+
+	assertTrue(t, changed == 1, "wrong number of changed")
+	assertTrue(t, r.Refs[0].Hash == actual, "actual hash changed")
+	assertTrue(t, r.Refs[1].Hash == actual, "corrupted hash is not fixed")
+	assertTrue(t, r.Refs[2].IsBlank() == true, "blank changed")
+	assertTrue(t, saved.Refs[1].Hash == rawHash, "saved Root changed")
+
+	var dec User
+	assertNil(t, r.Refs[1].Value(up, &dec))
+	assertTrue(t, dec == usr, "wrong value")
+
+	// no-op
+
+	changed, err = up.Rehash(r)
+	assertNil(t, err)
+	assertTrue(t, changed == 0, "not a no-op")
+
+	// used by previous Root and twice by the new one
+
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, rcOf(actual) == 3, fmt.Sprint("wrong rc: ", rcOf(actual)))
+	assertTrue(t, rcOf(rawHash) == 1, fmt.Sprint("wrong rc: ", rcOf(rawHash)))
+
+	// not saved
+
+	var rup *Unpack
+	rup, err = c.Unpack(sk, jsonRegistry)
+	assertNil(t, err)
+
+	var rr = saved
+	changed, err = rup.Rehash(&rr)
+	assertNil(t, err)
+	assertTrue(t, changed == 1, "wrong number of changed")
+
+	rup.Close()
+	assertTrue(t, rcOf(actual) == 3, fmt.Sprint("wrong rc: ", rcOf(actual)))
+
+	// registry without types
+
+	var reg *registry.Registry
+	reg, err = c.Registry(r.Reg)
+	assertNil(t, err)
+
+	rup, err = c.Unpack(sk, reg)
+	assertNil(t, err)
+	defer rup.Close()
+
+	rr = saved
+	_, err = rup.Rehash(&rr)
+	assertTrue(t, err == registry.ErrTypeNotFound, "unexpected error")
+
+	// nil Root
+
+	_, err = rup.Rehash(nil)
+	assertTrue(t, err == ErrPackWithoutRoot, "unexpected error")
+
+}
+
+func TestContainer_CountBySchema_codec(t *testing.T) {
+
+	for _, cached := range []bool{true, false} {
+
+		t.Run(fmt.Sprint("cached registry ", cached), func(t *testing.T) {
+
+			var conf = getTestConfig()
+			conf.Codec = jsonCodec{}
+
+			if cached == false {
+				conf.CacheRegistries = 0 // decoded from DB, without types
+			}
+
+			var c, err = NewContainer(conf)
+			assertNil(t, err)
+			defer c.Close()
+
+			var pk, sk = cipher.GenerateKeyPair()
+			assertNil(t, c.AddFeed(pk))
+
+			var up *Unpack
+			up, err = c.Unpack(sk, jsonRegistry)
+			assertNil(t, err)
+			defer up.Close()
+
+			var feed Feed
+			assertNil(t, feed.Posts.AppendValues(up,
+				Post{"first", "hello"},
+				Post{"second", "world"},
+			))
+
+			var r = new(registry.Root)
+
+			r.Pub = pk
+			r.Nonce = 9021
+			r.Refs = []registry.Dynamic{
+				createDynamic(up, jsonRegistry, "test.Feed", &feed),
+			}
+
+			assertNil(t, c.Save(up, r))
+
+			var counts map[string]int
+			counts, err = c.CountBySchema()
+
+			if cached == false {
+				assertTrue(t, err == registry.ErrTypeNotFound,
+					fmt.Sprint("missing or wrong error: ", err))
+				return
+			}
+
+			assertNil(t, err)
+			assertTrue(t, counts["test.Feed"] == 1, "wrong feeds")
+			assertTrue(t, counts["test.Post"] == 2, "wrong posts")
+			assertTrue(t, counts[UnknownSchema] == 0, "wrong unknown")
+
+		})
+
+	}
+
+}
+
+func TestContainer_Codec_refs(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.Codec = jsonCodec{}
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, c.AddFeed(pk))
+
+	t.Run("different codec", func(t *testing.T) {
+		var _, err = c.Unpack(sk, testRegistry)
+		assertTrue(t, err == ErrDifferentCodec, "missing ErrDifferentCodec")
+	})
+
+	var up *Unpack
+	up, err = c.Unpack(sk, jsonRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var feed = Feed{Head: "feed", Info: "json"}
+
+	err = feed.Posts.AppendValues(up,
+		Post{"first", "hello"},
+		Post{"second", "world"},
+	)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, jsonRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, jsonRegistry)
+	assertNil(t, err)
+
+	// the Feed, the Refs and two Post
+
+	var objects int
+	err = r.Walk(pack, func(cipher.SHA256, int) (bool, error) {
+		objects++
+		return true, nil
+	})
+	assertNil(t, err)
+	assertTrue(t, objects == 4, "wrong number of objects")
+
+	// saved objects referenced by the Root
+
+	for _, post := range []Post{{"first", "hello"}, {"second", "world"}} {
+		var val, _ = json.Marshal(post)
+		var _, rc, err = c.DB().CXDS().Get(cipher.SumSHA256(val), 0)
+		assertNil(t, err)
+		assertTrue(t, rc == 1, "wrong rc of a Post")
+	}
+
+}
+
+type Tags struct {
+	Name string
+	Tags map[string]string
+}
+
+func TestPack_maps(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var (
+		refs registry.Refs
+		tags = Tags{"x", map[string]string{"a": "b", "c": "d"}}
+	)
+
+	err = refs.AppendValues(up, tags)
+
+	var mte, ok = err.(*registry.MapTypeError)
+
+	assertTrue(t, ok == true, "missing *MapTypeError")
+	assertTrue(t, mte.Type() == reflect.TypeOf(tags), "wrong type")
+	assertTrue(t, up.IsDirty() == false, "saved")
+
+	t.Run("register", func(t *testing.T) {
+
+		defer func() {
+			_, ok := recover().(*registry.MapTypeError)
+			assertTrue(t, ok == true, "missing *MapTypeError")
+		}()
+
+		registry.NewRegistry(func(r *registry.Reg) {
+			r.Register("test.Tags", Tags{})
+		})
+
+	})
+
+}
@@ -0,0 +1,64 @@
+package skyobject
+
+import (
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// CommittedBuffer is size of buffer of
+// the channel returned by the Committed
+const CommittedBuffer int = 1024
+
+// A CommittedObject is key of new object and
+// feed of the Root that refers to the object
+// (see Committed)
+type CommittedObject struct {
+	Feed cipher.PubKey // feed of the saved Root
+	Key  cipher.SHA256 // key of the new object
+}
+
+// channel of new objects (see Committed)
+type committed struct {
+	mx sync.Mutex
+	ch chan CommittedObject
+}
+
+// notify sends given object to the channel if the
+// channel has been created and its buffer is not full
+func (m *committed) notify(feed cipher.PubKey, key cipher.SHA256) {
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if m.ch == nil {
+		return
+	}
+
+	select {
+	case m.ch <- CommittedObject{feed, key}:
+	default: // drop
+	}
+
+}
+
+// Committed returns channel that receives keys of
+// new objects, every time a Save stores objects that
+// have not been stored before. Every key comes with
+// feed of the saved Root. Objects received from peers
+// (filled) are not sent to the channel. The channel
+// is created by first call and is never closed. It
+// has CommittedBuffer size and keys are dropped if
+// the buffer is full. Thus, the channel should be
+// read by one goroutine that reads it fast
+func (c *Container) Committed() <-chan CommittedObject {
+
+	c.committed.mx.Lock()
+	defer c.committed.mx.Unlock()
+
+	if c.committed.ch == nil {
+		c.committed.ch = make(chan CommittedObject, CommittedBuffer)
+	}
+
+	return c.committed.ch
+}
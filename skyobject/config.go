@@ -1,11 +1,15 @@
 package skyobject
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
 
 	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/node/log"
@@ -47,6 +51,26 @@ const (
 	VerbosePin // too many logs to show
 )
 
+// ExpiryInterval is default interval of sweeping
+// of expired objects (disabled)
+const ExpiryInterval time.Duration = 0
+
+// WAL is default file name of write-ahead log
+// (see WAL field of the Config)
+const WAL string = "wal.db"
+
+// MaxTreeDepth is default max depth of Refs trees
+// (see MaxTreeDepth field of the Config)
+const MaxTreeDepth int = 64
+
+// MaxAccess is default max number of access times
+// of objects (see MaxAccess field of the Config)
+const MaxAccess int = 256 * 1024
+
+// MaxStored is default max number of store times
+// of objects (see MaxStored field of the Config)
+const MaxStored int = 256 * 1024
+
 // internal constants
 const (
 	// default tree is
@@ -141,6 +165,26 @@ type Config struct {
 	// CacheMaxVolume*(1.0 - CacheCleaning)
 	CacheMaxItemSize int
 
+	// TrackAccess turns on tracking of last access time
+	// of objects. The timestamps kept in memory. See
+	// (*Cache).HotObjects and (*Cache).TrackAccess
+	// methods for details
+	TrackAccess bool
+	// MaxAccess is max number of access times kept if
+	// the tracking is turned on. Access times of least
+	// recently accessed objects are dropped if the limit
+	// reached. Use zero to turn the limit off
+	MaxAccess int
+	// TrackStored turns on tracking of store time of
+	// objects. The timestamps kept in memory. See
+	// (*Container).ObjectsSince method for details
+	TrackStored bool
+	// MaxStored is max number of store times kept if
+	// the TrackStored is true. Oldest store times are
+	// dropped if the limit reached. Use zero to turn
+	// the limit off
+	MaxStored int
+
 	// limits
 
 	// MaxObjectSize is max size of object the CXO can
@@ -161,6 +205,23 @@ type Config struct {
 	// The MaxObjectSize can't be less then 1024
 	MaxObjectSize int
 
+	// MaxTreeDepth is max depth of a Refs tree the CXO
+	// can load. The limit protects against crafted Refs
+	// with pathological depth, since loading a Refs is
+	// recursive. A Refs deeper then the limit can't be
+	// loaded and a *registry.TreeTooDeepError returned.
+	// Use zero to turn the limit off
+	MaxTreeDepth int
+
+	// MaxUnsaved is max number of objects an Unpack can
+	// keep unsaved, and MaxUnsavedVolume is max total size
+	// of the objects. If a limit exceeded, then methods
+	// that add objects to the Unpack return ErrUnsavedLimit
+	// and the Unpack should be saved. The Save resets
+	// the limits. Use zero to turn a limit off
+	MaxUnsaved       int
+	MaxUnsavedVolume int
+
 	// MaxFillingParallel is limit of subtrees that used
 	// by Filler at the same time. The Filler can walk
 	// all possible subtress sumultaneously, creating
@@ -202,6 +263,98 @@ type Config struct {
 	// DB is *data.DB you can provide. If the field is not nil
 	// nil, then DPPath and InMemoryDB fields ignored.
 	DB *data.DB
+
+	// ReadOnly turns the Container to read-only mode. In
+	// this mode, every Pack of the Container has ViewOnly
+	// flag, and all methods that change DB returns
+	// ErrViewOnlyTree. The mode is useful to analyze
+	// a database without changes. See also
+	// NewReadOnlyContainer
+	ReadOnly bool
+
+	// Codec used to encode and decode values of objects.
+	// The Codec affects hashes of objects, thus a DB
+	// created with a Codec must be used with the same
+	// Codec. The Container uses one Codec for all
+	// Pack instances it creates. Name of the Codec is
+	// part of a Registry, and the Container refuses
+	// Registries of another Codec (see UseCodec method
+	// of the registry.Reg). Nil means default (see
+	// registry.DefaultCodec and registry.Codec for
+	// details)
+	Codec registry.Codec
+
+	// Encrypt and Decrypt are optional hooks used to
+	// encrypt values of objects stored in DB, and to
+	// decrypt them. Hashes of objects are hashes of
+	// plain values, thus the encryption doesn't affect
+	// addressing. Both hooks should be set or both
+	// should be nil. A DB created with the hooks must
+	// be used with the same hooks. The hooks called
+	// concurrently
+	Encrypt func(val []byte) (enc []byte, err error)
+	Decrypt func(enc []byte) (val []byte, err error)
+
+	// OnMissing is optional callback called by the Get
+	// method of a Pack if an object is not found. The
+	// callback can fetch the object (e.g. from a peer).
+	// A value the callback returns is used if hash of
+	// the value is the requested one. A Pack keeps the
+	// value for its lifetime, and an Unpack stores it
+	// as unsaved object (see Get method of the Pack).
+	// The callback called concurrently
+	OnMissing func(key cipher.SHA256) (val []byte, err error)
+
+	// OnSaveRollback is optional callback called by the
+	// Save (and the CompareAndSave) if IdxDB transaction
+	// of the Save fails and rolls back. The callback
+	// called with the error before the Save returns it.
+	// It's never called if the Save succeeded, or if
+	// the CompareAndSave returns false because of hash
+	// mismatch. The callback can be used for metrics
+	// and alerts.
+	// The callback called concurrently
+	OnSaveRollback func(err error)
+
+	// MirrorIgnoreErrors turns off rolling back of writes
+	// failed to be mirrored (see Mirror method of the
+	// Container). If it's true, then errors of the mirror
+	// are logged and the Container continues
+	MirrorIgnoreErrors bool
+
+	// ExpiryInterval is interval of sweeping of expired
+	// objects. Objects saved using SaveTTL method of the
+	// Container expire after given TTL and the Container
+	// removes them if they are not used. Set it to zero
+	// to disable sweeping (use SweepExpired method to
+	// remove expired objects manually)
+	ExpiryInterval time.Duration
+
+	// FlushOnClose keeps objects of not closed Unpack
+	// instances in DB, when the Container closes. By
+	// default, the Container rejects and removes objects
+	// created by Unpack instances that was not saved
+	// printing a warning. Thus all unsaved changes will
+	// be lost. The FlushOnClose turns this behaviour off
+	// and unsaved objects will be kept in DB with zero
+	// references counter (like the (*Unpack).Close does).
+	// Thus, they can be used by next Save or removed
+	// (see DelObject). An Unpack that is not closed and
+	// is collected by GC before the Container closes,
+	// removes its objects anyway
+	FlushOnClose bool
+
+	// WAL turns on write-ahead log of the Save method.
+	// The Save writes a record to the log before the
+	// Root committed to IdxDB. If the Save interrupted
+	// (e.g. the process crashed), then the Container
+	// finishes the Save next time it opens. The WAL is
+	// used only by a database on drive. The log created
+	// under the DataDir (see WAL constant), or near the
+	// database if the DBPath is set (DBPath + ".wal").
+	// A Save with the WAL holds it, thus Save calls are
+	// serialized
+	WAL bool
 }
 
 // NewConfig returns pointer to Config with default values
@@ -220,6 +373,13 @@ func NewConfig() (conf *Config) {
 	conf.CacheMaxItemSize = CacheMaxItemSize
 
 	conf.MaxObjectSize = MaxObjectSize
+	conf.MaxTreeDepth = MaxTreeDepth
+	conf.MaxAccess = MaxAccess
+	conf.MaxStored = MaxStored
+
+	conf.ExpiryInterval = ExpiryInterval
+
+	conf.Codec = registry.DefaultCodec
 
 	// data dir
 	conf.DataDir = DataDir()
@@ -242,6 +402,14 @@ func (c *Config) FromFlags() {
 		"db-path",
 		c.DBPath,
 		"path to database")
+	flag.DurationVar(&c.ExpiryInterval,
+		"expiry-interval",
+		c.ExpiryInterval,
+		"interval of sweeping of expired objects, zero to disable")
+	flag.BoolVar(&c.WAL,
+		"wal",
+		c.WAL,
+		"use write-ahead log of saving")
 }
 
 // Validate the Config
@@ -297,5 +465,40 @@ func (c *Config) Validate() error {
 			c.MaxObjectSize)
 	}
 
+	if (c.Encrypt == nil) != (c.Decrypt == nil) {
+		return errors.New(
+			"skyobject.Config: Encrypt and Decrypt should be set both")
+	}
+
+	if c.MaxTreeDepth < 0 {
+		return fmt.Errorf("skyobject.Config.MaxTreeDepth is negative: %d",
+			c.MaxTreeDepth)
+	}
+
+	if c.MaxAccess < 0 {
+		return fmt.Errorf("skyobject.Config.MaxAccess is negative: %d",
+			c.MaxAccess)
+	}
+
+	if c.MaxStored < 0 {
+		return fmt.Errorf("skyobject.Config.MaxStored is negative: %d",
+			c.MaxStored)
+	}
+
+	if c.MaxUnsaved < 0 {
+		return fmt.Errorf("skyobject.Config.MaxUnsaved is negative: %d",
+			c.MaxUnsaved)
+	}
+
+	if c.MaxUnsavedVolume < 0 {
+		return fmt.Errorf("skyobject.Config.MaxUnsavedVolume is negative: %d",
+			c.MaxUnsavedVolume)
+	}
+
+	if c.ExpiryInterval < 0 {
+		return fmt.Errorf("skyobject.Config.ExpiryInterval is negative: %s",
+			c.ExpiryInterval)
+	}
+
 	return nil
 }
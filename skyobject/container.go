@@ -1,8 +1,10 @@
 package skyobject
 
 import (
+	"bytes"
 	"log"
 	"path/filepath"
+	"sync"
 
 	"github.com/skycoin/skycoin/src/cipher"
 
@@ -18,13 +20,25 @@ func init() {
 
 // A Container represents
 type Container struct {
-	Cache // cache of the Container
+	Cache // cache of the Container, first for alignment
 	Index // memory mapped IdxDB
 
+	unsaved int32 // objects of Unpack instances (see Stat)
+
 	db *data.DB // database
 
 	conf *Config // configurations
 
+	// live Unpack instances
+	upmx sync.Mutex
+	ups  map[*unsaved]struct{}
+
+	expiry expiry // objects saved with TTL
+	wal    wal    // write-ahead log of Save
+	mirror mirror // secondary DB (see Mirror)
+
+	committed committed // new objects (see Committed)
+
 	// human readable (used by node for debugging)
 	cxPath, idxPath string
 }
@@ -56,6 +70,8 @@ func NewContainer(conf *Config) (c *Container, err error) {
 
 	c.conf = conf // keep
 
+	c.ups = make(map[*unsaved]struct{})
+
 	if err = c.createDB(conf); err != nil {
 		return
 	}
@@ -72,6 +88,12 @@ func NewContainer(conf *Config) (c *Container, err error) {
 		return
 	}
 
+	// finish interrupted Save if any
+	c.wal.init(c)
+	if err = c.wal.replay(c); err != nil {
+		return
+	}
+
 	// initialize cache
 	c.initCache()
 
@@ -79,9 +101,29 @@ func NewContainer(conf *Config) (c *Container, err error) {
 		return
 	}
 
+	c.expiry.init(c) // start sweeping
+
 	return // done
 }
 
+// NewReadOnlyContainer creates read-only Container
+// using given DB and default configurations. See
+// ReadOnly field of the Config for details
+func NewReadOnlyContainer(db *data.DB) (c *Container, err error) {
+
+	var conf = NewConfig()
+
+	conf.DB = db
+	conf.ReadOnly = true
+
+	return NewContainer(conf)
+}
+
+// IsReadOnly returns true if the Container is read-only
+func (c *Container) IsReadOnly() (ro bool) {
+	return c.conf.ReadOnly
+}
+
 func (c *Container) createDB(conf *Config) (err error) {
 
 	if conf.DataDir != "" {
@@ -128,6 +170,14 @@ func (c *Container) createDB(conf *Config) (err error) {
 
 	}
 
+	if conf.Encrypt != nil && conf.Decrypt != nil {
+		db = data.NewDB(&cryptCXDS{
+			CXDS:    db.CXDS(),
+			encrypt: conf.Encrypt,
+			decrypt: conf.Decrypt,
+		}, db.IdxDB())
+	}
+
 	c.db = db
 
 	return
@@ -173,6 +223,10 @@ func (c *Container) getPack(reg *registry.Registry) (pack *Pack) {
 	pack.reg = reg
 	pack.c = c
 
+	if c.conf.ReadOnly == true {
+		pack.flags = registry.ViewOnly
+	}
+
 	return
 }
 
@@ -246,6 +300,54 @@ func (c *Container) checkSize() (err error) {
 
 }
 
+// OversizedObjects returns hashes of stored objects
+// larger then MaxObjectSize. Such objects can be stored
+// if the MaxObjectSize was reduced after. The objects
+// can be read, since the MaxObjectSize is checked only
+// saving or receiving objects. The OversizedObjects
+// is useful to find and handle such objects manually
+// (see also CheckSizes option)
+func (c *Container) OversizedObjects() (hashes []cipher.SHA256, err error) {
+
+	err = c.db.CXDS().Iterate(
+		func(key cipher.SHA256, _ uint32, val []byte) (_ error) {
+			if len(val) > c.conf.MaxObjectSize {
+				hashes = append(hashes, key)
+			}
+			return
+		})
+
+	return
+}
+
+// Verify checks all objects in DB. Hash of
+// every object must be equal to its key. The Verify
+// calls given function for every corrupted object
+// with ErrCorruptedObject. The function can be nil.
+// The Verify returns number of corrupted objects
+// and error of DB if any
+func (c *Container) Verify(
+	fn func(hash cipher.SHA256, err error), // :
+) (
+	corrupted int, //                          :
+	err error, //                              :
+) {
+
+	err = c.db.CXDS().Iterate(
+		func(key cipher.SHA256, _ uint32, val []byte) (_ error) {
+			if cipher.SumSHA256(val) == key {
+				return
+			}
+			corrupted++
+			if fn != nil {
+				fn(key, ErrCorruptedObject)
+			}
+			return
+		})
+
+	return
+}
+
 // DB of the Container.
 func (c *Container) DB() (db *data.DB) {
 	return c.db
@@ -301,6 +403,573 @@ func (c *Container) walkRoot(
 	return r.Walk(pack, walkFunc)
 }
 
+// RootComplete checks that every object reachable from
+// given Root exists in DB. It returns list of missing
+// objects to fetch. The list contains Registry of the
+// Root only, if the Registry is missing, because it's
+// impossible to walk the Root without the Registry. The
+// Root itself is not checked. Elements of a missing
+// object (e.g. nodes of Refs) are not reported, since
+// they are unknown until the object is received
+func (c *Container) RootComplete(
+	r *registry.Root, //             : the Root to check
+) (
+	complete bool, //                : all objects present
+	missing []cipher.SHA256, //      : keys of missing objects
+	err error, //                    : an error
+) {
+
+	var reg *registry.Registry
+
+	if reg, err = c.Registry(r.Reg); err == data.ErrNotFound {
+		return false, []cipher.SHA256{cipher.SHA256(r.Reg)}, nil
+	} else if err != nil {
+		return
+	}
+
+	var pack = c.getPack(reg)
+
+	err = r.Walk(pack,
+		func(hash cipher.SHA256, _ int) (deepper bool, err error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // blank
+			}
+
+			if _, _, err = c.Get(hash, 0); err == data.ErrNotFound {
+				missing = append(missing, hash)
+				return false, nil // skip its subtree
+			} else if err != nil {
+				return
+			}
+
+			return true, nil
+		})
+
+	if err != nil {
+		return false, nil, err
+	}
+
+	return len(missing) == 0, missing, nil
+}
+
+// call given function for every Root of given feed
+// inside a transaction of IdxDB
+func rangeFeedRoots(
+	feeds data.Feeds,
+	pk cipher.PubKey,
+	rangeFunc func(nonce uint64, dr *data.Root) error,
+) (
+	err error,
+) {
+
+	var hs data.Heads
+	if hs, err = feeds.Heads(pk); err != nil {
+		return
+	}
+
+	return hs.Iterate(func(nonce uint64) (err error) {
+
+		var roots data.Roots
+		if roots, err = hs.Roots(nonce); err != nil {
+			return
+		}
+
+		return roots.Ascend(func(dr *data.Root) error {
+			return rangeFunc(nonce, dr)
+		})
+
+	})
+}
+
+// hashes of all Root objects of IdxDB
+func (c *Container) rootHashes() (hashes []cipher.SHA256, err error) {
+
+	err = c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		return feeds.Iterate(func(pk cipher.PubKey) (err error) {
+			return rangeFeedRoots(feeds, pk,
+				func(_ uint64, dr *data.Root) (_ error) {
+					hashes = append(hashes, dr.Hash)
+					return
+				})
+		})
+	})
+
+	if err != nil {
+		hashes = nil
+	}
+
+	return
+}
+
+// OrphanRegistries returns references to Registries
+// stored in DB that are not used by any Root. E.g.
+// Registries of removed Root objects, or Registries
+// added by the AddRegistry and not used yet. The
+// OrphanRegistries decodes objects of DB to find the
+// Registries, thus it's slow. Objects of DB are
+// iterated in one read transaction
+func (c *Container) OrphanRegistries() (
+	orphans []registry.RegistryRef, // : orphaned Registries
+	err error, //                      : an error
+) {
+
+	var hashes []cipher.SHA256 // hashes of all Root objects
+
+	if hashes, err = c.rootHashes(); err != nil {
+		return
+	}
+
+	var used = make(map[cipher.SHA256]struct{}) // Roots and Registries
+
+	for _, hash := range hashes {
+
+		var r *registry.Root
+		if r, err = c.rootByHash(hash); err != nil {
+			return
+		}
+
+		used[r.Hash] = struct{}{}
+		used[cipher.SHA256(r.Reg)] = struct{}{}
+
+	}
+
+	err = c.db.CXDS().Iterate(
+		func(key cipher.SHA256, _ uint32, val []byte) (_ error) {
+
+			if _, ok := used[key]; ok == true {
+				return
+			}
+
+			var reg, err = registry.DecodeRegistry(val)
+
+			if err != nil || cipher.SHA256(reg.Reference()) != key {
+				return // not a Registry
+			}
+
+			orphans = append(orphans, reg.Reference())
+			return
+		})
+
+	if err != nil {
+		orphans = nil
+	}
+
+	return
+}
+
+// UnknownSchema is name of the bucket the CountBySchema
+// method counts objects of unknown Schema in
+const UnknownSchema string = "unknown"
+
+// CountBySchema returns number of objects in DB per
+// name of Schema. Schemas of objects are taken from
+// trees of all Root objects, using their Registries.
+// Objects that are not reachable from a Root, or that
+// are referred by Schema missing in a Registry, are
+// counted as UnknownSchema. Root objects, Registries
+// and nodes of Refs trees are not counted. Objects
+// of DB are counted in one read transaction, values
+// of the objects are not kept in memory.
+//
+// If the Codec of the Container is not the default
+// one, then objects are decoded using registered types
+// of the Registries. Thus, a Registry must be kept by
+// the Cache with its types (e.g. used by an Unpack),
+// otherwise the CountBySchema returns
+// registry.ErrTypeNotFound (see (*registry.Root).ObjectSchemas)
+func (c *Container) CountBySchema() (counts map[string]int, err error) {
+
+	var hashes []cipher.SHA256 // hashes of all Root objects
+
+	if hashes, err = c.rootHashes(); err != nil {
+		return
+	}
+
+	var (
+		schemas = make(map[cipher.SHA256]registry.Schema)
+		skip    = make(map[cipher.SHA256]struct{}) // Roots and Registries
+	)
+
+	for _, hash := range hashes {
+
+		var r *registry.Root
+		if r, err = c.rootByHash(hash); err != nil {
+			return
+		}
+
+		skip[hash] = struct{}{}
+		skip[cipher.SHA256(r.Reg)] = struct{}{}
+
+		var reg *registry.Registry
+		if reg, err = c.Registry(r.Reg); err != nil {
+			return
+		}
+
+		if err = r.ObjectSchemas(c.getPack(reg), schemas); err != nil {
+			return
+		}
+
+	}
+
+	counts = make(map[string]int)
+
+	err = c.db.CXDS().Iterate(
+		func(key cipher.SHA256, _ uint32, _ []byte) (_ error) {
+
+			if _, ok := skip[key]; ok == true {
+				return
+			}
+
+			var sch, ok = schemas[key]
+
+			switch {
+			case ok == false:
+				counts[UnknownSchema]++
+			case sch == nil:
+				// node of Refs
+			case sch.Name() != "":
+				counts[sch.Name()]++
+			default:
+				counts[sch.String()]++
+			}
+
+			return
+		})
+
+	if err != nil {
+		counts = nil
+	}
+
+	return
+}
+
+// SchemaKeyFor returns reference of Schema of given
+// object computed using current definition of type of
+// the object and names of given Types (see
+// (*registry.Types).SchemaReference). The registered
+// reply is true if a Registry of a Root of the Container
+// has Schema with the reference. Thus, if the registered
+// is false, then the type has been changed, or it has
+// never been used by stored Root objects
+func (c *Container) SchemaKeyFor(
+	types *registry.Types, // : names of types
+	obj interface{}, //       : the object
+) (
+	ref registry.SchemaRef, // : reference of current Schema
+	registered bool, //        : used by a stored Registry
+	err error, //              : an error
+) {
+
+	if ref, err = types.SchemaReference(obj); err != nil {
+		return
+	}
+
+	var hashes []cipher.SHA256 // hashes of all Root objects
+
+	if hashes, err = c.rootHashes(); err != nil {
+		return
+	}
+
+	var checked = make(map[registry.RegistryRef]struct{})
+
+	for _, hash := range hashes {
+
+		var r *registry.Root
+		if r, err = c.rootByHash(hash); err != nil {
+			return
+		}
+
+		if _, ok := checked[r.Reg]; ok == true {
+			continue
+		}
+		checked[r.Reg] = struct{}{}
+
+		var reg *registry.Registry
+		if reg, err = c.Registry(r.Reg); err != nil {
+			return
+		}
+
+		if _, err = reg.SchemaByReference(ref); err == nil {
+			return ref, true, nil
+		}
+
+	}
+
+	return ref, false, nil
+}
+
+// hashes of Root objects of given feed
+func (c *Container) feedRootHashes(
+	pk cipher.PubKey,
+) (
+	hashes []cipher.SHA256,
+	err error,
+) {
+
+	var hrs []headRoot
+	if hrs, err = c.feedRoots(pk); err != nil {
+		return
+	}
+
+	hashes = make([]cipher.SHA256, 0, len(hrs))
+	for _, hr := range hrs {
+		hashes = append(hashes, hr.dr.Hash)
+	}
+
+	return
+}
+
+// a Root of a head
+type headRoot struct {
+	nonce uint64
+	dr    data.Root
+}
+
+// Root objects of given feed
+func (c *Container) feedRoots(
+	pk cipher.PubKey,
+) (
+	hrs []headRoot,
+	err error,
+) {
+
+	err = c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+		return rangeFeedRoots(feeds, pk,
+			func(nonce uint64, dr *data.Root) (_ error) {
+				hrs = append(hrs, headRoot{nonce, *dr})
+				return
+			})
+	})
+
+	if err != nil {
+		hrs = nil
+	}
+
+	return
+}
+
+// EachRoot calls given function for every Root of the
+// Container: all feeds, all heads and all seq numbers.
+// Roots of a head are visited in ascending order. The
+// eachFunc receives feed, head and meta information of
+// a Root; use RootByHash to get the Root itself. Use
+// data.ErrStopIteration to stop the iteration. Any other
+// error returned by the eachFunc is passed through.
+//
+// The EachRoot reads Root objects of a feed in one
+// transaction and calls the eachFunc outside it, thus
+// the eachFunc can use the Container. A feed removed
+// during the iteration is skipped
+func (c *Container) EachRoot(
+	eachFunc func(pk cipher.PubKey, nonce uint64, dr *data.Root) error,
+) (
+	err error,
+) {
+
+	for _, pk := range c.Feeds() {
+
+		var hrs []headRoot
+		if hrs, err = c.feedRoots(pk); err != nil {
+			if err == data.ErrNoSuchFeed {
+				continue // removed
+			}
+			return
+		}
+
+		for i := range hrs {
+			if err = eachFunc(pk, hrs[i].nonce, &hrs[i].dr); err != nil {
+				if err == data.ErrStopIteration {
+					err = nil
+				}
+				return
+			}
+		}
+
+	}
+
+	return
+}
+
+// Amplification compares logical and physical size
+// of objects of given feed. The logical size is sum
+// of sizes of all objects reachable from all Root
+// objects of the feed, where an object is counted
+// every time it is referenced. The physical size is
+// sum of sizes of the same objects, but every object
+// is counted once. The difference is what the
+// deduplication saves. Root objects and Registries
+// are not counted. The Amplification returns
+// data.ErrNoSuchFeed if the feed doesn't exist
+func (c *Container) Amplification(
+	feed cipher.PubKey, // : the feed
+) (
+	logical int, //        : all references
+	physical int, //       : unique objects
+	err error, //          : an error
+) {
+
+	var hashes []cipher.SHA256 // Root objects of the feed
+
+	if hashes, err = c.feedRootHashes(feed); err != nil {
+		return
+	}
+
+	var sizes = make(map[cipher.SHA256]int) // unique objects
+
+	for _, hash := range hashes {
+
+		var r *registry.Root
+		if r, err = c.rootByHash(hash); err != nil {
+			return 0, 0, err
+		}
+
+		var reg *registry.Registry
+		if reg, err = c.Registry(r.Reg); err != nil {
+			return 0, 0, err
+		}
+
+		err = r.Walk(c.getPack(reg),
+			func(hash cipher.SHA256, _ int) (deepper bool, err error) {
+
+				if hash == (cipher.SHA256{}) {
+					return // blank
+				}
+
+				var size, ok = sizes[hash]
+
+				if ok == false {
+
+					var val []byte
+					if val, _, err = c.Get(hash, 0); err != nil {
+						return
+					}
+
+					size = len(val)
+					sizes[hash] = size
+					physical += size
+
+				}
+
+				logical += size
+				return true, nil // go deepper anyway
+			})
+
+		if err != nil {
+			return 0, 0, err
+		}
+
+	}
+
+	return
+}
+
+// DelObject removes object with given key from DB.
+// References counter of every object persisted in DB
+// and it is changed by Save and DelRoot (DelHead,
+// DelFeed) methods. The DelObject refuses to remove
+// an object that is used (rc > 0) and returns
+// ErrObjectIsUsed. It returns data.ErrNotFound if
+// the object doesn't exist
+func (c *Container) DelObject(key cipher.SHA256) (err error) {
+
+	if c.conf.ReadOnly == true {
+		return ErrViewOnlyTree
+	}
+
+	c.Cache.mx.Lock()
+	defer c.Cache.mx.Unlock()
+
+	// an item in the Cache has rc > 0 or it is wanted
+	// or filling; anyway it's used
+	if _, ok := c.Cache.is[key]; ok == true {
+		return ErrObjectIsUsed
+	}
+
+	var rc uint32
+	if _, rc, err = c.db.CXDS().Get(key, 0); err != nil {
+		return
+	}
+
+	if rc > 0 {
+		return ErrObjectIsUsed
+	}
+
+	if err = c.db.CXDS().Del(key); err != nil {
+		return
+	}
+
+	c.Cache.access.del(key)
+	c.Cache.stored.del(key)
+	return
+}
+
+// AddRegistry decodes given encoded Registry, that can
+// be obtained from another node, and saves it in DB if
+// it doesn't exist. Thus, Root objects that use the
+// Registry can be walked and their objects can be
+// decoded. The encoded Registry must be canonical,
+// e.g. re-encoded Registry must be the same. Otherwise
+// the AddRegistry returns ErrInvalidRegistry. A saved
+// Registry is never removed, since it is not referenced
+// by a Root. The AddRegistry returns reference to the
+// Registry
+func (c *Container) AddRegistry(
+	val []byte, //                  : encoded Registry
+) (
+	rr registry.RegistryRef, //     : reference to the Registry
+	err error, //                   : an error
+) {
+
+	if c.conf.ReadOnly == true {
+		err = ErrViewOnlyTree
+		return
+	}
+
+	var reg *registry.Registry
+
+	if reg, err = registry.DecodeRegistry(val); err != nil {
+		return
+	}
+
+	if bytes.Equal(reg.Encode(), val) == false {
+		err = ErrInvalidRegistry
+		return
+	}
+
+	rr = reg.Reference()
+
+	if _, _, err = c.Get(cipher.SHA256(rr), 0); err == data.ErrNotFound {
+		_, err = c.Set(cipher.SHA256(rr), val, 1)
+	}
+
+	if err != nil {
+		return
+	}
+
+	c.AddRegistryToCache(reg)
+	return
+}
+
+// Codec returns Codec used by the Container
+// to encode and decode values of objects
+func (c *Container) Codec() (codec registry.Codec) {
+	if codec = c.conf.Codec; codec == nil {
+		codec = registry.DefaultCodec
+	}
+	return
+}
+
+// checkCodec returns ErrDifferentCodec if values of
+// objects of given Registry encoded by another Codec
+// (see UseCodec method of the registry.Reg)
+func (c *Container) checkCodec(reg *registry.Registry) (err error) {
+	if reg.CodecName() != c.Codec().Name() {
+		return ErrDifferentCodec
+	}
+	return
+}
+
 // Config returns configs of the Container.
 // The Config must not be modified
 func (c *Container) Config() (conf *Config) {
@@ -351,9 +1020,21 @@ func (c *Container) RootByHash(
 
 // Close the Container and related DB. The DB
 // will be closed, even if the Container created
-// with user-provided DB.
+// with user-provided DB. Objects of Unpack
+// instances that are not saved will be rejected
+// or kept depending on FlushOnClose option of
+// the Config.
 func (c *Container) Close() (err error) {
 
+	c.expiry.close() // stop sweeping
+
+	// unsaved changes
+	if err = c.closeUnpacks(); err != nil {
+		c.Cache.Close() // ignore error
+		c.db.Close()    // ignore error
+		return
+	}
+
 	// the Cache.Close closes CXDS
 	if err = c.Cache.Close(); err == nil {
 		err = c.db.Close()
@@ -0,0 +1,1183 @@
+package skyobject
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idxdb"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestNewReadOnlyContainer(t *testing.T) {
+
+	var (
+		db     = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+		conf   = getTestConfig()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	conf.DB = db
+	conf.CacheMaxAmount = 0 // write to the DB directly
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var ro *Container
+	ro, err = NewReadOnlyContainer(db)
+	assertNil(t, err)
+	defer ro.Close()
+
+	assertTrue(t, ro.IsReadOnly() == true, "not read-only")
+	assertTrue(t, c.IsReadOnly() == false, "read-only")
+
+	// reads
+
+	assertTrue(t, ro.HasFeed(pk) == true, "missing feed")
+
+	var lr *registry.Root
+	lr, err = ro.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "wrong last Root")
+
+	var pack *Pack
+	pack, err = ro.Pack(lr, nil)
+	assertNil(t, err)
+
+	assertTrue(t, pack.Flags()&registry.ViewOnly != 0, "missing ViewOnly")
+	pack.ClearFlags(registry.ViewOnly)
+	assertTrue(t, pack.Flags()&registry.ViewOnly != 0, "ViewOnly cleared")
+
+	var usr User
+	assertNil(t, lr.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr.Name == "Alice", "wrong value")
+
+	var n int
+	assertNil(t, ro.Walk(lr, func(cipher.SHA256, int) (bool, error) {
+		n++
+		return true, nil
+	}))
+	assertTrue(t, n == 3, "wrong number of objects") // root, registry, user
+
+	// mutations
+
+	var assertViewOnly = func(err error) {
+		t.Helper()
+		if err != ErrViewOnlyTree {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	_, err = pack.Add([]byte("value"))
+	assertViewOnly(err)
+
+	_, err = ro.Set(cipher.SumSHA256([]byte("value")), []byte("value"), 1)
+	assertViewOnly(err)
+
+	_, err = ro.Inc(lr.Hash, 1)
+	assertViewOnly(err)
+
+	_, _, err = ro.Get(lr.Hash, -1)
+	assertViewOnly(err)
+
+	_, err = ro.Unpack(sk, testRegistry)
+	assertViewOnly(err)
+
+	assertViewOnly(ro.Save(up, r))
+
+	var pk2, _ = cipher.GenerateKeyPair()
+
+	assertViewOnly(ro.AddFeed(pk2))
+	assertViewOnly(ro.AddHead(pk, 1))
+	_, err = ro.AddRoot(lr)
+	assertViewOnly(err)
+	assertViewOnly(ro.DelRoot(pk, lr.Nonce, lr.Seq))
+	assertViewOnly(ro.DelHead(pk, lr.Nonce))
+	assertViewOnly(ro.DelFeed(pk))
+
+	// nothing changed
+
+	lr, err = ro.LastRoot(pk, r.Nonce)
+	assertNil(t, err)
+	assertTrue(t, lr.Hash == r.Hash, "Root changed")
+
+}
+
+func testContainerCloseUnpack(
+	t *testing.T, //        :
+	flushOnClose bool, //   :
+) (
+	persisted bool, //      : objects of the Unpack persisted
+) {
+
+	var dir, err = ioutil.TempDir("", "cxo-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "test")
+	conf.FlushOnClose = flushOnClose
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	var (
+		_, sk = cipher.GenerateKeyPair()
+		up    *Unpack
+	)
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var key cipher.SHA256
+	key, err = up.Add(encoder.Serialize(User{"Alice", 19}))
+	assertNil(t, err)
+
+	assertTrue(t, up.IsDirty() == true, "not dirty")
+	assertNil(t, c.Close())
+
+	// reopen
+
+	if c, err = NewContainer(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// flushed objects are kept in DB with zero rc,
+	// thus they can be collected (see DelObject)
+
+	var rc int
+	if _, rc, err = c.Get(key, 0); err == nil {
+		assertTrue(t, rc == 0, "flushed object is used")
+		assertNil(t, c.DelObject(key))
+		return true
+	} else if err != data.ErrNotFound {
+		t.Fatal(err)
+	}
+
+	return false
+}
+
+func TestContainer_Close(t *testing.T) {
+
+	t.Run("flush on close", func(t *testing.T) {
+		assertTrue(t, testContainerCloseUnpack(t, true) == true,
+			"unsaved objects are not persisted")
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		assertTrue(t, testContainerCloseUnpack(t, false) == false,
+			"unsaved objects are persisted")
+	})
+
+	t.Run("not closed Unpack", func(t *testing.T) {
+
+		var c = getTestContainer()
+		defer c.Close()
+
+		var _, sk = cipher.GenerateKeyPair()
+
+		var up, err = c.Unpack(sk, testRegistry)
+		assertNil(t, err)
+
+		var key cipher.SHA256
+		key, err = up.Add(encoder.Serialize(User{"Alice", 19}))
+		assertNil(t, err)
+
+		up = nil // lost
+
+		// the finalizer removes the object
+
+		for i := 0; i < 100; i++ {
+			if _, _, err = c.Get(key, 0); err == data.ErrNotFound {
+				break
+			}
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		assertTrue(t, err == data.ErrNotFound, "object is not removed")
+		assertTrue(t, atomic.LoadInt32(&c.unsaved) == 0, "unsaved objects")
+
+	})
+
+}
+
+func TestContainer_DelObject(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "test")
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		up     *Unpack
+		usr    = User{"Alice", 19}
+		key    = cipher.SumSHA256(encoder.Serialize(usr))
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	// two Root objects refer to the same object
+
+	for _, nonce := range []uint64{1, 2} {
+		var r = new(registry.Root)
+		r.Pub, r.Nonce = pk, nonce
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User", &usr),
+		}
+		assertNil(t, c.Save(up, r))
+	}
+
+	assertNil(t, up.Close())
+
+	assertTrue(t, c.DelObject(key) == ErrObjectIsUsed, "deleted")
+
+	assertNil(t, c.DelHead(pk, 1))
+	assertTrue(t, c.DelObject(key) == ErrObjectIsUsed, "deleted")
+
+	// restart
+
+	assertNil(t, c.Close())
+
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	assertTrue(t, c.DelObject(key) == ErrObjectIsUsed, "deleted")
+
+	assertNil(t, c.DelHead(pk, 2))
+	assertNil(t, c.DelObject(key))
+
+	_, _, err = c.Get(key, 0)
+	assertTrue(t, err == data.ErrNotFound, "not deleted")
+
+	assertTrue(t, c.DelObject(key) == data.ErrNotFound, "unexpected error")
+
+}
+
+func TestContainer_Verify(t *testing.T) {
+
+	var (
+		c       = getTestContainer()
+		alice   = encoder.Serialize(User{"Alice", 19})
+		eva     = encoder.Serialize(User{"Eva", 21})
+		akey    = cipher.SumSHA256(alice)
+		cx      = c.DB().CXDS()
+		corrupt []cipher.SHA256
+	)
+
+	defer c.Close()
+
+	var report = func(hash cipher.SHA256, err error) {
+		assertTrue(t, err == ErrCorruptedObject, "unexpected error")
+		corrupt = append(corrupt, hash)
+	}
+
+	for _, val := range [][]byte{alice, eva} {
+		var _, err = cx.Set(cipher.SumSHA256(val), val, 1)
+		assertNil(t, err)
+	}
+
+	var n, err = c.Verify(report)
+	assertNil(t, err)
+	assertTrue(t, n == 0, "unexpected corrupted objects")
+	assertTrue(t, len(corrupt) == 0, "unexpected corrupted objects")
+
+	// corrupt the Alice
+
+	assertNil(t, cx.Del(akey))
+	_, err = cx.Set(akey, append([]byte{}, eva...), 1)
+	assertNil(t, err)
+
+	n, err = c.Verify(report)
+	assertNil(t, err)
+	assertTrue(t, n == 1, "wrong number of corrupted objects")
+	assertTrue(t, len(corrupt) == 1 && corrupt[0] == akey, "wrong hash")
+
+	_, err = c.Verify(nil) // nil function
+	assertNil(t, err)
+
+}
+
+func TestContainer_CountBySchema(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		eva   = User{"Eva", 21}
+		post  = Post{Head: "head", Body: "body"}
+		other = Post{Head: "other", Body: "body"}
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	// the post is referred twice, but counted once;
+	// nodes of the Posts are not counted
+	var feed Feed
+	assertNil(t, feed.Posts.AppendValues(up, &post, &other))
+
+	// object of a schema that is not registered,
+	// the object is not referred by a Root
+	var obj = []byte("unknown object")
+	_, err = c.Set(cipher.SumSHA256(obj), obj, 1)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.User", &eva),
+		createDynamic(up, testRegistry, "test.Post", &post),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+
+	assertNil(t, c.Save(up, r))
+	assertNil(t, up.Close())
+
+	var counts map[string]int
+	counts, err = c.CountBySchema()
+	assertNil(t, err)
+
+	assertTrue(t, len(counts) == 4, fmt.Sprint("wrong counts ", counts))
+	assertTrue(t, counts["test.User"] == 2, "wrong users")
+	assertTrue(t, counts["test.Post"] == 2, "wrong posts")
+	assertTrue(t, counts["test.Feed"] == 1, "wrong feeds")
+	assertTrue(t, counts[UnknownSchema] == 1, "wrong unknown")
+
+}
+
+func TestContainer_Amplification(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+
+		big   = User{string(bytes.Repeat([]byte{'x'}, 1024)), 19}
+		small = User{"Eva", 21}
+
+		bigSize   = len(encoder.Serialize(&big))
+		smallSize = len(encoder.Serialize(&small))
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	// the big User is shared by three references
+	// of the first Root and by the second Root
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &big),
+		createDynamic(up, testRegistry, "test.User", &big),
+		createDynamic(up, testRegistry, "test.User", &small),
+		createDynamic(up, testRegistry, "test.User", &big),
+	}
+	assertNil(t, c.Save(up, r))
+
+	r.Refs = r.Refs[:1]
+	assertNil(t, c.Save(up, r))
+
+	var logical, physical int
+	logical, physical, err = c.Amplification(pk)
+	assertNil(t, err)
+
+	assertTrue(t, physical == bigSize+smallSize, "wrong physical size")
+	assertTrue(t, logical == 4*bigSize+smallSize, "wrong logical size")
+	assertTrue(t, logical-physical == 3*bigSize, "wrong difference")
+
+	var unknown, _ = cipher.GenerateKeyPair()
+	_, _, err = c.Amplification(unknown)
+	assertTrue(t, err == data.ErrNoSuchFeed, "missing or wrong error")
+
+}
+
+func TestContainer_SchemaKeyFor(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		types  = testRegistry.Types()
+
+		ref        registry.SchemaRef
+		registered bool
+		err        error
+	)
+
+	// no Root objects
+
+	ref, registered, err = c.SchemaKeyFor(types, User{})
+	assertNil(t, err)
+	assertTrue(t, registered == false, "registered without Root objects")
+
+	var sch registry.Schema
+	sch, err = testRegistry.SchemaByName("test.User")
+	assertNil(t, err)
+	assertTrue(t, ref == sch.Reference(), "wrong reference")
+
+	// save a Root with the testRegistry
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+	assertNil(t, c.Save(up, r))
+
+	_, registered, err = c.SchemaKeyFor(types, &User{})
+	assertNil(t, err)
+	assertTrue(t, registered == true, "not registered")
+
+	// changed fields
+
+	type User struct {
+		Name  string
+		Age   uint32
+		Email string // new field
+	}
+
+	var changed = registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", User{})
+	})
+
+	var cref registry.SchemaRef
+	cref, registered, err = c.SchemaKeyFor(changed.Types(), User{})
+	assertNil(t, err)
+	assertTrue(t, registered == false, "changed type is registered")
+	assertTrue(t, cref != ref, "the same reference of changed type")
+
+	// not registered type
+
+	_, _, err = c.SchemaKeyFor(types, struct{}{})
+	assertTrue(t, err == registry.ErrTypeNotFound, "missing or wrong error")
+
+}
+
+func TestContainer_EachRoot(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		pk1, sk1 = cipher.GenerateKeyPair()
+		pk2, sk2 = cipher.GenerateKeyPair()
+
+		saved = make(map[cipher.SHA256]cipher.PubKey)
+	)
+
+	var save = func(pk cipher.PubKey, sk cipher.SecKey, nonce uint64) {
+
+		var up, err = c.Unpack(sk, testRegistry)
+		assertNil(t, err)
+		defer up.Close()
+
+		var r = new(registry.Root)
+		r.Pub, r.Nonce = pk, nonce
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+		}
+		assertNil(t, c.Save(up, r))
+
+		saved[r.Hash] = pk
+	}
+
+	assertNil(t, c.AddFeed(pk1))
+	assertNil(t, c.AddFeed(pk2))
+
+	save(pk1, sk1, 1)
+	save(pk1, sk1, 1)
+	save(pk1, sk1, 2)
+	save(pk2, sk2, 1)
+
+	// all
+
+	var visited = make(map[cipher.SHA256]cipher.PubKey)
+
+	var err = c.EachRoot(
+		func(pk cipher.PubKey, nonce uint64, dr *data.Root) (_ error) {
+
+			var r, err = c.RootByHash(dr.Hash) // use the Container
+			assertNil(t, err)
+
+			assertTrue(t, r.Pub == pk, "wrong feed")
+			assertTrue(t, r.Nonce == nonce, "wrong head")
+			assertTrue(t, r.Seq == dr.Seq, "wrong seq")
+
+			visited[dr.Hash] = pk
+			return
+		})
+	assertNil(t, err)
+
+	assertTrue(t, len(visited) == len(saved), "wrong number of Roots")
+	for hash, pk := range saved {
+		assertTrue(t, visited[hash] == pk, "not visited")
+	}
+
+	// early stop
+
+	var calls int
+
+	err = c.EachRoot(
+		func(cipher.PubKey, uint64, *data.Root) (_ error) {
+			calls++
+			return data.ErrStopIteration
+		})
+	assertNil(t, err)
+	assertTrue(t, calls == 1, "not stopped")
+
+	// error
+
+	var errTest = errors.New("test error")
+
+	err = c.EachRoot(
+		func(cipher.PubKey, uint64, *data.Root) (_ error) {
+			return errTest
+		})
+	assertTrue(t, err == errTest, "missing or wrong error")
+
+}
+
+func TestContainer_Encrypt(t *testing.T) {
+
+	var xor = func(val []byte) (enc []byte, _ error) {
+		enc = make([]byte, len(val))
+		for i, b := range val {
+			enc[i] = b ^ 0x5a
+		}
+		return
+	}
+
+	var (
+		cx   = cxds.NewMemoryCXDS()
+		conf = getTestConfig()
+	)
+
+	conf.DB = data.NewDB(cx, idxdb.NewMemeoryDB())
+	conf.Encrypt, conf.Decrypt = xor, xor
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var (
+		val = []byte("secret value")
+		key = cipher.SumSHA256(val) // hash of plain value
+	)
+
+	_, err = c.Set(key, val, 1)
+	assertNil(t, err)
+
+	// on disk
+
+	var enc []byte
+	enc, _, err = cx.Get(key, 0)
+	assertNil(t, err)
+
+	var plain, _ = xor(val)
+	assertTrue(t, string(enc) == string(plain), "not encrypted")
+
+	// round-trip (the Cache disabled to get from DB)
+
+	c.Cache.enable = false
+	c.Cache.is = make(map[cipher.SHA256]*item)
+
+	var got []byte
+	got, _, err = c.Get(key, 0)
+	assertNil(t, err)
+	assertTrue(t, string(got) == string(val), "wrong value")
+
+	// mirror
+
+	var (
+		mx = cxds.NewMemoryCXDS()
+		md = data.NewDB(mx, idxdb.NewMemeoryDB())
+	)
+
+	defer md.Close()
+
+	c.Mirror(md)
+	defer c.Mirror(nil)
+
+	var (
+		mval = []byte("mirrored secret")
+		mkey = cipher.SumSHA256(mval)
+	)
+
+	_, err = c.Set(mkey, mval, 1)
+	assertNil(t, err)
+
+	enc, _, err = mx.Get(mkey, 0)
+	assertNil(t, err)
+
+	plain, _ = xor(mval)
+	assertTrue(t, string(enc) == string(plain), "mirror is not encrypted")
+
+	// hooks should be set both
+
+	conf = getTestConfig()
+	conf.Encrypt = xor
+	assertTrue(t, conf.Validate() != nil, "missing error")
+
+}
+
+func TestContainer_Mirror(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		md = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+	)
+
+	defer c.Close()
+	defer md.Close()
+
+	c.Mirror(md)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+		createDynamic(up, testRegistry, "test.User", &User{"Bob", 21}),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var amount int
+
+	err = c.DB().CXDS().Iterate(
+		func(key cipher.SHA256, _ uint32, val []byte) (_ error) {
+
+			amount++
+
+			var mval, _, err = md.CXDS().Get(key, 0)
+			assertNil(t, err)
+			assertTrue(t, string(mval) == string(val), "wrong value")
+
+			return
+		})
+	assertNil(t, err)
+
+	var all, _ = md.CXDS().Amount()
+	assertTrue(t, all == amount, fmt.Sprint("wrong amount ", all, amount))
+
+	// mirror failure, rollback
+
+	var (
+		broken = data.NewDB(cxds.NewMemoryCXDS(), idxdb.NewMemeoryDB())
+		val    = []byte("value")
+		key    = cipher.SumSHA256(val)
+	)
+
+	broken.Close() // ErrClosed
+
+	c.Mirror(broken)
+
+	_, err = c.Set(key, val, 1)
+	assertTrue(t, err == data.ErrClosed, fmt.Sprint("wrong error: ", err))
+
+	_, _, err = c.DB().CXDS().Get(key, 0)
+	assertTrue(t, err == data.ErrNotFound, "not rolled back")
+
+	// mirror failure, rollback of existing object with rc 0
+
+	var (
+		zval = []byte("zero rc")
+		zkey = cipher.SumSHA256(zval)
+	)
+
+	_, err = c.DB().CXDS().Set(zkey, zval, 1)
+	assertNil(t, err)
+	_, err = c.DB().CXDS().Inc(zkey, -1)
+	assertNil(t, err)
+
+	_, err = c.Set(zkey, zval, 1)
+	assertTrue(t, err == data.ErrClosed, fmt.Sprint("wrong error: ", err))
+
+	var zrc uint32
+	_, zrc, err = c.DB().CXDS().Get(zkey, 0)
+	assertNil(t, err)
+	assertTrue(t, zrc == 0, fmt.Sprint("wrong rc: ", zrc))
+
+	// ignore errors
+
+	c.conf.MirrorIgnoreErrors = true
+
+	_, err = c.Set(key, val, 1)
+	assertNil(t, err)
+
+	_, _, err = c.DB().CXDS().Get(key, 0)
+	assertNil(t, err)
+
+	// stop mirroring
+
+	c.Mirror(nil)
+
+}
+
+func TestContainer_Committed(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		committed = c.Committed()
+
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = encoder.Serialize(User{"Alice", 19})
+		akey  = cipher.SumSHA256(alice)
+	)
+
+	// not saved by a Save (e.g. filled)
+
+	if _, err := c.Set(akey, alice, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-committed:
+		t.Error("object is committed without a Save")
+	default:
+	}
+
+	if err := c.AddFeed(pk); err != nil {
+		t.Fatal(err)
+	}
+
+	var up, err = c.Unpack(sk, testRegistry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+		createDynamic(up, testRegistry, "test.User", &User{"Bob", 20}),
+	}
+
+	if err = c.Save(up, r); err != nil {
+		t.Fatal(err)
+	}
+
+	// the Alice already exists
+
+	select {
+	case obj := <-committed:
+		if obj.Feed != pk {
+			t.Error("wrong feed")
+		}
+		if obj.Key != r.Refs[1].Hash {
+			t.Error("wrong key")
+		}
+	default:
+		t.Fatal("missing key of new object")
+	}
+
+	select {
+	case <-committed:
+		t.Error("existing object is committed")
+	default:
+	}
+
+}
+
+func TestContainer_RootComplete(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		alice = encoder.Serialize(User{"Alice", 19})
+		eva   = encoder.Serialize(User{"Eva", 21})
+		akey  = cipher.SumSHA256(alice)
+		ekey  = cipher.SumSHA256(eva)
+
+		sch, err = testRegistry.SchemaByName("test.User")
+	)
+
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+
+	r.Reg = testRegistry.Reference()
+	r.Refs = []registry.Dynamic{
+		{Hash: akey, Schema: sch.Reference()},
+		{Hash: ekey, Schema: sch.Reference()},
+	}
+
+	var (
+		complete bool
+		missing  []cipher.SHA256
+	)
+
+	t.Run("no registry", func(t *testing.T) {
+		complete, missing, err = c.RootComplete(r)
+		assertNil(t, err)
+		assertTrue(t, complete == false, "complete")
+		assertTrue(t, len(missing) == 1 &&
+			missing[0] == cipher.SHA256(r.Reg), "wrong missing")
+	})
+
+	_, err = c.Set(cipher.SHA256(r.Reg), testRegistry.Encode(), 1)
+	assertNil(t, err)
+	_, err = c.Set(akey, alice, 1)
+	assertNil(t, err)
+
+	t.Run("missing", func(t *testing.T) {
+		complete, missing, err = c.RootComplete(r)
+		assertNil(t, err)
+		assertTrue(t, complete == false, "complete")
+		assertTrue(t, len(missing) == 1 && missing[0] == ekey,
+			"wrong missing")
+	})
+
+	_, err = c.Set(ekey, eva, 1)
+	assertNil(t, err)
+
+	t.Run("complete", func(t *testing.T) {
+		complete, missing, err = c.RootComplete(r)
+		assertNil(t, err)
+		assertTrue(t, complete == true, "not complete")
+		assertTrue(t, len(missing) == 0, "missing")
+	})
+
+}
+
+func TestContainer_AddRegistry(t *testing.T) {
+
+	var (
+		src = getTestContainer()
+		dst = getTestContainer()
+
+		pk, sk = cipher.GenerateKeyPair()
+		usr    = User{"Alice", 19}
+
+		up  *Unpack
+		val []byte
+		err error
+	)
+
+	defer src.Close()
+	defer dst.Close()
+
+	// export
+
+	assertNil(t, src.AddFeed(pk))
+
+	up, err = src.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &usr),
+	}
+	assertNil(t, src.Save(up, r))
+
+	val, _, err = src.Get(cipher.SHA256(r.Reg), 0)
+	assertNil(t, err)
+
+	var obj []byte
+	obj, _, err = src.Get(r.Refs[0].Hash, 0)
+	assertNil(t, err)
+
+	// import
+
+	var rr registry.RegistryRef
+
+	_, err = dst.AddRegistry(val[:len(val)-1])
+	assertTrue(t, err != nil, "missing error")
+
+	rr, err = dst.AddRegistry(val)
+	assertNil(t, err)
+	assertTrue(t, rr == r.Reg, "wrong reference")
+
+	_, err = dst.Set(r.Refs[0].Hash, obj, 1)
+	assertNil(t, err)
+
+	// decode
+
+	var reg *registry.Registry
+	reg, err = dst.Registry(rr)
+	assertNil(t, err)
+
+	var sch registry.Schema
+	sch, err = reg.SchemaByReference(r.Refs[0].Schema)
+	assertNil(t, err)
+	assertTrue(t, sch.Name() == "test.User", "wrong schema")
+
+	var got User
+	obj, _, err = dst.Get(r.Refs[0].Hash, 0)
+	assertNil(t, err)
+	assertNil(t, dst.Codec().Unmarshal(obj, &got))
+	assertTrue(t, got == usr, "wrong object")
+
+}
+
+func TestContainer_Stat(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		alice = encoder.Serialize(User{"Alice", 19})
+		akey  = cipher.SumSHA256(alice)
+
+		s   *Stat
+		err error
+	)
+
+	// put to DB directly, bypassing the Cache
+
+	_, err = c.db.CXDS().Set(akey, alice, 1)
+	assertNil(t, err)
+
+	_, _, err = c.Get(akey, 0) // miss
+	assertNil(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, _, err = c.Get(akey, 0) // hit
+		assertNil(t, err)
+	}
+
+	s = c.Stat()
+	assertTrue(t, s.CacheMisses == 1,
+		fmt.Sprint("wrong misses: ", s.CacheMisses))
+	assertTrue(t, s.CacheHits == 3,
+		fmt.Sprint("wrong hits: ", s.CacheHits))
+	assertTrue(t, s.AllObjects.Amount == 1, "wrong amount of objects")
+
+	// unsaved
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	_, err = up.Add(encoder.Serialize(User{"Bob", 20}))
+	assertNil(t, err)
+	_, err = up.Add(encoder.Serialize(User{"Eva", 21}))
+	assertNil(t, err)
+
+	s = c.Stat()
+	assertTrue(t, s.Unsaved == 2, fmt.Sprint("wrong unsaved: ", s.Unsaved))
+
+	assertNil(t, up.Close())
+
+	s = c.Stat()
+	assertTrue(t, s.Unsaved == 0, fmt.Sprint("wrong unsaved: ", s.Unsaved))
+
+}
+
+func TestContainer_OrphanRegistries(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+
+		other = registry.NewRegistry(func(r *registry.Reg) {
+			r.Register("test.Post", Post{})
+		})
+
+		orphans []registry.RegistryRef
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+	assertNil(t, c.Save(up, r))
+
+	orphans, err = c.OrphanRegistries()
+	assertNil(t, err)
+	assertTrue(t, len(orphans) == 0, "unexpected orphans")
+
+	// not used
+
+	_, err = c.AddRegistry(other.Encode())
+	assertNil(t, err)
+
+	orphans, err = c.OrphanRegistries()
+	assertNil(t, err)
+	assertTrue(t, len(orphans) == 1 && orphans[0] == other.Reference(),
+		"wrong orphans")
+
+	// the Root removed
+
+	assertNil(t, c.DelRoot(pk, r.Nonce, r.Seq))
+
+	orphans, err = c.OrphanRegistries()
+	assertNil(t, err)
+	assertTrue(t, len(orphans) == 2, fmt.Sprint("wrong orphans: ", orphans))
+
+}
+
+func TestContainer_MissingSeqs(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var missing []uint64
+
+	// seq 0, 1, 2, 3, 4
+
+	for i := 0; i < 5; i++ {
+		var r = &registry.Root{Pub: pk, Nonce: 1}
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User",
+				&User{"Alice", uint32(i)}),
+		}
+		assertNil(t, c.Save(up, r))
+		assertTrue(t, r.Seq == uint64(i), "unexpected seq")
+	}
+
+	missing, err = c.MissingSeqs(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, len(missing) == 0, "unexpected missing")
+
+	// seq 1, 2, 4
+
+	assertNil(t, c.DelRoot(pk, 1, 0))
+	assertNil(t, c.DelRoot(pk, 1, 3))
+
+	missing, err = c.MissingSeqs(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, len(missing) == 1 && missing[0] == 3,
+		fmt.Sprint("wrong missing: ", missing))
+
+	// no such head
+
+	_, err = c.MissingSeqs(pk, 2)
+	assertTrue(t, err == data.ErrNoSuchHead, "wrong error")
+
+}
+
+func TestContainer_OversizedObjects(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		large = make([]byte, 2048)
+		small = []byte("small")
+		lkey  = cipher.SumSHA256(large)
+
+		oversized []cipher.SHA256
+		err       error
+	)
+
+	_, err = c.Set(lkey, large, 1)
+	assertNil(t, err)
+	_, err = c.Set(cipher.SumSHA256(small), small, 1)
+	assertNil(t, err)
+
+	oversized, err = c.OversizedObjects()
+	assertNil(t, err)
+	assertTrue(t, len(oversized) == 0, "unexpected oversized")
+
+	c.conf.MaxObjectSize = 1024 // reduce
+
+	var val []byte
+	val, _, err = c.Get(lkey, 0)
+	assertNil(t, err)
+	assertTrue(t, bytes.Equal(val, large), "wrong value")
+
+	oversized, err = c.OversizedObjects()
+	assertNil(t, err)
+	assertTrue(t, len(oversized) == 1 && oversized[0] == lkey,
+		"wrong oversized")
+
+}
@@ -0,0 +1,82 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// cryptCXDS wraps a CXDS encrypting values before
+// they are stored and decrypting them after they
+// are got (see Encrypt and Decrypt fields of the
+// Config); keys are hashes of plain values
+type cryptCXDS struct {
+	data.CXDS
+
+	encrypt func([]byte) ([]byte, error)
+	decrypt func([]byte) ([]byte, error)
+}
+
+// Get and decrypt
+func (c *cryptCXDS) Get(
+	key cipher.SHA256,
+	inc int,
+) (
+	val []byte,
+	rc uint32,
+	err error,
+) {
+
+	if val, rc, err = c.CXDS.Get(key, inc); err != nil {
+		return
+	}
+
+	if val, err = c.decrypt(val); err != nil {
+		return nil, 0, err
+	}
+
+	return
+}
+
+// Set encrypted
+func (c *cryptCXDS) Set(
+	key cipher.SHA256,
+	val []byte,
+	inc int,
+) (
+	rc uint32,
+	err error,
+) {
+
+	if val, err = c.encrypt(val); err != nil {
+		return
+	}
+
+	return c.CXDS.Set(key, val, inc)
+}
+
+// Iterate over decrypted values
+func (c *cryptCXDS) Iterate(iterateFunc data.IterateObjectsFunc) (err error) {
+	return c.CXDS.Iterate(
+		func(key cipher.SHA256, rc uint32, val []byte) (err error) {
+			if val, err = c.decrypt(val); err != nil {
+				return
+			}
+			return iterateFunc(key, rc, val)
+		})
+}
+
+// IterateDel over decrypted values
+func (c *cryptCXDS) IterateDel(
+	iterateFunc data.IterateObjectsDelFunc,
+) (
+	err error,
+) {
+	return c.CXDS.IterateDel(
+		func(key cipher.SHA256, rc uint32, val []byte) (del bool, err error) {
+			if val, err = c.decrypt(val); err != nil {
+				return
+			}
+			return iterateFunc(key, rc, val)
+		})
+}
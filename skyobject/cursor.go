@@ -0,0 +1,201 @@
+package skyobject
+
+import (
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// A Cursor iterates over the Refs of the Root of a Pack
+// and allows to remove and replace elements during the
+// iteration. The Cursor keeps touched elements (see
+// Touch method of the Unpack) consistent, shifting them
+// if an element removed. Use Cursor method of a Pack or
+// an Unpack to create a Cursor. A Cursor of a Pack can't
+// replace elements. A Cursor is not thread safe
+//
+//     for c := up.Cursor(r); c.Next(); {
+//         // c.Value(&obj), c.Remove(), c.Replace(&obj)
+//     }
+//
+type Cursor struct {
+	p       *Pack
+	r       *registry.Root // the Root
+	w       registry.Pack  // writes values, nil for a Pack
+	i       int            // current element
+	removed bool           // current element has been removed
+}
+
+// Cursor returns Cursor of the Refs of the Root of
+// the Pack. The Cursor points to nothing, call Next
+// to move it to first element. If the Pack has not
+// a Root, then Next of the Cursor returns false. The
+// Pack is view only, and the Replace method of the
+// Cursor returns ErrViewOnlyTree
+func (p *Pack) Cursor() (c *Cursor) {
+	return &Cursor{p: p, r: p.r, i: -1}
+}
+
+// Cursor returns Cursor of the Refs of given Root.
+// Replaced values are saved by the Unpack, thus the
+// Root should be saved using the Unpack after. The
+// Cursor points to nothing, call Next to move it to
+// first element. If given Root is nil, then Next of
+// the Cursor returns false
+func (u *Unpack) Cursor(r *registry.Root) (c *Cursor) {
+	return &Cursor{p: u.Pack, r: r, w: u, i: -1}
+}
+
+// Next moves the Cursor to next element and returns
+// false if there are not elements anymore
+func (c *Cursor) Next() (ok bool) {
+
+	if c.r == nil {
+		return false
+	}
+
+	if c.removed == true {
+		c.removed = false // next element has current index
+	} else if c.i < len(c.r.Refs) {
+		c.i++
+	}
+
+	return c.i < len(c.r.Refs)
+}
+
+// current returns registry.ErrIndexOutOfRange if the
+// Cursor doesn't point to an element
+func (c *Cursor) current() (err error) {
+
+	if c.r == nil || c.removed == true || c.i < 0 ||
+		c.i >= len(c.r.Refs) {
+
+		return registry.ErrIndexOutOfRange
+	}
+
+	return
+}
+
+// Index of current element in the Refs of the Root.
+// It returns -1 if the Cursor doesn't point to an
+// element
+func (c *Cursor) Index() (i int) {
+
+	if c.current() != nil {
+		return -1
+	}
+
+	return c.i
+}
+
+// Dynamic returns current element. It returns
+// registry.ErrIndexOutOfRange if the Cursor doesn't
+// point to an element
+func (c *Cursor) Dynamic() (dr registry.Dynamic, err error) {
+
+	if err = c.current(); err != nil {
+		return
+	}
+
+	return c.r.Refs[c.i], nil
+}
+
+// Value decodes value of current element to given
+// object (see Value method of registry.Dynamic). It
+// returns registry.ErrIndexOutOfRange if the Cursor
+// doesn't point to an element
+func (c *Cursor) Value(obj interface{}) (err error) {
+
+	if err = c.current(); err != nil {
+		return
+	}
+
+	return c.r.Refs[c.i].Value(c.p, obj)
+}
+
+// Remove current element from the Refs of the Root.
+// After the Remove, the Cursor points to nothing and
+// the Next moves it to element after the removed one.
+// The Remove returns registry.ErrIndexOutOfRange if
+// the Cursor doesn't point to an element
+func (c *Cursor) Remove() (err error) {
+
+	if err = c.current(); err != nil {
+		return
+	}
+
+	var refs = c.r.Refs
+
+	copy(refs[c.i:], refs[c.i+1:])
+	refs[len(refs)-1] = registry.Dynamic{} // clear
+	c.r.Refs = refs[:len(refs)-1]
+
+	// shift touched elements
+
+	if len(c.p.touched) != 0 {
+
+		var touched = make(map[int]interface{}, len(c.p.touched))
+
+		for i, obj := range c.p.touched {
+			switch {
+			case i < c.i:
+				touched[i] = obj
+			case i > c.i:
+				touched[i-1] = obj
+			}
+		}
+
+		c.p.touched = touched
+	}
+
+	c.removed = true
+	return
+}
+
+// Replace value of current element with given one. The
+// object must be of registered type, and the Registry
+// of the Unpack must be created from Go types (see
+// Rehash for details). Schema of the element is replaced
+// with Schema of the object. Use nil to make the element
+// blank. The value is saved by the Unpack of the Cursor.
+// Since the value is replaced, the element is not touched
+// anymore (see Touch method of the Unpack). The Replace returns
+// ErrViewOnlyTree if the Cursor created by a Pack, and
+// registry.ErrIndexOutOfRange if the Cursor doesn't
+// point to an element
+func (c *Cursor) Replace(obj interface{}) (err error) {
+
+	if c.w == nil {
+		return ErrViewOnlyTree
+	}
+
+	if err = c.current(); err != nil {
+		return
+	}
+
+	var dr = &c.r.Refs[c.i]
+
+	if obj == nil {
+		dr.Clear()
+		delete(c.p.touched, c.i)
+		return
+	}
+
+	var name string
+	if name, err = c.reg.Types().SchemaName(obj); err != nil {
+		return
+	}
+
+	var sch registry.Schema
+	if sch, err = c.reg.SchemaByName(name); err != nil {
+		return
+	}
+
+	var nd = registry.Dynamic{Schema: sch.Reference()}
+
+	if err = nd.SetValue(c.w, obj); err != nil {
+		return
+	}
+
+	*dr = nd
+	delete(c.p.touched, c.i)
+	return
+}
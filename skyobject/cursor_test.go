@@ -0,0 +1,132 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestCursor(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	for i := 0; i < 6; i++ {
+		r.Refs = append(r.Refs, createDynamic(up, testRegistry, "test.User",
+			&User{fmt.Sprint("user-", i), uint32(i)}))
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	// touched odd elements (see Unpack.Touch)
+
+	pack.touched = make(map[int]interface{})
+
+	for _, i := range []int{1, 3, 5} {
+		pack.touched[i] = &User{"touched", uint32(i)}
+	}
+
+	// remove every other element
+
+	var cr = pack.Cursor()
+
+	assertTrue(t, cr.Index() == -1, "points to an element")
+	assertTrue(t, cr.Remove() == registry.ErrIndexOutOfRange,
+		"missing or wrong error")
+
+	for i := 0; cr.Next() == true; i++ {
+
+		var usr User
+		assertNil(t, cr.Value(&usr))
+		assertTrue(t, usr.Age == uint32(i), "wrong element")
+
+		if i%2 == 0 {
+			assertNil(t, cr.Remove())
+			assertTrue(t, cr.Index() == -1, "points to removed element")
+			assertTrue(t, cr.Remove() == registry.ErrIndexOutOfRange,
+				"missing or wrong error")
+		}
+
+	}
+
+	assertTrue(t, len(r.Refs) == 3, "wrong number of elements")
+
+	for i, dr := range r.Refs {
+		var usr User
+		assertNil(t, dr.Value(pack, &usr))
+		assertTrue(t, usr.Age == uint32(i*2+1), "wrong element")
+
+		_, ok := pack.touched[i]
+		assertTrue(t, ok == true, fmt.Sprint("element is not touched: ", i))
+	}
+
+	assertTrue(t, len(pack.touched) == 3, "wrong touched elements")
+
+	// replace
+
+	cr = pack.Cursor()
+	assertTrue(t, cr.Next() == true, "no elements")
+	assertTrue(t, cr.Replace(&Post{"head", "body"}) == ErrViewOnlyTree,
+		"missing or wrong error")
+
+	assertNil(t, up.Touch(0, &User{"touched", 0}))
+
+	cr = up.Cursor(r)
+	assertTrue(t, cr.Next() == true, "no elements")
+	assertNil(t, cr.Replace(&Post{"head", "body"}))
+
+	var post Post
+	assertNil(t, cr.Value(&post))
+	assertTrue(t, post.Head == "head", "wrong replaced value")
+
+	var name string
+	name, err = r.Refs[0].SchemaName(pack)
+	assertNil(t, err)
+	assertTrue(t, name == "test.Post", "wrong schema: "+name)
+
+	_, ok := up.touched[0]
+	assertTrue(t, ok == false, "replaced element is touched")
+
+	assertTrue(t, cr.Next() == true, "no elements")
+	assertNil(t, cr.Replace(nil))
+	assertTrue(t, r.Refs[1].IsBlank() == true, "not blank")
+
+	// the replaced value is saved once
+
+	assertNil(t, c.Save(up, r))
+
+	var (
+		key = r.Refs[0].Hash
+		rc  int
+	)
+
+	_, rc, err = c.Get(key, 0)
+	assertNil(t, err)
+	assertTrue(t, rc == 1, fmt.Sprint("wrong rc: ", rc))
+
+	assertNil(t, c.DelRoot(pk, r.Nonce, r.Seq))
+
+	if _, rc, err = c.Get(key, 0); err == nil {
+		assertTrue(t, rc == 0, fmt.Sprint("wrong rc: ", rc))
+	}
+
+}
@@ -2,6 +2,8 @@ package skyobject
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/skycoin/skycoin/src/cipher"
 )
@@ -11,6 +13,25 @@ var (
 	ErrObjectIsTooLarge = errors.New("object is too large (see MaxObjectSize)")
 	ErrTerminated       = errors.New("terminated")
 	ErrBlankRegistryRef = errors.New("blank registry reference")
+	ErrViewOnlyTree     = errors.New("view only tree")
+	ErrPackWithoutRoot  = errors.New("the Pack has not a Root")
+	ErrObjectIsUsed     = errors.New("object is used (rc > 0)")
+	ErrCorruptedObject  = errors.New("corrupted object (hash mismatch)")
+	ErrInvalidChunkSize = errors.New("invalid chunk size")
+	ErrStaleRoot        = errors.New("stale Root (there is a newer one)")
+	ErrDifferentDB      = errors.New("different Container")
+	ErrDifferentReg     = errors.New("different Registry")
+	ErrDifferentCodec   = errors.New("Registry of another Codec (see Codec)")
+	ErrStoredNotTracked = errors.New("store time is not tracked (see TrackStored)")
+	ErrStoredDropped    = errors.New("store times are dropped (see MaxStored)")
+	ErrUnsavedLimit     = errors.New("too many unsaved objects (see MaxUnsaved)")
+	ErrInvalidRegistry  = errors.New("invalid encoded Registry")
+	ErrUnfinishedSave   = errors.New("previous Save is not finished (see WAL)")
+)
+
+// internal errors
+var (
+	errRootMismatch = errors.New("last Root doesn't match")
 )
 
 // ObjectIsTooLargeError represents error that
@@ -29,3 +50,90 @@ func (o *ObjectIsTooLargeError) Hash() cipher.SHA256 {
 func (o *ObjectIsTooLargeError) Error() string {
 	return "object is too large: " + o.Hash().Hex()[:7]
 }
+
+// A MissingSchemasError occurs when the ReplaceRegistry
+// method of a Pack can't replace Registry, because
+// some Schemas of the Root of the Pack are not
+// registered in new Registry. The error contains
+// names of the Schemas
+type MissingSchemasError struct {
+	names []string
+}
+
+// Names of the missing Schemas
+func (m *MissingSchemasError) Names() []string {
+	return m.names
+}
+
+// Error implements error interface
+func (m *MissingSchemasError) Error() string {
+	return "missing schemas: " + strings.Join(m.names, ", ")
+}
+
+// An IncompatibleSchemasError occurs when the
+// ReplaceRegistry method of a Pack can't replace
+// Registry, because some Schemas used by the Root
+// of the Pack are registered in new Registry, but
+// they are not the same. The error contains names
+// of the Schemas
+type IncompatibleSchemasError struct {
+	names []string
+}
+
+// Names of the incompatible Schemas
+func (i *IncompatibleSchemasError) Names() []string {
+	return i.names
+}
+
+// Error implements error interface
+func (i *IncompatibleSchemasError) Error() string {
+	return "incompatible schemas: " + strings.Join(i.names, ", ")
+}
+
+// A ValueError represents error that occurs when
+// the Values method of a Pack can't receive or
+// decode an object. The error contains index of
+// reference of the object and the cause
+type ValueError struct {
+	index int
+	err   error
+}
+
+// Index of the reference
+func (v *ValueError) Index() int {
+	return v.index
+}
+
+// Err returns cause of the error
+func (v *ValueError) Err() error {
+	return v.err
+}
+
+// Error implements error interface
+func (v *ValueError) Error() string {
+	return fmt.Sprintf("value %d: %v", v.index, v.err)
+}
+
+// A DecodingError represents error that occurs when
+// the Validate method of a Pack can't receive or
+// decode an object. The error contains hash of the
+// object and the cause
+type DecodingError struct {
+	hash cipher.SHA256
+	err  error
+}
+
+// Hash of the object
+func (d *DecodingError) Hash() cipher.SHA256 {
+	return d.hash
+}
+
+// Err returns cause of the error
+func (d *DecodingError) Err() error {
+	return d.err
+}
+
+// Error implements error interface
+func (d *DecodingError) Error() string {
+	return fmt.Sprintf("object %s: %v", d.hash.Hex()[:7], d.err)
+}
@@ -0,0 +1,221 @@
+package skyobject
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// expiration times of objects saved with TTL
+type expiry struct {
+	mx sync.Mutex
+	db data.ExpiryDB // persistent expiration times (or nil)
+	ts memoryExpiry  // expiration times if the db is nil
+
+	closeo sync.Once
+	closeq chan struct{}
+	await  sync.WaitGroup
+}
+
+func (e *expiry) init(c *Container) {
+
+	if edb, ok := c.db.IdxDB().(data.ExpiryDB); ok == true {
+		e.db = edb
+	} else {
+		e.ts = make(memoryExpiry)
+	}
+
+	e.closeq = make(chan struct{})
+
+	if c.conf.ExpiryInterval > 0 && c.conf.ReadOnly == false {
+		e.await.Add(1)
+		go c.sweeping(c.conf.ExpiryInterval)
+	}
+}
+
+// tx performs transaction over expiration times,
+// the mx must be locked
+func (e *expiry) tx(txFunc func(ex data.Expiry) error) (err error) {
+	if e.db != nil {
+		return e.db.ExpiryTx(txFunc)
+	}
+	return txFunc(e.ts)
+}
+
+func (e *expiry) set(keys []cipher.SHA256, tp time.Time) (err error) {
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	return e.tx(func(ex data.Expiry) (err error) {
+		for _, key := range keys {
+			if err = ex.Set(key, tp.UnixNano()); err != nil {
+				return
+			}
+		}
+		return
+	})
+}
+
+// memoryExpiry implements data.Expiry for
+// IdxDB that is not a data.ExpiryDB
+type memoryExpiry map[cipher.SHA256]int64
+
+func (m memoryExpiry) Set(key cipher.SHA256, expire int64) (_ error) {
+	m[key] = expire
+	return
+}
+
+func (m memoryExpiry) Del(key cipher.SHA256) (_ error) {
+	delete(m, key)
+	return
+}
+
+func (m memoryExpiry) Iterate(
+	iterateFunc data.IterateExpiryFunc,
+) (
+	err error,
+) {
+	for key, expire := range m {
+		if err = iterateFunc(key, expire); err != nil {
+			if err == data.ErrStopIteration {
+				err = nil
+			}
+			return
+		}
+	}
+	return
+}
+
+func (m memoryExpiry) Len() int {
+	return len(m)
+}
+
+func (e *expiry) close() {
+	e.closeo.Do(func() {
+		close(e.closeq)
+		e.await.Wait()
+	})
+}
+
+// SaveTTL saves given Root like the Save method, but
+// objects created by given Unpack expire after given
+// ttl. An expired object will be removed from DB if
+// it is not used by any Root (rc == 0). Used objects
+// retained until they are not used. The expiration
+// times kept in IdxDB if it is a data.ExpiryDB (the
+// data/idxdb package implements it). Otherwise, they
+// kept in memory, and after restart the objects never
+// expire. See also ExpiryInterval field of the Config
+// and the SweepExpired method
+func (c *Container) SaveTTL(
+	up *Unpack, //          :
+	r *registry.Root, //    :
+	ttl time.Duration, //   :
+) (
+	err error, //           :
+) {
+
+	var created []cipher.SHA256
+
+	for key, ui := range up.m {
+		if ui.created == true {
+			created = append(created, key)
+		}
+	}
+
+	if err = c.Save(up, r); err != nil {
+		return
+	}
+
+	return c.expiry.set(created, time.Now().Add(ttl))
+}
+
+// SweepExpired removes expired objects that are not
+// used (see SaveTTL). The SweepExpired called by the
+// Container periodically if ExpiryInterval of the
+// Config is not zero. It returns number of removed
+// objects
+func (c *Container) SweepExpired() (removed int, err error) {
+
+	if c.conf.ReadOnly == true {
+		return 0, ErrViewOnlyTree
+	}
+
+	c.expiry.mx.Lock()
+	defer c.expiry.mx.Unlock()
+
+	var (
+		now     = time.Now().UnixNano()
+		expired []cipher.SHA256
+		gone    []cipher.SHA256 // removed or not found
+	)
+
+	err = c.expiry.tx(func(ex data.Expiry) error {
+		return ex.Iterate(func(key cipher.SHA256, expire int64) (_ error) {
+			if expire <= now {
+				expired = append(expired, key)
+			}
+			return
+		})
+	})
+
+	if err != nil {
+		return
+	}
+
+	// remove objects outside the transaction
+
+	for _, key := range expired {
+
+		switch err = c.DelObject(key); err {
+		case nil:
+			removed++
+		case ErrObjectIsUsed:
+			continue // retain until it is used
+		case data.ErrNotFound:
+			// already removed
+		default:
+			return
+		}
+
+		gone = append(gone, key)
+
+	}
+
+	err = c.expiry.tx(func(ex data.Expiry) (err error) {
+		for _, key := range gone {
+			if err = ex.Del(key); err != nil {
+				return
+			}
+		}
+		return
+	})
+
+	return
+}
+
+func (c *Container) sweeping(interval time.Duration) {
+
+	defer c.expiry.await.Done()
+
+	var tk = time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			if _, err := c.SweepExpired(); err != nil {
+				log.Print("[ERR] sweeping expired objects: ", err)
+			}
+		case <-c.expiry.closeq:
+			return
+		}
+	}
+
+}
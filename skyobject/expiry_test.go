@@ -0,0 +1,176 @@
+package skyobject
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_SaveTTL(t *testing.T) {
+
+	const interval = 50 * time.Millisecond
+
+	var conf = getTestConfig()
+	conf.ExpiryInterval = interval
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		up     *Unpack
+		usr    = User{"Alice", 19}
+		key    = cipher.SumSHA256(encoder.Serialize(usr))
+		r      = new(registry.Root)
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &usr),
+	}
+
+	assertNil(t, c.SaveTTL(up, r, interval/5))
+
+	// expired, but used by the Root
+
+	time.Sleep(3 * interval)
+
+	_, _, err = c.Get(key, 0)
+	assertNil(t, err)
+
+	// not used
+
+	assertNil(t, c.DelHead(pk, 1))
+
+	time.Sleep(3 * interval)
+
+	_, _, err = c.Get(key, 0)
+	assertTrue(t, err == data.ErrNotFound, "expired object is not removed")
+
+	// the Registry never expires
+
+	_, _, err = c.Get(cipher.SHA256(testRegistry.Reference()), 0)
+	assertNil(t, err)
+
+}
+
+func TestContainer_SweepExpired(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+		up     *Unpack
+		err    error
+		r      = new(registry.Root)
+		n      int
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+		createDynamic(up, testRegistry, "test.User", &User{"Eva", 21}),
+	}
+
+	assertNil(t, c.SaveTTL(up, r, time.Hour))
+	assertNil(t, c.DelHead(pk, 1))
+
+	// not expired yet
+
+	n, err = c.SweepExpired()
+	assertNil(t, err)
+	assertTrue(t, n == 0, "removed not expired objects")
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Bob", 20}),
+		createDynamic(up, testRegistry, "test.User", &User{"Tom", 22}),
+	}
+
+	assertNil(t, c.SaveTTL(up, r, 0)) // expired
+
+	n, err = c.SweepExpired()
+	assertNil(t, err)
+	assertTrue(t, n == 0, "removed used objects")
+
+	assertNil(t, c.DelHead(pk, 1))
+
+	n, err = c.SweepExpired()
+	assertNil(t, err)
+	assertTrue(t, n == 2, "wrong number of removed objects")
+
+}
+
+func TestContainer_SweepExpired_reopen(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "test")
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		up     *Unpack
+		r      = new(registry.Root)
+		n      int
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	assertNil(t, c.SaveTTL(up, r, 0)) // expired
+	assertNil(t, c.DelHead(pk, 1))
+	assertNil(t, up.Close())
+	assertNil(t, c.Close())
+
+	// reopen, the expiration times are persistent
+
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	n, err = c.SweepExpired()
+	assertNil(t, err)
+	assertTrue(t, n == 1, "wrong number of removed objects")
+
+	n, err = c.SweepExpired()
+	assertNil(t, err)
+	assertTrue(t, n == 0, "expiration time is not removed")
+
+}
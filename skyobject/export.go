@@ -0,0 +1,246 @@
+package skyobject
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// Export stream format
+//
+// The stream is a sequence of records. Every record is
+// length of the record (little-endian uint32) and the
+// record itself. The first record is encoded exportRoot,
+// the Root with its feed and signature. Other records
+// are encoded objects of the Root, including Registry
+// of the Root. Order of the objects is order of walking,
+// and an object is exported once. The stream ends with
+// last object (EOF)
+
+// the first record of an export stream
+type exportRoot struct {
+	Pub  cipher.PubKey
+	Sig  cipher.Sig
+	Root []byte // encoded Root
+}
+
+// Export writes given Root and all objects the Root
+// refers to (including Registry) to given io.Writer
+// (see Export stream format above). The Root must be
+// signed and all its objects must be in DB (e.g. the
+// Root must be full). The Export returns number of
+// exported objects, excluding the Root. Use Import to
+// import exported Root and its objects
+func (c *Container) Export(
+	w io.Writer, //      : write to
+	r *registry.Root, // : the Root to export
+) (
+	objects int, //      : number of exported objects
+	err error, //        : an error
+) {
+
+	var pack *Pack
+	if pack, err = c.Pack(r, nil); err != nil {
+		return
+	}
+
+	var er = exportRoot{
+		Pub:  r.Pub,
+		Sig:  r.Sig,
+		Root: r.Encode(),
+	}
+
+	if err = writeExportRecord(w, encoder.Serialize(&er)); err != nil {
+		return
+	}
+
+	if err = writeExportRecord(w, pack.Registry().Encode()); err != nil {
+		return
+	}
+
+	objects++
+
+	var seen = make(map[cipher.SHA256]struct{})
+
+	err = r.Walk(pack,
+		func(hash cipher.SHA256, _ int) (deepper bool, err error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // nil
+			}
+
+			if _, ok := seen[hash]; ok == true {
+				return // already exported
+			}
+
+			seen[hash] = struct{}{}
+
+			var val []byte
+			if val, err = pack.Get(hash); err != nil {
+				return
+			}
+
+			if err = writeExportRecord(w, val); err != nil {
+				return
+			}
+
+			objects++
+			return true, nil
+		})
+
+	return
+}
+
+// Import reads export stream (see Export) from given
+// io.Reader and saves the Root and its objects. Feed
+// of the Root must be added to the Container before.
+// The Import verifies the Root (hash and signature) and
+// saves only objects the Root refers to. The stream is
+// read incrementally, while the Root is being filled.
+// It returns data.ErrNotFound if an object of the Root
+// is missing in the stream. The Import returns the
+// imported Root.
+// If the Container already has the Root, then the
+// Import returns it and does nothing
+func (c *Container) Import(rd io.Reader) (r *registry.Root, err error) {
+
+	var val []byte
+	if val, err = c.readExportRecord(rd); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF // no Root
+		}
+		return
+	}
+
+	var er exportRoot
+	if err = encoder.DeserializeRaw(val, &er); err != nil {
+		return
+	}
+
+	if r, err = c.ReceivedRoot(er.Pub, er.Sig, er.Root); err != nil {
+		return
+	}
+
+	if r.IsFull == true {
+		return // already have
+	}
+
+	// read objects incrementally, when the Fill requests
+	// them; objects that are not requested yet are kept
+	// until requested; since order of the objects is order
+	// of walking, there are few such objects
+
+	var (
+		objs = make(map[cipher.SHA256][]byte) // read, not requested
+		eof  bool                             // end of the stream
+	)
+
+	var next = func(key cipher.SHA256) (val []byte, err error) {
+
+		var ok bool
+		if val, ok = objs[key]; ok == true {
+			delete(objs, key)
+			return
+		}
+
+		for eof == false {
+
+			if val, err = c.readExportRecord(rd); err != nil {
+				if err == io.EOF {
+					eof = true
+					break
+				}
+				return
+			}
+
+			var hash = cipher.SumSHA256(val)
+
+			if hash == key {
+				return
+			}
+
+			objs[hash] = val
+
+		}
+
+		return nil, data.ErrNotFound
+	}
+
+	// fill the Root using the objects
+
+	var (
+		rq     = make(chan cipher.SHA256, 1)
+		done   = make(chan struct{})
+		exited = make(chan struct{}) // don't read after the Import
+		f      = c.Fill(r, rq, 1)    // objects are local
+	)
+
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case key := <-rq:
+				if val, err := next(key); err != nil {
+					f.Fail(err)
+				} else if _, err = c.SetWanted(key, val); err != nil {
+					f.Fail(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err = f.Run()
+	close(done)
+	<-exited
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func writeExportRecord(w io.Writer, val []byte) (err error) {
+
+	var l [4]byte
+	binary.LittleEndian.PutUint32(l[:], uint32(len(val)))
+
+	if _, err = w.Write(l[:]); err != nil {
+		return
+	}
+
+	_, err = w.Write(val)
+	return
+}
+
+// readExportRecord returns io.EOF if the stream ends
+// between records and io.ErrUnexpectedEOF if it ends
+// inside a record
+func (c *Container) readExportRecord(rd io.Reader) (val []byte, err error) {
+
+	var l [4]byte
+	if _, err = io.ReadFull(rd, l[:]); err != nil {
+		return
+	}
+
+	var ln = binary.LittleEndian.Uint32(l[:])
+
+	if int64(ln) > int64(c.conf.MaxObjectSize) {
+		return nil, ErrObjectIsTooLarge
+	}
+
+	val = make([]byte, ln)
+
+	if _, err = io.ReadFull(rd, val); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return
+}
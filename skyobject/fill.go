@@ -37,11 +37,22 @@ type Filler struct {
 // methods of the registry.Splitter
 //
 
+// MaxTreeDepth returns max depth of Refs trees
+// (see MaxTreeDepth field of the Config)
+func (f *Filler) MaxTreeDepth() int {
+	return f.c.conf.MaxTreeDepth
+}
+
 // Registry of the Filler
 func (f *Filler) Registry() (reg *registry.Registry) {
 	return f.reg
 }
 
+// Codec of the Container (see Codec)
+func (f *Filler) Codec() registry.Codec {
+	return f.c.Codec()
+}
+
 func (f *Filler) get(
 	key cipher.SHA256,
 	inc int,
@@ -346,6 +357,10 @@ func (f *Filler) getRegistry() (err error) {
 		return
 	}
 
+	if err = f.c.checkCodec(reg); err != nil {
+		return
+	}
+
 	f.reg = reg
 
 	return
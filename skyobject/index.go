@@ -34,6 +34,10 @@ func (i *indexHeads) setActive() {
 
 	for nonce, dr := range i.h {
 
+		if dr == nil {
+			continue // blank head
+		}
+
 		if i.activet < dr.Time {
 			i.activet = dr.Time
 			i.activen = nonce
@@ -162,9 +166,39 @@ func (i *Index) lastRoot(
 	return
 }
 
+// copy of last Root of given head, or nil if the
+// head is blank or doesn't exist
+func (i *Index) lastRootOfHead(
+	pk cipher.PubKey,
+	nonce uint64,
+) (
+	last *data.Root,
+	err error,
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	var dr *data.Root
+	if dr, err = i.lastRoot(pk, nonce); err != nil {
+		if err == data.ErrNotFound || err == data.ErrNoSuchHead {
+			err = nil // blank head
+		}
+		return
+	}
+
+	last = new(data.Root)
+	*last = *dr
+	return
+}
+
 // AddFeed adds feed
 func (i *Index) AddFeed(pk cipher.PubKey) (err error) {
 
+	if i.c.conf.ReadOnly == true {
+		return ErrViewOnlyTree
+	}
+
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
@@ -448,6 +482,10 @@ func (i *Index) addSavedRoot(r *registry.Root, dr *data.Root) {
 // method adds the Root to index (that is necessary)
 func (i *Index) AddRoot(r *registry.Root) (alreadyHave bool, err error) {
 
+	if i.c.conf.ReadOnly == true {
+		return false, ErrViewOnlyTree
+	}
+
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
@@ -565,6 +603,67 @@ func (i *Index) LastRoot(
 	return
 }
 
+// MissingSeqs returns seq numbers of Root objects of
+// given head of given feed that are not stored, but
+// there are stored Root objects with lesser and greater
+// seq. E.g. if there are Root objects with seq 1, 2 and 4,
+// then the MissingSeqs returns [3]. The seq numbers are
+// in ascending order. Since seq numbers are unique only
+// inside a head, the MissingSeqs requires nonce of the
+// head. Possible errors are data.ErrNoSuchFeed and
+// data.ErrNoSuchHead
+func (i *Index) MissingSeqs(
+	pk cipher.PubKey, // : feed
+	nonce uint64, //     : head
+) (
+	missing []uint64, // : missing seq numbers
+	err error, //        : an error
+) {
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+
+	if _, err = i.lastRoot(pk, nonce); err != nil {
+		if err == data.ErrNotFound {
+			err = nil // blank head, nothing is missing
+		}
+		return
+	}
+
+	err = i.c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+
+		var heads data.Heads
+		if heads, err = feeds.Heads(pk); err != nil {
+			return
+		}
+
+		var roots data.Roots
+		if roots, err = heads.Roots(nonce); err != nil {
+			return
+		}
+
+		var (
+			prev  uint64
+			first = true
+		)
+
+		return roots.Ascend(func(dr *data.Root) (_ error) {
+
+			if first == false {
+				for seq := prev + 1; seq < dr.Seq; seq++ {
+					missing = append(missing, seq)
+				}
+			}
+
+			prev, first = dr.Seq, false
+			return
+		})
+
+	})
+
+	return
+}
+
 // delFeed deletes feed from IdxDB and from the Index
 func (i *Index) delFeed(
 	pk cipher.PubKey,
@@ -640,6 +739,10 @@ func (i *Index) delFeedLock(
 // DelFeed deletes feed with all heads and Root objects
 func (i *Index) DelFeed(pk cipher.PubKey) (err error) {
 
+	if i.c.conf.ReadOnly == true {
+		return ErrViewOnlyTree
+	}
+
 	// with lock
 	var rhs []cipher.SHA256
 	if rhs, err = i.delFeedLock(pk); err != nil {
@@ -732,6 +835,10 @@ func (i *Index) delHeadLock(
 // Root of the head is held, returning ErrRootIsHeld error
 func (i *Index) DelHead(pk cipher.PubKey, nonce uint64) (err error) {
 
+	if i.c.conf.ReadOnly == true {
+		return ErrViewOnlyTree
+	}
+
 	// with lock
 
 	var rhs []cipher.SHA256
@@ -939,6 +1046,10 @@ func (i *Index) delRootRelatedValues(rootHash cipher.SHA256) (err error) {
 // Root doesn't exist
 func (i *Index) DelRoot(pk cipher.PubKey, nonce, seq uint64) (err error) {
 
+	if i.c.conf.ReadOnly == true {
+		return ErrViewOnlyTree
+	}
+
 	// with lock
 	var rootHash cipher.SHA256
 	if rootHash, err = i.delRootLock(pk, nonce, seq); err != nil {
@@ -1002,6 +1113,10 @@ func (i *Index) HasHead(pk cipher.PubKey, nonce uint64) (yep bool) {
 // and the Heads method will return it even if it empty
 func (i *Index) AddHead(pk cipher.PubKey, nonce uint64) (err error) {
 
+	if i.c.conf.ReadOnly == true {
+		return ErrViewOnlyTree
+	}
+
 	i.mx.Lock()
 	defer i.mx.Unlock()
 
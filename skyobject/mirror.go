@@ -0,0 +1,114 @@
+package skyobject
+
+import (
+	"log"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// secondary DB objects are written to (see Mirror)
+type mirror struct {
+	mx sync.Mutex
+	db *data.DB
+}
+
+// set writes given object to the mirror if the mirror is
+// set; if the writing fails, then the set rolls back the
+// primary write or logs the error (see MirrorIgnoreErrors).
+// The urc is references counter of the object after the
+// primary write, the object is removed from the primary
+// DB by the rollback only if the write has created it
+func (m *mirror) set(
+	primary data.CXDS, //  : primary CXDS to roll back
+	key cipher.SHA256, //  : key of the object
+	val []byte, //         : the object
+	inc int, //            : references counter
+	urc uint32, //         : rc after the primary write
+	ignore bool, //        : log errors instead of the rollback
+) (
+	err error, //          : mirror error
+) {
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	if m.db == nil {
+		return
+	}
+
+	if _, err = m.db.CXDS().Set(key, val, inc); err == nil {
+		return
+	}
+
+	if ignore == true {
+		log.Printf("[ERR] mirror: writing %s: %v", key.Hex()[:7], err)
+		return nil
+	}
+
+	var rerr error
+
+	if inc > 0 && urc == uint32(inc) {
+		rerr = primary.Del(key) // has been created
+	} else if inc != 0 {
+		_, rerr = primary.Inc(key, -inc)
+	}
+
+	if rerr != nil {
+		log.Printf("[ERR] mirror: rolling back %s: %v", key.Hex()[:7], rerr)
+	}
+
+	return
+}
+
+// Mirror sets secondary DB objects are written to. Every
+// object the Container writes to its DB is written to the
+// mirror too. If a mirror write fails, then the primary
+// write is rolled back and the error is returned, or the
+// error is logged (see MirrorIgnoreErrors field of the
+// Config). Objects are read from the primary DB only.
+// The mirror receives values encrypted like values of
+// the primary DB (see Encrypt) and doesn't track changes
+// of references counters. The Container doesn't close
+// the mirror. Use nil to stop mirroring
+func (c *Container) Mirror(db *data.DB) {
+
+	if db != nil && c.conf.Encrypt != nil && c.conf.Decrypt != nil {
+		db = data.NewDB(&cryptCXDS{
+			CXDS:    db.CXDS(),
+			encrypt: c.conf.Encrypt,
+			decrypt: c.conf.Decrypt,
+		}, db.IdxDB())
+	}
+
+	c.mirror.mx.Lock()
+	defer c.mirror.mx.Unlock()
+
+	c.mirror.db = db
+}
+
+// set to DB and to the mirror
+func (c *Cache) setDB(
+	key cipher.SHA256,
+	val []byte,
+	inc int,
+) (
+	urc uint32,
+	err error,
+) {
+
+	if urc, err = c.db().Set(key, val, inc); err != nil {
+		return
+	}
+
+	err = c.c.mirror.set(c.db(), key, val, inc, urc,
+		c.c.conf.MirrorIgnoreErrors)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return
+}
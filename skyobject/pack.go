@@ -1,8 +1,14 @@
 package skyobject
 
 import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
 	"github.com/skycoin/skycoin/src/cipher"
 
+	"github.com/skycoin/cxo/data"
 	"github.com/skycoin/cxo/skyobject/registry"
 )
 
@@ -10,8 +16,18 @@ import (
 type Pack struct {
 	reg   *registry.Registry
 	c     *Container
+	r     *registry.Root // Root of the Pack (can be nil)
 	deg   registry.Degree
 	flags registry.Flags
+
+	vals    map[valueKey]reflect.Value // decoded values
+	touched map[int]interface{}        // refs to re-encode (see Touch)
+	missing map[cipher.SHA256][]byte   // fetched by OnMissing (see Get)
+}
+
+type valueKey struct {
+	hash cipher.SHA256
+	typ  reflect.Type
 }
 
 // Registry returns related registry
@@ -19,15 +35,64 @@ func (p *Pack) Registry() (reg *registry.Registry) {
 	return p.reg
 }
 
-// Get value by hash
+// Get value by hash. If the value is not found, then
+// the Get calls OnMissing callback of the Config (if
+// set). The Get returns data.ErrNotFound if the
+// callback fails or returns value of another hash.
+// A Pack doesn't store fetched values, it keeps them
+// for its lifetime. An Unpack stores them as its
+// unsaved objects (see Get method of the Unpack)
 func (p *Pack) Get(key cipher.SHA256) (val []byte, err error) {
-	val, _, err = p.c.Get(key, 0)
+
+	var fetched bool
+	if val, fetched, err = p.get(key); err == nil && fetched == true {
+		if p.missing == nil {
+			p.missing = make(map[cipher.SHA256][]byte)
+		}
+		p.missing[key] = val
+	}
+
 	return
 }
 
+// get value by hash calling the OnMissing if the value
+// is not found; the fetched is true if the value has
+// been fetched by the OnMissing
+func (p *Pack) get(
+	key cipher.SHA256, // : hash of the value
+) (
+	val []byte, //        : the value
+	fetched bool, //      : fetched by the OnMissing
+	err error, //         : an error
+) {
+
+	val, _, err = p.c.Get(key, 0)
+
+	if err != data.ErrNotFound || p.c.conf.OnMissing == nil {
+		return
+	}
+
+	var ok bool
+	if val, ok = p.missing[key]; ok == true {
+		return val, false, nil // already fetched
+	}
+
+	if val, err = p.c.conf.OnMissing(key); err != nil ||
+		cipher.SumSHA256(val) != key {
+
+		return nil, false, data.ErrNotFound
+	}
+
+	return val, true, nil
+}
+
 // Set key-value pair
 func (p *Pack) Set(key cipher.SHA256, val []byte) (err error) {
 
+	if p.flags&registry.ViewOnly != 0 {
+		return ErrViewOnlyTree
+	}
+
 	if len(val) > p.c.conf.MaxObjectSize {
 		return &ObjectIsTooLargeError{key}
 	}
@@ -43,6 +108,48 @@ func (p *Pack) Add(val []byte) (key cipher.SHA256, err error) {
 	return
 }
 
+// Value returns decoded value kept by the Pack.
+// The Value implements registry.ValuePack interface
+func (p *Pack) Value(
+	hash cipher.SHA256, // :
+	typ reflect.Type, //   :
+) (
+	val reflect.Value, //  :
+	ok bool, //            :
+) {
+	val, ok = p.vals[valueKey{hash, typ}]
+	return
+}
+
+// KeepValue keeps decoded value for lifetime of the Pack.
+// A ViewOnly Pack with the LeanWalk flag doesn't keep
+// values. The KeepValue implements registry.ValuePack
+// interface
+func (p *Pack) KeepValue(hash cipher.SHA256, val reflect.Value) {
+	if p.flags&(registry.ViewOnly|registry.LeanWalk) ==
+		registry.ViewOnly|registry.LeanWalk {
+		return // don't keep (see registry.LeanWalk)
+	}
+	if p.vals == nil {
+		p.vals = make(map[valueKey]reflect.Value)
+	}
+	p.vals[valueKey{hash, val.Type()}] = val
+}
+
+// Touch is implemented by the Unpack only, since a
+// Pack can't save changes. For a Pack the Touch always
+// returns ErrViewOnlyTree (see Touch method of the
+// Unpack)
+func (p *Pack) Touch(i int, obj interface{}) (err error) {
+	return ErrViewOnlyTree
+}
+
+// Codec of the Pack. It's Codec of the Container
+// and it can't be changed for the Pack
+func (p *Pack) Codec() registry.Codec {
+	return p.c.Codec()
+}
+
 // Degree of the Pack
 func (p *Pack) Degree() registry.Degree {
 	return p.deg
@@ -61,13 +168,25 @@ func (p *Pack) Flags() registry.Flags {
 	return p.flags
 }
 
+// MaxTreeDepth returns max depth of Refs trees
+// (see MaxTreeDepth field of the Config). The
+// method implements registry.DepthPack interface
+func (p *Pack) MaxTreeDepth() int {
+	return p.c.conf.MaxTreeDepth
+}
+
 // AddFlags adds given flags to flags of the Pack (|)
 func (p *Pack) AddFlags(flags registry.Flags) {
 	p.flags |= flags
 }
 
-// ClearFlags isclears given flags from flags of the Pack (&^)
+// ClearFlags isclears given flags from flags of the Pack (&^).
+// The ViewOnly flag can't be cleared if the Container is
+// read-only
 func (p *Pack) ClearFlags(flags registry.Flags) {
+	if p.c.conf.ReadOnly == true {
+		flags &^= registry.ViewOnly
+	}
 	p.flags &^= flags
 }
 
@@ -100,6 +219,805 @@ func (c *Container) Pack(
 		}
 	}
 
+	if err = c.checkCodec(reg); err != nil {
+		return
+	}
+
 	p = c.getPack(reg)
+	p.r = r
+	return
+}
+
+// PackFromRoot decodes given encoded Root and returns
+// Pack of the Root. The Root is not saved and not
+// required to be in DB. But the Registry of the Root
+// is required. The PackFromRoot verifies the Root: its
+// feed, Registry and Dynamic references. Objects the
+// Root refers to are not checked, and a missing object
+// turns data.ErrNotFound when the object is requested
+// (e.g. walking). The Pack has given flags and the
+// ViewOnly flag. The flag can be cleared using the
+// ClearFlags method of the Pack
+func (c *Container) PackFromRoot(
+	val []byte, //              : encoded Root
+	flags registry.Flags, //    : flags of the Pack
+) (
+	p *Pack, //                 : the Pack
+	err error, //               : an error
+) {
+
+	var r *registry.Root
+	if r, err = registry.DecodeRoot(val); err != nil {
+		return
+	}
+
+	r.Hash = cipher.SumSHA256(val)
+
+	if err = r.Pub.Verify(); err != nil {
+		return
+	}
+
+	if r.Reg == (registry.RegistryRef{}) {
+		return nil, ErrBlankRegistryRef
+	}
+
+	var reg *registry.Registry
+	if reg, err = c.Registry(r.Reg); err != nil {
+		return
+	}
+
+	if err = c.checkCodec(reg); err != nil {
+		return
+	}
+
+	for i := range r.Refs {
+
+		if r.Refs[i].IsValid() == false {
+			return nil, registry.ErrInvalidDynamicReference
+		}
+
+		if r.Refs[i].Schema.IsBlank() == true {
+			continue
+		}
+
+		if _, err = reg.SchemaByReference(r.Refs[i].Schema); err != nil {
+			return
+		}
+
+	}
+
+	p = c.getPack(reg)
+	p.r = r
+	p.flags |= flags | registry.ViewOnly
+
+	return
+}
+
+// AppendUnique appends given object to given Refs if the
+// Refs doesn't contain the object. The object is encoded
+// using Codec of the Pack and compared by hash. It's O(1)
+// if the Refs has HashTableIndex flag, and O(n) otherwise
+// (see HasHash method of the Refs). The object must be
+// non-nil and of schema of the Refs. The AppendUnique
+// returns true if the object has been appended
+func (p *Pack) AppendUnique(
+	refs *registry.Refs, // : the Refs
+	obj interface{}, //     : object to append
+) (
+	added bool, //          : appended
+	err error, //           : an error
+) {
+	return appendUnique(p, p.Codec(), refs, obj)
+}
+
+func appendUnique(
+	pack registry.Pack, //   : pack to load and save
+	codec registry.Codec, // : codec of the pack
+	refs *registry.Refs, //  : the Refs
+	obj interface{}, //      : object to append
+) (
+	added bool, //           : appended
+	err error, //            : an error
+) {
+
+	var val []byte
+	if val, err = codec.Marshal(obj); err != nil {
+		return
+	}
+
+	var (
+		hash = cipher.SumSHA256(val)
+		ok   bool
+	)
+
+	if ok, err = refs.HasHash(pack, hash); err != nil || ok == true {
+		return // already has or error
+	}
+
+	if err = pack.Set(hash, val); err != nil {
+		return
+	}
+
+	if err = refs.AppendHashes(pack, hash); err != nil {
+		return
+	}
+
+	return true, nil
+}
+
+// Root of the Pack. It's the Root the Pack created
+// for (see (*Container).Pack). It can be nil
+func (p *Pack) Root() (r *registry.Root) {
+	return p.r
+}
+
+// RootHash returns hash of the Root of the Pack in its
+// current state, including unsaved changes of the Refs
+// of the Root. The Root is not saved and not changed.
+// The RootHash sets Seq, Prev and Time fields like the
+// Save does, thus it returns the hash the Save produces
+// for the Root, if the Time field of the Root is set by
+// caller and is greater than timestamp of last Root of
+// the head (see Save). Otherwise, the Save uses current
+// time, and the hash can't be predicted. If the Root is
+// saved and not changed, then the RootHash returns its
+// hash. If Registry reference of the Root is blank, then
+// reference of the Registry of the Pack is used, as the
+// Save does. The RootHash returns ErrPackWithoutRoot if
+// the Pack created without Root, and data.ErrNoSuchFeed
+// if feed of the Root doesn't exist
+func (p *Pack) RootHash() (hash cipher.SHA256, err error) {
+
+	if p.r == nil {
+		return cipher.SHA256{}, ErrPackWithoutRoot
+	}
+
+	var r = *p.r // shallow copy
+
+	if r.Reg == (registry.RegistryRef{}) {
+		r.Reg = p.reg.Reference()
+	}
+
+	var last *data.Root
+	if last, err = p.c.lastRootOfHead(r.Pub, r.Nonce); err != nil {
+		return
+	}
+
+	if last == nil {
+		setNextRoot(&r, 0, cipher.SHA256{}, 0)
+		return cipher.SumSHA256(r.Encode()), nil
+	}
+
+	// saved and not changed
+	if r.Hash == last.Hash && cipher.SumSHA256(r.Encode()) == r.Hash {
+		return r.Hash, nil
+	}
+
+	setNextRoot(&r, last.Seq, last.Hash, last.Time)
+	return cipher.SumSHA256(r.Encode()), nil
+}
+
+// ChildHashes returns hashes of objects the Dynamic
+// references of the Root of the Pack point to, in order
+// of the references. Blank references are skipped. The
+// objects are not loaded and not decoded. The ChildHashes
+// returns ErrPackWithoutRoot if the Pack created without
+// Root
+func (p *Pack) ChildHashes() (hashes []cipher.SHA256, err error) {
+
+	if p.r == nil {
+		return nil, ErrPackWithoutRoot
+	}
+
+	hashes = make([]cipher.SHA256, 0, len(p.r.Refs))
+
+	for _, dr := range p.r.Refs {
+		if dr.Hash != (cipher.SHA256{}) {
+			hashes = append(hashes, dr.Hash)
+		}
+	}
+
+	return
+}
+
+// Validate walks through objects of the Root of the Pack
+// and decodes every reachable object using its Schema,
+// or to its golang type if the type is registered in the
+// Registry of the Pack. The Validate collects errors
+// (*DecodingError with hash of the object, mostly) and
+// doesn't stop on first one; it doesn't walk through
+// objects a malformed object refers to. Empty result
+// means that the tree is sound. The Validate returns
+// ErrPackWithoutRoot if the Pack created without Root
+func (p *Pack) Validate() (errs []error) {
+
+	if p.r == nil {
+		return []error{ErrPackWithoutRoot}
+	}
+
+	var (
+		schemas = make(map[cipher.SHA256]registry.Schema)
+		seen    = make(map[cipher.SHA256]struct{})
+		types   = p.reg.Types()
+
+		err error
+	)
+
+	if err = p.r.ObjectSchemas(p, schemas); err != nil {
+		return []error{err}
+	}
+
+	for _, dr := range p.r.Refs {
+
+		err = dr.Walk(p,
+			func(hash cipher.SHA256, _ int) (deepper bool, err error) {
+
+				if hash == (cipher.SHA256{}) {
+					return // nil
+				}
+
+				if _, ok := seen[hash]; ok == true {
+					return // already checked
+				}
+
+				seen[hash] = struct{}{}
+
+				var val []byte
+				if val, err = p.Get(hash); err != nil {
+					errs = append(errs, &DecodingError{hash, err})
+					return false, nil // don't go deepper
+				}
+
+				var sch, ok = schemas[hash]
+
+				if ok == false || sch == nil {
+					return true, nil // a Refs node
+				}
+
+				if err = p.decode(types, sch, val); err != nil {
+					errs = append(errs, &DecodingError{hash, err})
+					return false, nil // don't go deepper
+				}
+
+				return true, nil
+			})
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+	}
+
+	return
+}
+
+// decode given value using given Schema or
+// registered golang type of the Schema
+func (p *Pack) decode(
+	types *registry.Types, // : registered types
+	sch registry.Schema, //   : schema of the value
+	val []byte, //            : encoded value
+) (
+	err error, //             : decoding error
+) {
+
+	defer func() {
+		if pc := recover(); pc != nil {
+			err = fmt.Errorf("malformed object: %v", pc)
+		}
+	}()
+
+	if typ, ok := types.Direct[sch.Name()]; ok == true {
+		return p.Codec().Unmarshal(val, reflect.New(typ).Interface())
+	}
+
+	var n int
+	if n, err = sch.Size(val); err != nil {
+		return
+	}
+
+	if n != len(val) {
+		err = fmt.Errorf("invalid size of encoded object: %d, want %d",
+			len(val), n)
+	}
+
+	return
+}
+
+// SnapshotRoot returns deep copy of the Root of the
+// Pack. Use RestoreRoot to revert changes of the Root
+// made after. The SnapshotRoot returns nil if the Pack
+// created without Root
+func (p *Pack) SnapshotRoot() (r *registry.Root) {
+	if p.r == nil {
+		return
+	}
+	return copyRoot(p.r)
+}
+
+// RestoreRoot reverts the Root of the Pack to given
+// snapshot (see SnapshotRoot). The Root is changed in
+// place, and the snapshot can be used again. If the
+// Pack created without Root, then the RestoreRoot sets
+// copy of the snapshot as the Root of the Pack
+func (p *Pack) RestoreRoot(snapshot *registry.Root) {
+	if snapshot == nil {
+		return
+	}
+	if p.r == nil {
+		p.r = copyRoot(snapshot)
+		return
+	}
+	*p.r = *copyRoot(snapshot)
+}
+
+func copyRoot(r *registry.Root) (cp *registry.Root) {
+	cp = new(registry.Root)
+	*cp = *r
+	if r.Refs != nil {
+		cp.Refs = make([]registry.Dynamic, len(r.Refs))
+		copy(cp.Refs, r.Refs)
+	}
+	if r.Descriptor != nil {
+		cp.Descriptor = make([]byte, len(r.Descriptor))
+		copy(cp.Descriptor, r.Descriptor)
+	}
+	return
+}
+
+// Diff compares hashes of all objects of the Root of
+// the Pack (including hash of the Root and hash of the
+// Registry) with given list of hashes. The Diff returns
+// hashes the Root has but the list has not (onlyLocal),
+// and hashes the list has but the Root has not
+// (onlyRemote). The remote list is expected to be
+// reachable objects of another Root. The Diff returns
+// ErrPackWithoutRoot if the Pack created without Root
+func (p *Pack) Diff(
+	remote []cipher.SHA256,
+) (
+	onlyLocal []cipher.SHA256,
+	onlyRemote []cipher.SHA256,
+	err error,
+) {
+
+	if p.r == nil {
+		err = ErrPackWithoutRoot
+		return
+	}
+
+	var rs = make(map[cipher.SHA256]struct{}, len(remote))
+
+	for _, hash := range remote {
+		rs[hash] = struct{}{}
+	}
+
+	var ls = make(map[cipher.SHA256]struct{})
+
+	err = p.c.walkRoot(p, p.r,
+		func(hash cipher.SHA256, _ int) (deepper bool, _ error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // nil
+			}
+
+			if _, ok := ls[hash]; ok == true {
+				return // already walked
+			}
+
+			ls[hash] = struct{}{}
+
+			if _, ok := rs[hash]; ok == false {
+				onlyLocal = append(onlyLocal, hash)
+			}
+
+			return true, nil
+		})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, hash := range remote {
+		if _, ok := ls[hash]; ok == true || hash == (cipher.SHA256{}) {
+			continue
+		}
+		ls[hash] = struct{}{} // avoid duplicates
+		onlyRemote = append(onlyRemote, hash)
+	}
+
+	return
+}
+
+// Find walks through objects of the Root of the Pack
+// and returns hashes of objects given predicate returns
+// true for. The predicate called with name of Schema and
+// encoded value of an object. Nodes of Refs trees (and
+// the Refs themselves) are not passed to the predicate.
+// An object the Root refers to many times is passed once.
+// Order of the hashes is order of the walking. The Find
+// returns ErrPackWithoutRoot if the Pack created without
+// Root
+func (p *Pack) Find(
+	pred func(schema string, val []byte) bool, // : the predicate
+) (
+	found []cipher.SHA256, //                     : matching objects
+	err error, //                                 : an error
+) {
+
+	if p.r == nil {
+		return nil, ErrPackWithoutRoot
+	}
+
+	var schemas = make(map[cipher.SHA256]registry.Schema)
+
+	if err = p.r.ObjectSchemas(p, schemas); err != nil {
+		return
+	}
+
+	var seen = make(map[cipher.SHA256]struct{})
+
+	err = p.r.Walk(p,
+		func(hash cipher.SHA256, _ int) (deepper bool, err error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // nil
+			}
+
+			if _, ok := seen[hash]; ok == true {
+				return // already walked
+			}
+
+			seen[hash] = struct{}{}
+
+			var sch, ok = schemas[hash]
+
+			if ok == false || sch == nil {
+				return true, nil // a Refs node or unreachable
+			}
+
+			var val []byte
+			if val, err = p.Get(hash); err != nil {
+				return
+			}
+
+			if pred(sch.Name(), val) == true {
+				found = append(found, hash)
+			}
+
+			return true, nil
+		})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// RefSchemas returns names of schemas of the Refs of
+// the Root of the Pack. Order of the names is the same
+// as order of the Refs. A Dynamic with blank schema
+// reference yields empty string. The RefSchemas doesn't
+// fetch values. It returns ErrPackWithoutRoot if the
+// Pack created without Root, and *SchemaNotRegisteredError
+// if a schema not found in the Registry of the Pack
+func (p *Pack) RefSchemas() (names []string, err error) {
+
+	if p.r == nil {
+		err = ErrPackWithoutRoot
+		return
+	}
+
+	names = make([]string, len(p.r.Refs))
+
+	var sch registry.Schema
+
+	for i := range p.r.Refs {
+
+		if p.r.Refs[i].Schema.IsBlank() == true {
+			continue // blank name
+		}
+
+		if sch, err = p.reg.SchemaByReference(p.r.Refs[i].Schema); err != nil {
+			return nil, err
+		}
+
+		names[i] = sch.Name()
+
+	}
+
+	return
+}
+
+// ReplaceRegistry replaces Registry of the Pack with
+// given one. Types of a Registry belong to it, thus they
+// are replaced too. Before the replacing, it checks that
+// every Schema referenced by the Root of the Pack (see
+// RefSchemas), every registered Schema they use (fields,
+// elements and references), and every Schema of objects
+// reachable from the Root, including objects of nested
+// Dynamic references, is registered in given Registry
+// by name. If not, the ReplaceRegistry
+// returns *MissingSchemasError. Then it checks that the
+// Schemas are the same in both Registries (the same
+// encoded Schema). If not, the ReplaceRegistry returns
+// *IncompatibleSchemasError. Objects are not re-encoded,
+// thus the check is strict. Given Registry must be of
+// the same Codec (see ErrDifferentCodec). In any error
+// case the Pack kept as is. After the replacing, the
+// Root refers to given Registry and its Dynamic
+// references refer to Schemas of the Registry. The Root
+// is changed in place, and its Hash and Sig are cleared,
+// since the Root should be signed and saved again
+func (p *Pack) ReplaceRegistry(reg *registry.Registry) (err error) {
+
+	if err = p.c.checkCodec(reg); err != nil {
+		return
+	}
+
+	var names []string
+
+	if p.r != nil {
+		if names, err = p.RefSchemas(); err != nil {
+			return
+		}
+	}
+
+	// names of all used registered Schemas (ordered)
+
+	var (
+		used   []string
+		seen   = make(map[string]struct{})
+		oldSch registry.Schema
+	)
+
+	for _, name := range names {
+
+		if name == "" {
+			continue // blank
+		}
+
+		if oldSch, err = p.reg.SchemaByName(name); err != nil {
+			return
+		}
+
+		used = usedSchemas(oldSch, seen, used)
+
+	}
+
+	// Schemas of all reachable objects, including objects
+	// of Dynamic references of the objects (ordered by name)
+
+	if p.r != nil {
+
+		var schemas = make(map[cipher.SHA256]registry.Schema)
+
+		if err = p.r.ObjectSchemas(p, schemas); err != nil {
+			return
+		}
+
+		var reachable = make(map[string]registry.Schema)
+
+		for _, sch := range schemas {
+			if sch != nil && sch.IsRegistered() == true {
+				reachable[sch.Name()] = sch
+			}
+		}
+
+		var rnames = make([]string, 0, len(reachable))
+
+		for name := range reachable {
+			rnames = append(rnames, name)
+		}
+
+		sort.Strings(rnames)
+
+		for _, name := range rnames {
+			used = usedSchemas(reachable[name], seen, used)
+		}
+
+	}
+
+	var (
+		refs = make(map[string]registry.SchemaRef, len(used))
+
+		missing, incompatible []string
+
+		newSch registry.Schema
+	)
+
+	for _, name := range used {
+
+		if newSch, err = reg.SchemaByName(name); err != nil {
+			missing = append(missing, name)
+			continue
+		}
+
+		if oldSch, err = p.reg.SchemaByName(name); err != nil {
+			return
+		}
+
+		if bytes.Equal(oldSch.Encode(), newSch.Encode()) == false {
+			incompatible = append(incompatible, name)
+			continue
+		}
+
+		refs[name] = newSch.Reference()
+
+	}
+
+	if len(missing) != 0 {
+		return &MissingSchemasError{missing}
+	}
+
+	if len(incompatible) != 0 {
+		return &IncompatibleSchemasError{incompatible}
+	}
+
+	err = nil
+	p.reg = reg
+
+	if p.r == nil {
+		return
+	}
+
+	p.r.Reg = reg.Reference()
+
+	for i, name := range names {
+		if name != "" {
+			p.r.Refs[i].Schema = refs[name]
+		}
+	}
+
+	p.r.Hash, p.r.Sig = cipher.SHA256{}, cipher.Sig{} // changed
+
+	return
+}
+
+// usedSchemas appends names of registered Schemas
+// used by given one (and the Schema itself if it is
+// registered) to given slice
+func usedSchemas(
+	sch registry.Schema, //      : the Schema
+	seen map[string]struct{}, // : already appended
+	used []string, //            : names
+) (
+	names []string, //           : the used with new names
+) {
+
+	if sch.IsRegistered() == true {
+		if _, ok := seen[sch.Name()]; ok == true {
+			return used
+		}
+		seen[sch.Name()] = struct{}{}
+		used = append(used, sch.Name())
+	}
+
+	if sch.IsReference() == true {
+		if sch.ReferenceType() == registry.ReferenceTypeDynamic {
+			return used // schema of a Dynamic is taken from its object
+		}
+		return usedSchemas(sch.Elem(), seen, used)
+	}
+
+	switch sch.Kind() {
+	case reflect.Array, reflect.Slice:
+		return usedSchemas(sch.Elem(), seen, used)
+	case reflect.Struct:
+		for _, fl := range sch.Fields() {
+			used = usedSchemas(fl.Schema(), seen, used)
+		}
+	}
+
+	return used
+}
+
+// DumpDepth is depth limit of the Dump method of the Pack
+const DumpDepth int = 10
+
+// Dump returns human readable representation of object
+// with given hash. The object should be reachable from
+// the Root of the Pack, because the Dump uses Schema of
+// the object to print it (and the Schema is taken from
+// the Root tree). The Dump doesn't require registered
+// types. It goes through references up to DumpDepth.
+// The Dump returns ErrPackWithoutRoot if the Pack
+// created without Root, and registry.ErrNotFound if the
+// object is not reachable from the Root
+func (p *Pack) Dump(hash cipher.SHA256) (dump string, err error) {
+
+	if p.r == nil {
+		err = ErrPackWithoutRoot
+		return
+	}
+
+	if hash == p.r.Hash {
+		return p.r.Tree(p)
+	}
+
+	var sch registry.Schema
+	if sch, err = p.r.SchemaOf(p, hash); err != nil {
+		return
+	}
+
+	return registry.ObjectTree(p, sch, hash, DumpDepth)
+}
+
+// GetRaw returns encoded object with given hash and
+// name of its Schema. The object is not decoded. The
+// object should be reachable from the Root of the Pack,
+// because the Schema is taken from the Root tree. The
+// name is empty if the Schema is not named. The GetRaw
+// returns ErrPackWithoutRoot if the Pack created without
+// Root, and registry.ErrNotFound if the object is not
+// reachable from the Root
+func (p *Pack) GetRaw(
+	hash cipher.SHA256, // :
+) (
+	schema string, //      :
+	val []byte, //         :
+	err error, //          :
+) {
+
+	if p.r == nil {
+		err = ErrPackWithoutRoot
+		return
+	}
+
+	var sch registry.Schema
+	if sch, err = p.r.SchemaOf(p, hash); err != nil {
+		return
+	}
+
+	if val, err = p.Get(hash); err != nil {
+		return
+	}
+
+	schema = sch.Name()
+	return
+}
+
+// Manifest returns hashes of all objects reachable from
+// the Root of the Pack. Every hash is listed once, and the
+// list is sorted, thus two manifests can be compared
+// directly. The Manifest doesn't include hash of the Root
+// and hash of its Registry. Already visited objects are not
+// walked again, thus shared subtrees and cycles are
+// processed once. The Manifest returns ErrPackWithoutRoot
+// if the Pack created without Root
+func (p *Pack) Manifest() (hashes []cipher.SHA256, err error) {
+
+	if p.r == nil {
+		return nil, ErrPackWithoutRoot
+	}
+
+	var seen = make(map[cipher.SHA256]struct{})
+
+	err = p.r.Walk(p,
+		func(hash cipher.SHA256, _ int) (deepper bool, _ error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // nil
+			}
+
+			if _, ok := seen[hash]; ok == true {
+				return // already walked
+			}
+
+			seen[hash] = struct{}{}
+			hashes = append(hashes, hash)
+
+			return true, nil
+		})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+
 	return
 }
@@ -0,0 +1,1474 @@
+package skyobject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestPack_Diff(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		bob   = User{"Bob", 20}
+		eva   = User{"Eva", 21}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var local, remote = new(registry.Root), new(registry.Root)
+
+	local.Pub, local.Nonce = pk, 1
+	local.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.User", &bob),
+	}
+	assertNil(t, c.Save(up, local))
+
+	remote.Pub, remote.Nonce = pk, 2
+	remote.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &bob),
+		createDynamic(up, testRegistry, "test.User", &eva),
+	}
+	assertNil(t, c.Save(up, remote))
+
+	var remoteHashes []cipher.SHA256
+
+	assertNil(t, c.Walk(remote, func(hash cipher.SHA256, _ int) (bool, error) {
+		remoteHashes = append(remoteHashes, hash)
+		return true, nil
+	}))
+
+	var pack *Pack
+	pack, err = c.Pack(local, nil)
+	assertNil(t, err)
+
+	var onlyLocal, onlyRemote []cipher.SHA256
+	onlyLocal, onlyRemote, err = pack.Diff(remoteHashes)
+	assertNil(t, err)
+
+	var userHash = func(u User) cipher.SHA256 {
+		return cipher.SumSHA256(encoder.Serialize(u))
+	}
+
+	assertTrue(t, len(onlyLocal) == 2, "wrong length of onlyLocal")
+	assertTrue(t, onlyLocal[0] == local.Hash, "missing local Root")
+	assertTrue(t, onlyLocal[1] == userHash(alice), "missing Alice")
+
+	assertTrue(t, len(onlyRemote) == 2, "wrong length of onlyRemote")
+	assertTrue(t, onlyRemote[0] == remote.Hash, "missing remote Root")
+	assertTrue(t, onlyRemote[1] == userHash(eva), "missing Eva")
+
+	// the same
+
+	onlyLocal, onlyRemote, err = pack.Diff(append(remoteHashes,
+		local.Hash, userHash(alice)))
+	assertNil(t, err)
+	assertTrue(t, len(onlyLocal) == 0, "not empty onlyLocal")
+	assertTrue(t, len(onlyRemote) == 2, "wrong length of onlyRemote")
+
+	// without Root
+
+	_, _, err = up.Diff(remoteHashes)
+	assertTrue(t, err == ErrPackWithoutRoot, "unexpected error")
+
+}
+
+func TestPack_SnapshotRoot(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Descriptor = []byte("descriptor")
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	var pack *Pack
+	pack, err = c.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	var snapshot = pack.SnapshotRoot()
+
+	for _, usr := range []User{{"Bob", 20}, {"Eva", 21}, {"Tom", 22}} {
+		r.Refs = append(r.Refs,
+			createDynamic(up, testRegistry, "test.User", &usr))
+	}
+	r.Refs[0] = registry.Dynamic{}
+	r.Descriptor[0] = 'D'
+	r.Seq = 10
+
+	pack.RestoreRoot(snapshot)
+
+	assertTrue(t, pack.Root() == r, "Root replaced")
+	assertTrue(t, r.Seq == 0, "wrong seq")
+	assertTrue(t, string(r.Descriptor) == "descriptor", "wrong descriptor")
+	assertTrue(t, len(r.Refs) == 1, "wrong length")
+	assertTrue(t, r.Refs[0] == createDynamic(up, testRegistry, "test.User",
+		&User{"Alice", 19}), "wrong ref")
+
+	// the snapshot is not affected by the RestoreRoot
+	r.Refs[0] = registry.Dynamic{}
+	assertTrue(t, snapshot.Refs[0].IsBlank() == false, "snapshot changed")
+
+}
+
+func TestPack_RefSchemas(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		{}, // blank
+		createDynamic(up, testRegistry, "test.User", &alice),
+		{},
+		createDynamic(up, testRegistry, "test.Feed", &Feed{}),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	var names []string
+	names, err = pack.RefSchemas()
+	assertNil(t, err)
+
+	var want = []string{"", "test.User", "", "test.Feed"}
+
+	assertTrue(t, len(names) == len(want), "wrong length")
+
+	for i, name := range names {
+		if name != want[i] {
+			t.Errorf("wrong name %d: %q, want %q", i, name, want[i])
+		}
+	}
+
+	// without Root
+
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	_, err = pack.RefSchemas()
+	assertTrue(t, err == ErrPackWithoutRoot, "missing ErrPackWithoutRoot")
+
+}
+
+func TestPack_KeepValue(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var ref registry.Ref
+	assertNil(t, ref.SetValue(up, &alice))
+
+	var pack *Pack
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	for i := 0; i < 2; i++ {
+		var usr User
+		assertNil(t, ref.Value(pack, &usr))
+		assertTrue(t, usr == alice, "wrong value")
+		assertTrue(t, len(pack.vals) == 1, "value is not kept")
+	}
+
+}
+
+func TestPack_LeanWalk(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var ref registry.Ref
+	assertNil(t, ref.SetValue(up, &alice))
+
+	var value = func(pack *Pack) {
+		for i := 0; i < 2; i++ {
+			var usr User
+			assertNil(t, ref.Value(pack, &usr))
+			assertTrue(t, usr == alice, "wrong value")
+		}
+	}
+
+	// view only
+
+	var pack *Pack
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	pack.AddFlags(registry.ViewOnly | registry.LeanWalk)
+	value(pack)
+	assertTrue(t, len(pack.vals) == 0, "value is kept")
+
+	// writable (no-op)
+
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	pack.AddFlags(registry.LeanWalk)
+	value(pack)
+	assertTrue(t, len(pack.vals) == 1, "value is not kept")
+
+}
+
+func BenchmarkPack_LeanWalk(b *testing.B) {
+
+	const n = 1000 // objects
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	if err := c.AddFeed(pk); err != nil {
+		b.Fatal(err)
+	}
+
+	var up, err = c.Unpack(sk, testRegistry)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer up.Close()
+
+	var refs = make([]registry.Ref, n)
+
+	for i := range refs {
+		var usr = User{fmt.Sprint("user-", i), uint32(i)}
+		if err = refs[i].SetValue(up, &usr); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, bc := range []struct {
+		name  string
+		flags registry.Flags
+	}{
+		{"keep", registry.ViewOnly},
+		{"lean", registry.ViewOnly | registry.LeanWalk},
+	} {
+
+		b.Run(bc.name, func(b *testing.B) {
+
+			var kept int
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+
+				var pack *Pack
+				if pack, err = c.Pack(nil, testRegistry); err != nil {
+					b.Fatal(err)
+				}
+				pack.AddFlags(bc.flags)
+
+				for j := range refs {
+					var usr User
+					if err = refs[j].Value(pack, &usr); err != nil {
+						b.Fatal(err)
+					}
+				}
+
+				kept = len(pack.vals)
+			}
+
+			b.ReportMetric(float64(kept), "kept-values")
+
+		})
+
+	}
+
+}
+
+func TestPack_Dump(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var feed = Feed{Head: "news", Info: "daily"}
+
+	assertNil(t, feed.Posts.AppendValues(up,
+		&Post{"first", "hello"},
+		&Post{"second", "world"},
+	))
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+	assertNil(t, c.Save(up, r))
+
+	// a pack without registered types
+
+	var reg *registry.Registry
+	reg, err = c.Registry(r.Reg)
+	assertNil(t, err)
+
+	var pack *Pack
+	pack, err = c.Pack(r, reg)
+	assertNil(t, err)
+
+	var dump string
+	dump, err = pack.Dump(r.Refs[0].Hash)
+	assertNil(t, err)
+
+	for _, want := range []string{
+		"Head: news",
+		"Info: daily",
+		"Head: first",
+		"Body: hello",
+		"Head: second",
+		"Body: world",
+	} {
+		assertTrue(t, strings.Contains(dump, want), "missing "+want)
+	}
+
+	// nested object
+
+	var ph cipher.SHA256
+	ph, err = feed.Posts.HashByIndex(up, 1)
+	assertNil(t, err)
+
+	dump, err = pack.Dump(ph)
+	assertNil(t, err)
+	assertTrue(t, strings.Contains(dump, "Body: world"), "missing value")
+	assertTrue(t, strings.Contains(dump, "hello") == false, "unexpected value")
+
+	// unknown
+
+	_, err = pack.Dump(cipher.SHA256{1, 2, 3})
+	assertTrue(t, err == registry.ErrNotFound, "missing ErrNotFound")
+
+}
+
+func TestPack_GetRaw(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		post  = Post{"first", "hello"}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var feed Feed
+	assertNil(t, feed.Posts.AppendValues(up, &post))
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	var ph cipher.SHA256
+	ph, err = feed.Posts.HashByIndex(pack, 0)
+	assertNil(t, err)
+
+	for _, tc := range []struct {
+		hash   cipher.SHA256
+		schema string
+		val    []byte
+	}{
+		{r.Refs[0].Hash, "test.User", encoder.Serialize(&alice)},
+		{r.Refs[1].Hash, "test.Feed", encoder.Serialize(&feed)},
+		{ph, "test.Post", encoder.Serialize(&post)},
+	} {
+
+		var (
+			schema string
+			val    []byte
+		)
+
+		schema, val, err = pack.GetRaw(tc.hash)
+		assertNil(t, err)
+
+		assertTrue(t, schema == tc.schema, "wrong schema: "+schema)
+		assertTrue(t, string(val) == string(tc.val), "wrong value")
+
+	}
+
+	_, _, err = pack.GetRaw(cipher.SHA256{1, 2, 3})
+	assertTrue(t, err == registry.ErrNotFound, "missing ErrNotFound")
+
+}
+
+func TestPack_Get_closed(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+	}
+	assertNil(t, c.Save(up, r))
+	assertNil(t, up.Close())
+
+	var pack *Pack
+	pack, err = c.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	assertNil(t, c.Close())
+
+	_, err = pack.Get(r.Refs[0].Hash)
+	assertTrue(t, err == data.ErrClosed, fmt.Sprint("want ErrClosed, got ", err))
+
+}
+
+func TestPack_MaxTreeDepth(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.MaxTreeDepth = 3
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var _, sk = cipher.GenerateKeyPair()
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	// a normal tree under the limit
+
+	assertNil(t, up.SetDegree(2))
+
+	var posts registry.Refs
+	for i := 0; i < 5; i++ {
+		var post = Post{Head: fmt.Sprint("head ", i)}
+		assertNil(t, posts.AppendValues(up, &post))
+	}
+
+	var depth int
+	depth, err = posts.Depth(up)
+	assertNil(t, err)
+	assertTrue(t, depth == 3, fmt.Sprint("wrong depth ", depth))
+
+	var loaded = registry.Refs{Hash: posts.Hash}
+	var ln int
+	ln, err = loaded.Len(up)
+	assertNil(t, err)
+	assertTrue(t, ln == 5, "wrong length")
+
+	// synthetic over-deep tree (encoded Refs)
+
+	var er = struct {
+		Depth    uint32
+		Degree   uint32
+		Length   uint32
+		Elements []cipher.SHA256
+	}{
+		Depth:    1000000,
+		Degree:   2,
+		Length:   1,
+		Elements: []cipher.SHA256{cipher.SumSHA256([]byte("leaf"))},
+	}
+
+	var hash cipher.SHA256
+	hash, err = up.Add(encoder.Serialize(er))
+	assertNil(t, err)
+
+	var deep = registry.Refs{Hash: hash}
+	_, err = deep.Len(up)
+
+	var tde, ok = err.(*registry.TreeTooDeepError)
+	assertTrue(t, ok == true, fmt.Sprint("unexpected error: ", err))
+	assertTrue(t, tde.Depth() == 1000001, "wrong depth")
+	assertTrue(t, tde.Max() == 3, "wrong max")
+	assertTrue(t, tde.Hash() == hash, "wrong hash")
+
+	// the same tree through Walk
+
+	var r = new(registry.Root)
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &Feed{Posts: deep}),
+	}
+
+	err = r.Walk(up, func(cipher.SHA256, int) (bool, error) {
+		return true, nil
+	})
+
+	tde, ok = err.(*registry.TreeTooDeepError)
+	assertTrue(t, ok == true, fmt.Sprint("unexpected error of Walk: ", err))
+	assertTrue(t, tde.Hash() == hash, "wrong hash")
+
+}
+
+func TestPack_ReplaceRegistry(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		alice  = User{"Alice", 19}
+		post   = Post{Head: "head", Body: "body"}
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.Post", &post),
+		createDynamic(up, testRegistry, "test.Feed", &Feed{Head: "feed"}),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	// incompatible: test.User has another structure
+
+	type User struct {
+		Name string
+		Age  uint32
+		Bio  string
+	}
+
+	var changed = registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", User{})
+		r.Register("test.Post", Post{})
+		r.Register("test.Feed", Feed{})
+	})
+
+	err = pack.ReplaceRegistry(changed)
+
+	var ise, ok = err.(*IncompatibleSchemasError)
+	assertTrue(t, ok == true, fmt.Sprint("unexpected error: ", err))
+	assertTrue(t, len(ise.Names()) == 1 && ise.Names()[0] == "test.User",
+		fmt.Sprint("wrong names ", ise.Names()))
+
+	// incompatible: test.Post used by the test.Feed (nested)
+
+	type Post struct {
+		Head  string
+		Body  string
+		Likes uint32
+	}
+
+	var nested = registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", User{})
+		r.Register("test.Post", Post{})
+		r.Register("test.Feed", Feed{})
+	})
+
+	err = pack.ReplaceRegistry(nested)
+
+	ise, ok = err.(*IncompatibleSchemasError)
+	assertTrue(t, ok == true, fmt.Sprint("unexpected error: ", err))
+	assertTrue(t, len(ise.Names()) == 2 &&
+		ise.Names()[0] == "test.User" && ise.Names()[1] == "test.Post",
+		fmt.Sprint("wrong names ", ise.Names()))
+
+	assertTrue(t, pack.Registry() == testRegistry, "registry replaced")
+	assertTrue(t, r.Hash != (cipher.SHA256{}), "Root changed")
+
+	// compatible: the same schemas and a new one
+
+	type Comment struct {
+		Text string
+	}
+
+	var compatible = registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", alice)
+		r.Register("test.Post", post)
+		r.Register("test.Feed", Feed{})
+		r.Register("test.Comment", Comment{})
+	})
+
+	assertNil(t, pack.ReplaceRegistry(compatible))
+	assertTrue(t, pack.Registry() == compatible, "registry not replaced")
+	assertTrue(t, r.Reg == compatible.Reference(), "wrong Root.Reg")
+
+	assertTrue(t, r.Hash == (cipher.SHA256{}), "Hash is not cleared")
+	assertTrue(t, r.Sig == (cipher.Sig{}), "Sig is not cleared")
+
+	var names []string
+	names, err = pack.RefSchemas()
+	assertNil(t, err)
+	assertTrue(t, len(names) == 3, "wrong length")
+	assertTrue(t, names[0] == "test.User" && names[1] == "test.Post",
+		fmt.Sprint("wrong names ", names))
+
+	var usr = alice
+	usr.Name = ""
+	assertNil(t, r.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr == alice, "wrong value")
+
+	// incompatible: missing test.Post and test.Feed
+
+	var incompatible = registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", alice)
+	})
+
+	err = pack.ReplaceRegistry(incompatible)
+
+	var mse *MissingSchemasError
+	mse, ok = err.(*MissingSchemasError)
+	assertTrue(t, ok == true, fmt.Sprint("unexpected error: ", err))
+	assertTrue(t, len(mse.Names()) == 2 &&
+		mse.Names()[0] == "test.Post" && mse.Names()[1] == "test.Feed",
+		fmt.Sprint("wrong names ", mse.Names()))
+	assertTrue(t, strings.Contains(err.Error(), "test.Post"), "wrong message")
+
+	assertTrue(t, pack.Registry() == compatible, "registry replaced")
+	assertTrue(t, r.Reg == compatible.Reference(), "Root.Reg changed")
+
+}
+
+func TestPack_ReplaceRegistry_nestedDynamic(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		reg = registry.NewRegistry(func(r *registry.Reg) {
+			r.Register("test.Chain", Chain{})
+			r.Register("test.User", User{})
+		})
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, reg)
+	assertNil(t, err)
+	defer up.Close()
+
+	// the test.User is used through the Next field only
+
+	var chain = Chain{
+		Name: "chain",
+		Next: createDynamic(up, reg, "test.User", &User{"Alice", 19}),
+	}
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, reg, "test.Chain", &chain),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, reg)
+	assertNil(t, err)
+
+	var noUser = registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.Chain", Chain{})
+	})
+
+	err = pack.ReplaceRegistry(noUser)
+
+	var mse, ok = err.(*MissingSchemasError)
+	assertTrue(t, ok == true, fmt.Sprint("unexpected error: ", err))
+	assertTrue(t, len(mse.Names()) == 1 && mse.Names()[0] == "test.User",
+		fmt.Sprint("wrong names ", mse.Names()))
+
+	assertTrue(t, pack.Registry() == reg, "registry replaced")
+
+}
+
+func TestContainer_PackFromRoot(t *testing.T) {
+
+	var c = getTestContainer()
+	defer c.Close()
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		eva   = User{"Eva", 21}
+	)
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Reg = testRegistry.Reference()
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.User", &eva),
+	}
+
+	// the Registry should be in DB
+	_, err = up.Add(testRegistry.Encode())
+	assertNil(t, err)
+
+	var pack *Pack
+	pack, err = c.PackFromRoot(r.Encode(), registry.EntireRefs)
+	assertNil(t, err)
+
+	assertTrue(t, pack.Flags()&registry.ViewOnly != 0, "not view only")
+	assertTrue(t, pack.Flags()&registry.EntireRefs != 0, "missing flag")
+	assertTrue(t, pack.Root().Hash == cipher.SumSHA256(r.Encode()),
+		"wrong hash")
+
+	// walk
+
+	var walked []cipher.SHA256
+	err = pack.Root().Walk(pack, func(
+		hash cipher.SHA256,
+		_ int,
+	) (
+		deepper bool,
+		_ error,
+	) {
+		walked = append(walked, hash)
+		return true, nil
+	})
+	assertNil(t, err)
+
+	assertTrue(t, len(walked) == 2, "wrong number of objects walked")
+	assertTrue(t, walked[0] == r.Refs[0].Hash && walked[1] == r.Refs[1].Hash,
+		"wrong objects walked")
+
+	var usr User
+	assertNil(t, pack.Root().Refs[1].Value(pack, &usr))
+	assertTrue(t, usr == eva, "wrong value")
+
+	// missing object turns not-found on demand
+
+	r.Refs = append(r.Refs, registry.Dynamic{
+		Schema: r.Refs[0].Schema,
+		Hash:   cipher.SumSHA256([]byte("missing")),
+	})
+
+	pack, err = c.PackFromRoot(r.Encode(), 0)
+	assertNil(t, err)
+
+	err = pack.Root().Refs[2].Value(pack, &usr)
+	assertTrue(t, err == data.ErrNotFound, fmt.Sprint("unexpected error: ", err))
+
+	// invalid Root
+
+	_, err = c.PackFromRoot([]byte("invalid"), 0)
+	assertTrue(t, err != nil, "missing error")
+
+	r.Reg = registry.RegistryRef{}
+	_, err = c.PackFromRoot(r.Encode(), 0)
+	assertTrue(t, err == ErrBlankRegistryRef, "wrong error")
+
+}
+
+func TestPack_Touch(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var old = r.Refs[0].Hash
+
+	// dereference and change a copy
+
+	var usr User
+	assertNil(t, r.Refs[0].Value(up, &usr))
+
+	usr.Age = 20
+
+	// out of range (the Unpack has not a Root)
+
+	assertTrue(t, up.Touch(-1, &usr) == registry.ErrIndexOutOfRange,
+		"missing ErrIndexOutOfRange")
+	assertNil(t, up.Touch(1, &usr))
+	assertTrue(t, c.Save(up, r) == registry.ErrIndexOutOfRange,
+		"missing ErrIndexOutOfRange")
+
+	up.touched = nil
+
+	// not registered type
+
+	assertTrue(t, up.Touch(0, 10) != nil, "missing error")
+
+	// touched
+
+	assertNil(t, up.Touch(0, &usr))
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Refs[0].Hash != old, "not changed")
+
+	var pack *Pack
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	var lr *registry.Root
+	lr, err = c.LastRoot(pk, 1)
+	assertNil(t, err)
+
+	assertNil(t, lr.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr == User{"Alice", 20}, "change is not saved")
+
+	// the old value is not changed (immutable)
+
+	var val, ok = up.Value(old, reflect.TypeOf(usr))
+	assertTrue(t, ok == true, "value is not kept")
+	assertTrue(t, val.Interface().(User) == alice, "kept value changed")
+
+	var oval []byte
+	oval, _, err = c.Get(old, 0)
+	assertNil(t, err)
+	assertNil(t, encoder.DeserializeRaw(oval, &usr))
+	assertTrue(t, usr == alice, "old object changed")
+
+	// a Pack can't save changes
+
+	pack, err = c.Pack(lr, testRegistry)
+	assertNil(t, err)
+
+	assertTrue(t, pack.Touch(0, &usr) == ErrViewOnlyTree,
+		"missing ErrViewOnlyTree")
+
+}
+
+func TestPack_AppendUnique(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	for _, flags := range []registry.Flags{0, registry.HashTableIndex} {
+
+		up.ClearFlags(registry.HashTableIndex)
+		up.AddFlags(flags)
+
+		var (
+			refs  registry.Refs
+			added bool
+			ln    int
+		)
+
+		for i, post := range []Post{{"Head", "Body"}, {"Head", "Body"}} {
+
+			added, err = up.AppendUnique(&refs, &post)
+			assertNil(t, err)
+			assertTrue(t, added == (i == 0), fmt.Sprint("wrong added ", i))
+
+			ln, err = refs.Len(up)
+			assertNil(t, err)
+			assertTrue(t, ln == 1, fmt.Sprint("wrong length ", ln))
+
+		}
+
+		added, err = up.AppendUnique(&refs, &Post{"Other", "Body"})
+		assertNil(t, err)
+		assertTrue(t, added == true, "not added")
+
+		ln, err = refs.Len(up)
+		assertNil(t, err)
+		assertTrue(t, ln == 2, fmt.Sprint("wrong length ", ln))
+
+		// save
+
+		var r = new(registry.Root)
+		r.Pub, r.Nonce = pk, 1
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.Feed", &Feed{Posts: refs}),
+		}
+		assertNil(t, c.Save(up, r))
+
+	}
+
+}
+
+func TestPack_Get_onMissing(t *testing.T) {
+
+	var (
+		conf   = getTestConfig()
+		remote = make(map[cipher.SHA256][]byte)
+		calls  int
+
+		_, sk = cipher.GenerateKeyPair()
+	)
+
+	conf.OnMissing = func(key cipher.SHA256) (val []byte, err error) {
+		calls++
+		var ok bool
+		if val, ok = remote[key]; ok == false {
+			err = data.ErrNotFound
+		}
+		return
+	}
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var pack *Pack
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	var (
+		val = []byte("remote value")
+		key = cipher.SumSHA256(val)
+	)
+
+	// the callback fails
+
+	_, err = pack.Get(key)
+	assertTrue(t, err == data.ErrNotFound, "missing ErrNotFound")
+	assertTrue(t, calls == 1, "callback not called")
+
+	// the callback supplies the value
+
+	remote[key] = val
+
+	var got []byte
+	got, err = pack.Get(key)
+	assertNil(t, err)
+	assertTrue(t, string(got) == string(val), "wrong value")
+	assertTrue(t, calls == 2, "callback not called")
+
+	// kept by the Pack, but not stored
+
+	_, _, err = c.db.CXDS().Get(key, 0)
+	assertTrue(t, err == data.ErrNotFound, "stored by a Pack")
+
+	_, err = pack.Get(key)
+	assertNil(t, err)
+	assertTrue(t, calls == 2, "callback called for kept value")
+
+	// stored by an Unpack as unsaved object
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	got, err = up.Get(key)
+	assertNil(t, err)
+	assertTrue(t, string(got) == string(val), "wrong value")
+	assertTrue(t, calls == 3, "callback not called")
+
+	got, _, err = c.Get(key, 0)
+	assertNil(t, err)
+	assertTrue(t, string(got) == string(val), "wrong value")
+
+	_, err = up.Get(key)
+	assertNil(t, err)
+	assertTrue(t, calls == 3, "callback called for existing value")
+
+	// collectible, since not used by a saved Root
+
+	assertNil(t, up.Close())
+
+	var rc uint32
+	_, rc, err = c.db.CXDS().Get(key, 0)
+	assertNil(t, err)
+	assertTrue(t, rc == 0, "unsaved object is not collectible")
+
+	// value of another hash
+
+	var other = cipher.SumSHA256([]byte("other"))
+	remote[other] = val
+
+	_, err = pack.Get(other)
+	assertTrue(t, err == data.ErrNotFound, "missing ErrNotFound")
+
+}
+
+func TestPack_Find(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var posts registry.Refs
+	assertNil(t, posts.AppendValues(up,
+		&Post{"cxo", "one"},
+		&Post{"go", "two"},
+		&Post{"cxo", "three"},
+	))
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+		createDynamic(up, testRegistry, "test.Feed", &Feed{Posts: posts}),
+		createDynamic(up, testRegistry, "test.User", &User{"Bob", 21}),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	var found []cipher.SHA256
+	found, err = pack.Find(func(schema string, val []byte) bool {
+
+		if schema != "test.Post" {
+			return false
+		}
+
+		var post Post
+		assertNil(t, c.Codec().Unmarshal(val, &post))
+		return post.Head == "cxo"
+	})
+	assertNil(t, err)
+
+	assertTrue(t, len(found) == 2, fmt.Sprint("wrong found: ", len(found)))
+
+	for i, body := range []string{"one", "three"} {
+		var post Post
+		assertNil(t, up.Codec().Unmarshal(mustGet(t, pack, found[i]), &post))
+		assertTrue(t, post.Body == body, "wrong post")
+	}
+
+	// users older then 20
+
+	found, err = pack.Find(func(schema string, val []byte) bool {
+		var usr User
+		return schema == "test.User" &&
+			c.Codec().Unmarshal(val, &usr) == nil && usr.Age > 20
+	})
+	assertNil(t, err)
+
+	assertTrue(t, len(found) == 1, fmt.Sprint("wrong found: ", len(found)))
+	assertTrue(t, found[0] == r.Refs[2].Hash, "wrong user")
+
+	// without Root
+
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	_, err = pack.Find(func(string, []byte) bool { return true })
+	assertTrue(t, err == ErrPackWithoutRoot, "missing ErrPackWithoutRoot")
+
+}
+
+func mustGet(t *testing.T, pack *Pack, key cipher.SHA256) (val []byte) {
+	var err error
+	val, err = pack.Get(key)
+	assertNil(t, err)
+	return
+}
+
+func TestPack_RootHash(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	var pack *Pack
+	pack, err = c.Pack(r, testRegistry)
+	assertNil(t, err)
+
+	r.Time = time.Now().UnixNano()
+
+	var unsaved cipher.SHA256
+	unsaved, err = pack.RootHash()
+	assertNil(t, err)
+
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, unsaved == r.Hash, "RootHash doesn't match hash of the Save")
+
+	var hash cipher.SHA256
+	hash, err = pack.RootHash()
+	assertNil(t, err)
+	assertTrue(t, hash == r.Hash, "RootHash doesn't match hash of saved Root")
+
+	// append (the next seq)
+
+	r.Refs = append(r.Refs,
+		createDynamic(up, testRegistry, "test.User", &User{"Bob", 21}))
+	r.Time++
+
+	var appended cipher.SHA256
+	appended, err = pack.RootHash()
+	assertNil(t, err)
+	assertTrue(t, appended != hash, "RootHash doesn't reflect changes")
+	assertTrue(t, r.Hash == hash, "the Root changed")
+
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, appended == r.Hash, "RootHash doesn't match hash of the Save")
+	assertTrue(t, r.Seq == 1, "wrong seq")
+
+	hash, err = pack.RootHash()
+	assertNil(t, err)
+	assertTrue(t, hash == r.Hash, "RootHash doesn't match hash of saved Root")
+
+	// without Root
+
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	_, err = pack.RootHash()
+	assertTrue(t, err == ErrPackWithoutRoot, "missing ErrPackWithoutRoot")
+
+}
+
+func TestPack_ChildHashes(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		bob   = User{"Bob", 21}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		{}, // blank
+		createDynamic(up, testRegistry, "test.User", &bob),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	var hashes []cipher.SHA256
+	hashes, err = pack.ChildHashes()
+	assertNil(t, err)
+
+	assertTrue(t, len(hashes) == 2, "wrong number of hashes")
+
+	for i, usr := range []User{alice, bob} {
+		var val []byte
+		val, _, err = c.Get(hashes[i], 0)
+		assertNil(t, err)
+		assertTrue(t, hashes[i] == cipher.SumSHA256(encoder.Serialize(usr)),
+			"wrong hash")
+		assertTrue(t, hashes[i] == cipher.SumSHA256(val),
+			"hash doesn't match stored object")
+	}
+
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	_, err = pack.ChildHashes()
+	assertTrue(t, err == ErrPackWithoutRoot, "missing ErrPackWithoutRoot")
+
+}
+
+func TestPack_Validate(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		bob   = User{"Bob", 21}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.User", &bob),
+	}
+
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		var errs = pack.Validate()
+		assertTrue(t, len(errs) == 0, "unexpected errors")
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+
+		// string length is greater than the value
+		var bad cipher.SHA256
+		bad, err = up.Add([]byte{0xff, 0x00, 0x00, 0x00, 'x'})
+		assertNil(t, err)
+
+		var dr = r.Refs[1]
+		dr.Hash = bad
+		r.Refs = append(r.Refs, dr)
+
+		assertNil(t, c.Save(up, r))
+
+		pack, err = c.Pack(r, nil)
+		assertNil(t, err)
+
+		var errs = pack.Validate()
+		assertTrue(t, len(errs) == 1, "wrong number of errors")
+
+		var de, ok = errs[0].(*DecodingError)
+		assertTrue(t, ok == true, "wrong type of error")
+		assertTrue(t, de.Hash() == bad, "wrong hash")
+	})
+
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	var errs = pack.Validate()
+	assertTrue(t, len(errs) == 1 && errs[0] == ErrPackWithoutRoot,
+		"missing or wrong error")
+
+}
+
+func TestPack_Manifest(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var (
+		first  = Post{"first", "hello"}
+		second = Post{"second", "world"}
+
+		news  = Feed{Head: "news", Info: "daily"}
+		blogs = Feed{Head: "blogs", Info: "weekly"}
+	)
+
+	// the first Post is shared
+
+	assertNil(t, news.Posts.AppendValues(up, &first, &second))
+	assertNil(t, blogs.Posts.AppendValues(up, &first))
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.Feed", &news),
+		createDynamic(up, testRegistry, "test.Feed", &blogs),
+		createDynamic(up, testRegistry, "test.Feed", &news), // shared
+		{}, // blank
+	}
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	var hashes []cipher.SHA256
+	hashes, err = pack.Manifest()
+	assertNil(t, err)
+
+	var set = make(map[cipher.SHA256]int)
+	for i, hash := range hashes {
+		_, ok := set[hash]
+		assertTrue(t, ok == false, "duplicate hash "+hash.Hex()[:7])
+		set[hash] = i
+		if i > 0 {
+			assertTrue(t, hash.Hex() > hashes[i-1].Hex(), "not sorted")
+		}
+	}
+
+	_, ok := set[cipher.SHA256{}]
+	assertTrue(t, ok == false, "blank hash in the manifest")
+
+	for _, want := range []cipher.SHA256{
+		r.Refs[0].Hash,
+		r.Refs[1].Hash,
+		cipher.SumSHA256(encoder.Serialize(&first)),
+		cipher.SumSHA256(encoder.Serialize(&second)),
+	} {
+		_, ok = set[want]
+		assertTrue(t, ok == true, "missing hash "+want.Hex()[:7])
+	}
+
+	// deterministic
+
+	var again []cipher.SHA256
+	again, err = pack.Manifest()
+	assertNil(t, err)
+	assertTrue(t, reflect.DeepEqual(hashes, again), "different manifests")
+
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	_, err = pack.Manifest()
+	assertTrue(t, err == ErrPackWithoutRoot, "missing or wrong error")
+
+}
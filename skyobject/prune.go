@@ -0,0 +1,154 @@
+package skyobject
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// a Root to prune
+type pruneRoot struct {
+	nonce uint64
+	*data.Root
+}
+
+// PruneRoots removes all Root objects of given feed except
+// latest 'keep' Root objects. Last Root of every head is
+// never pruned, even if it is outside the keep window,
+// since the head is represented by it. The PruneRoots
+// removes objects used only by the pruned Root objects
+// (objects with rc == 0). Objects used by other Root
+// objects are kept. The PruneRoots returns number of
+// removed Root objects
+func (c *Container) PruneRoots(
+	pk cipher.PubKey, // : feed
+	keep int, //         : Root objects to keep
+) (
+	removed int, //      : removed Root objects
+	err error, //        : an error
+) {
+
+	if c.conf.ReadOnly == true {
+		return 0, ErrViewOnlyTree
+	}
+
+	if keep < 0 {
+		return 0, errors.New("negative keep argument of PruneRoots")
+	}
+
+	var heads []uint64
+	if heads, err = c.Heads(pk); err != nil {
+		return
+	}
+
+	// all Root objects of the feed
+
+	var rs []pruneRoot
+
+	err = c.db.IdxDB().Tx(func(feeds data.Feeds) (err error) {
+
+		var hs data.Heads
+		if hs, err = feeds.Heads(pk); err != nil {
+			return
+		}
+
+		for _, nonce := range heads {
+
+			var roots data.Roots
+			if roots, err = hs.Roots(nonce); err != nil {
+				return
+			}
+
+			err = roots.Ascend(func(dr *data.Root) (_ error) {
+				var cp = *dr // copy
+				rs = append(rs, pruneRoot{nonce, &cp})
+				return
+			})
+
+			if err != nil {
+				return
+			}
+
+		}
+
+		return
+	})
+
+	if err != nil || len(rs) <= keep {
+		return
+	}
+
+	// latest first
+
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].Time == rs[j].Time {
+			return rs[i].Seq > rs[j].Seq
+		}
+		return rs[i].Time > rs[j].Time
+	})
+
+	var objects []cipher.SHA256 // objects of pruned Root objects
+
+	for _, pr := range rs[keep:] {
+
+		var last uint64
+		if last, err = c.LastRootSeq(pk, pr.nonce); err != nil {
+			return
+		}
+
+		if last == pr.Seq {
+			continue // keep last Root of the head
+		}
+
+		if objects, err = c.pruneRootObjects(pr.Hash, objects); err != nil {
+			return
+		}
+
+		if err = c.DelRoot(pk, pr.nonce, pr.Seq); err != nil {
+			return
+		}
+
+		removed++
+
+	}
+
+	// remove objects that are not used anymore
+
+	for _, hash := range objects {
+
+		switch err = c.DelObject(hash); err {
+		case nil, ErrObjectIsUsed, data.ErrNotFound:
+		default:
+			return
+		}
+
+	}
+
+	return removed, nil
+}
+
+// append all objects of Root with given hash to given list
+func (c *Container) pruneRootObjects(
+	rootHash cipher.SHA256, //  : hash of the Root
+	objects []cipher.SHA256, // : list to append to
+) (
+	_ []cipher.SHA256, //       : the list
+	err error, //               : an error
+) {
+
+	var r *registry.Root
+	if r, err = c.rootByHash(rootHash); err != nil {
+		return objects, err
+	}
+
+	err = c.Walk(r, func(hash cipher.SHA256, _ int) (bool, error) {
+		objects = append(objects, hash)
+		return true, nil
+	})
+
+	return objects, err
+}
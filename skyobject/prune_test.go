@@ -0,0 +1,101 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_PruneRoots(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		shared = User{"Shared", 100}
+		users  = []User{
+			{"Alice", 19},
+			{"Bob", 20},
+			{"Eva", 21},
+			{"Tom", 22},
+			{"Ann", 23},
+		}
+		other = User{"Other", 30}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var save = func(nonce uint64, usr User) {
+		var r = new(registry.Root)
+		r.Pub, r.Nonce = pk, nonce
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User", &shared),
+			createDynamic(up, testRegistry, "test.User", &usr),
+		}
+		assertNil(t, c.Save(up, r))
+	}
+
+	var has = func(usr User) bool {
+		var _, _, err = c.Get(cipher.SumSHA256(encoder.Serialize(usr)), 0)
+		if err != nil && err != data.ErrNotFound {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	// the oldest Root is last Root of another head
+
+	save(2, other)
+
+	for _, usr := range users {
+		save(1, usr)
+	}
+
+	_, err = c.PruneRoots(pk, -1)
+	assertTrue(t, err != nil, "missing error")
+
+	var removed int
+	removed, err = c.PruneRoots(pk, 2)
+	assertNil(t, err)
+	assertTrue(t, removed == 3, "wrong number of pruned Root objects")
+
+	for seq := uint64(0); seq < 3; seq++ {
+		_, err = c.Root(pk, 1, seq)
+		assertTrue(t, err == data.ErrNotFound, "Root is not pruned")
+	}
+
+	for seq := uint64(3); seq < 5; seq++ {
+		_, err = c.Root(pk, 1, seq)
+		assertNil(t, err)
+	}
+
+	_, err = c.Root(pk, 2, 0)
+	assertNil(t, err)
+
+	// objects
+
+	for _, usr := range users[:3] {
+		assertTrue(t, has(usr) == false, "object of pruned Root is not removed")
+	}
+
+	for _, usr := range append(users[3:], shared, other) {
+		assertTrue(t, has(usr) == true, "used object is removed")
+	}
+
+	// nothing to prune
+
+	removed, err = c.PruneRoots(pk, 2)
+	assertNil(t, err)
+	assertTrue(t, removed == 0, "wrong number of pruned Root objects")
+
+}
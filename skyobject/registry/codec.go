@@ -0,0 +1,375 @@
+package registry
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A Codec used to encode and decode values of objects
+// (see Ref, Refs and Dynamic). Hashes of objects depend
+// on encoded values, thus the same object encoded by
+// different codecs has different hashes. A Codec is not
+// used for internal structures such as Root, Registry
+// and nodes of the Refs. They are always encoded by the
+// skycoin encoder. Name of the Codec is part of the
+// Registry (see UseCodec method of the Reg).
+//
+// Walking through objects (and saving and filling) uses
+// Schema of an object to find references inside. If a
+// Codec is not the DefaultCodec, then the walking decodes
+// an object using the Codec to registered Go type of its
+// Schema. Thus, the Registry must be created from Go
+// types (see Types) to walk objects encoded by another
+// Codec
+type Codec interface {
+	Name() string                                      // unique name
+	Marshal(obj interface{}) (val []byte, err error)   // encode
+	Unmarshal(val []byte, obj interface{}) (err error) // decode
+}
+
+// A CodecPack is Pack that has its own Codec.
+// If a Pack doesn't implement this interface,
+// then the DefaultCodec used
+type CodecPack interface {
+	Pack
+	Codec() Codec // codec of the Pack
+}
+
+// DefaultCodec is the skycoin encoder
+var DefaultCodec Codec = skyencoder{}
+
+type skyencoder struct{}
+
+// Name implements Codec interface
+func (skyencoder) Name() string {
+	return "skyencoder"
+}
+
+// Marshal implements Codec interface. It returns
+// *MapTypeError if given value contains a map
+func (skyencoder) Marshal(obj interface{}) (val []byte, err error) {
+
+	if typ := reflect.TypeOf(obj); typ != nil && hasMap(typ) == true {
+		return nil, &MapTypeError{typ}
+	}
+
+	return encoder.Serialize(obj), nil
+}
+
+// Unmarshal implements Codec interface
+func (skyencoder) Unmarshal(val []byte, obj interface{}) (err error) {
+	return encoder.DeserializeRaw(val, obj)
+}
+
+// types checked by the hasMap
+var mapTypes struct {
+	mx sync.Mutex
+	ts map[reflect.Type]bool
+}
+
+// hasMap returns true if given type is map or it
+// contains a map (e.g. a field of a struct)
+func hasMap(typ reflect.Type) (yep bool) {
+
+	mapTypes.mx.Lock()
+	defer mapTypes.mx.Unlock()
+
+	var ok bool
+
+	if yep, ok = mapTypes.ts[typ]; ok == true {
+		return
+	}
+
+	if mapTypes.ts == nil {
+		mapTypes.ts = make(map[reflect.Type]bool)
+	}
+
+	yep = hasMapType(typ, make(map[reflect.Type]struct{}))
+	mapTypes.ts[typ] = yep
+	return
+}
+
+func hasMapType(
+	typ reflect.Type, //                : type to check
+	seen map[reflect.Type]struct{}, //  : recursive types
+) (
+	yep bool, //                        : has a map
+) {
+
+	if _, ok := seen[typ]; ok == true {
+		return
+	}
+
+	seen[typ] = struct{}{}
+
+	switch typ.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasMapType(typ.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			var sf = typ.Field(i)
+			if sf.Tag.Get("enc") != "-" && hasMapType(sf.Type, seen) == true {
+				return true
+			}
+		}
+	}
+
+	return
+}
+
+// codec of given pack or Splitter
+func codecOf(pack interface{}) (codec Codec) {
+	if cp, ok := pack.(interface{ Codec() Codec }); ok == true {
+		if codec = cp.Codec(); codec != nil {
+			return
+		}
+	}
+	return DefaultCodec
+}
+
+// SchemaData converts given value encoded by Codec of
+// given Pack to value encoded by the skycoin encoder.
+// Schema describes values encoded by the skycoin encoder
+// only. The SchemaData returns given value if the Codec
+// is the DefaultCodec. Otherwise, the value is decoded to
+// registered Go type of given Schema. It returns
+// ErrTypeNotFound if the Registry of the Pack has not the
+// type (see Types). Use CodecData for reverse conversion
+func SchemaData(
+	pack Pack, //   : pack with Codec and Registry
+	sch Schema, //  : Schema of the value
+	val []byte, //  : value encoded by the Codec
+) (
+	data []byte, // : value encoded by the skycoin encoder
+	err error, //   : an error
+) {
+	return schemaData(codecOf(pack), pack.Registry(), sch, val)
+}
+
+// CodecData is reverse for the SchemaData. It converts
+// value encoded by the skycoin encoder to value encoded
+// by Codec of given Pack
+func CodecData(
+	pack Pack, //   : pack with Codec and Registry
+	sch Schema, //  : Schema of the value
+	data []byte, // : value encoded by the skycoin encoder
+) (
+	val []byte, //  : value encoded by the Codec
+	err error, //   : an error
+) {
+
+	var codec = codecOf(pack)
+
+	if codec.Name() == DefaultCodec.Name() {
+		return data, nil
+	}
+
+	var pv reflect.Value
+	if pv, err = newOfSchema(pack.Registry(), sch); err != nil {
+		return
+	}
+
+	if err = encoder.DeserializeRaw(data, pv.Interface()); err != nil {
+		return
+	}
+
+	return codec.Marshal(pv.Elem().Interface())
+}
+
+func schemaData(
+	codec Codec, //   : the Codec
+	reg *Registry, // : registered types
+	sch Schema, //    : Schema of the value
+	val []byte, //    : value encoded by the Codec
+) (
+	data []byte, //   : value encoded by the skycoin encoder
+	err error, //     : an error
+) {
+
+	if codec.Name() == DefaultCodec.Name() {
+		return val, nil
+	}
+
+	var pv reflect.Value
+	if pv, err = newOfSchema(reg, sch); err != nil {
+		return
+	}
+
+	if err = codec.Unmarshal(val, pv.Interface()); err != nil {
+		return
+	}
+
+	return encoder.Serialize(pv.Elem().Interface()), nil
+}
+
+// pointer to new value of registered
+// Go type of given Schema
+func newOfSchema(reg *Registry, sch Schema) (pv reflect.Value, err error) {
+
+	var typ, ok = reg.nt[sch.Name()]
+
+	if ok == false {
+		return reflect.Value{}, ErrTypeNotFound
+	}
+
+	return reflect.New(typ), nil
+}
+
+// encode and add value to given Pack using Codec of the Pack
+func addValue(
+	pack Pack, //          : pack to save
+	obj interface{}, //    : the value
+) (
+	hash cipher.SHA256, // : hash of the encoded value
+	err error, //          : encoding or saving error
+) {
+
+	var val []byte
+	if val, err = codecOf(pack).Marshal(obj); err != nil {
+		return
+	}
+
+	return pack.Add(val)
+}
+
+// get by hash from the Pack and deocde to given pointer (obj)
+// using Codec of the Pack
+func getValue(
+	pack Pack, //          : pack to get from
+	hash cipher.SHA256, // : hash of the object
+	obj interface{}, //    : pointer to object
+) (
+	err error, //          : getting or decoding error
+) {
+
+	var val []byte
+
+	if val, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	return codecOf(pack).Unmarshal(val, obj)
+}
+
+// get from the ValuePack a kept value, or get by hash from
+// the ValuePack and deocde to given pointer (obj) keeping
+// the decoded value; the kept value is copied deeply both
+// ways, thus it can't be changed through the obj
+func getKeptValue(
+	pack ValuePack, //     : pack to get from
+	hash cipher.SHA256, // : hash of the object
+	obj interface{}, //    : pointer to object
+) (
+	err error, //          : getting or decoding error
+) {
+
+	var pv = reflect.ValueOf(obj)
+
+	if pv.Kind() != reflect.Ptr || pv.IsNil() == true {
+		return getValue(pack, hash, obj) // let the codec report the error
+	}
+
+	var el = pv.Elem()
+
+	if val, ok := pack.Value(hash, el.Type()); ok == true {
+		el.Set(CopyValue(val))
+		return
+	}
+
+	if err = getValue(pack, hash, obj); err != nil {
+		return
+	}
+
+	pack.KeepValue(hash, CopyValue(el))
+	return
+}
+
+// CopyValue returns deep copy of given value. Slices, maps
+// and pointers of the copy don't share memory with the value.
+// Unexported fields of structures are copied shallowly. A
+// ValuePack keeps and hands out such copies
+func CopyValue(val reflect.Value) (cp reflect.Value) {
+	cp = reflect.New(val.Type()).Elem()
+	copyValue(cp, val)
+	return
+}
+
+// copy src to settable dst deeply
+func copyValue(dst, src reflect.Value) {
+
+	switch src.Kind() {
+
+	case reflect.Ptr:
+
+		if src.IsNil() == false {
+			dst.Set(reflect.New(src.Type().Elem()))
+			copyValue(dst.Elem(), src.Elem())
+		}
+
+	case reflect.Interface:
+
+		if src.IsNil() == false {
+			var val = reflect.New(src.Elem().Type()).Elem()
+			copyValue(val, src.Elem())
+			dst.Set(val)
+		}
+
+	case reflect.Slice:
+
+		if src.IsNil() == true {
+			return
+		}
+
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+
+		if src.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(dst, src) // bytes
+			return
+		}
+
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Array:
+
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Map:
+
+		if src.IsNil() == true {
+			return
+		}
+
+		dst.Set(reflect.MakeMap(src.Type()))
+
+		for _, key := range src.MapKeys() {
+			var val = reflect.New(src.Type().Elem()).Elem()
+			copyValue(val, src.MapIndex(key))
+			dst.SetMapIndex(key, val)
+		}
+
+	case reflect.Struct:
+
+		dst.Set(src) // unexported fields
+
+		for i := 0; i < src.NumField(); i++ {
+			if fl := dst.Field(i); fl.CanSet() == true {
+				copyValue(fl, src.Field(i))
+			}
+		}
+
+	default:
+
+		dst.Set(src)
+
+	}
+
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/skycoin/skycoin/src/cipher"
-	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
 // A Dynamic represents reference to object
@@ -43,7 +42,12 @@ func (d *Dynamic) IsBlank() bool {
 }
 
 // Value of the Dynamic. The obj argument
-// must be a non-nil pointer
+// must be a non-nil pointer. The Value returns
+// *SchemaNotRegisteredError if Registry of
+// given Pack doesn't have Schema of the Dynamic.
+// If given Pack implements the ValuePack interface,
+// then decoded value is kept by the Pack (see
+// Value method of the Ref)
 func (d *Dynamic) Value(
 	pack Pack, //       : pack to get
 	obj interface{}, // : pointer to object to decode to
@@ -59,7 +63,66 @@ func (d *Dynamic) Value(
 		return ErrReferenceRepresentsNil
 	}
 
-	return get(pack, d.Hash, obj)
+	var reg *Registry
+	if reg = pack.Registry(); reg == nil {
+		return ErrMissingRegistry
+	}
+
+	if _, err = reg.SchemaByReference(d.Schema); err != nil {
+		return // *SchemaNotRegisteredError
+	}
+
+	if vp, ok := pack.(ValuePack); ok == true {
+		return getKeptValue(vp, d.Hash, obj)
+	}
+
+	return getValue(pack, d.Hash, obj)
+}
+
+// SchemaName returns name of Schema of the Dynamic
+// using Registry of given Pack. The SchemaName doesn't
+// get and decode value. It returns empty string if
+// schema reference of the Dynamic is blank. The
+// SchemaName returns *SchemaNotRegisteredError if
+// Registry of given Pack doesn't have the Schema
+func (d *Dynamic) SchemaName(pack Pack) (name string, err error) {
+
+	if true == d.Schema.IsBlank() {
+		return
+	}
+
+	var reg *Registry
+	if reg = pack.Registry(); reg == nil {
+		return "", ErrMissingRegistry
+	}
+
+	var sch Schema
+	if sch, err = reg.SchemaByReference(d.Schema); err != nil {
+		return // *SchemaNotRegisteredError
+	}
+
+	return sch.Name(), nil
+}
+
+// ResolveSchema returns Schema of the Dynamic using
+// Registry of given Pack. The ResolveSchema doesn't get
+// and decode value. It returns ErrReferenceRepresentsNil
+// if schema reference of the Dynamic is blank, and
+// *SchemaNotRegisteredError if Registry of given Pack
+// doesn't have the Schema. The method is not called
+// Schema, since the Dynamic has field with the name
+func (d *Dynamic) ResolveSchema(pack Pack) (sch Schema, err error) {
+
+	if true == d.Schema.IsBlank() {
+		return nil, ErrReferenceRepresentsNil
+	}
+
+	var reg *Registry
+	if reg = pack.Registry(); reg == nil {
+		return nil, ErrMissingRegistry
+	}
+
+	return reg.SchemaByReference(d.Schema) // *SchemaNotRegisteredError
 }
 
 // SetValue replacing the Dynamic.Hash with new.
@@ -79,7 +142,7 @@ func (d *Dynamic) SetValue(
 	}
 
 	var hash cipher.SHA256
-	if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+	if hash, err = addValue(pack, obj); err != nil {
 		return
 	}
 
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -133,6 +134,153 @@ func TestDynamic_Value(t *testing.T) {
 		t.Error(err)
 	}
 
+	// schema is not registered
+
+	var other = testPackReg(NewRegistry(func(r *Reg) {
+		r.Register("test.Man", TestMan{})
+	}))
+
+	if err = other.Set(hash, data); err != nil {
+		t.Fatal(err)
+	}
+
+	err = dr.Value(other, &dec)
+
+	if snr, ok := err.(*SchemaNotRegisteredError); ok == false {
+		t.Error("wrong error:", err)
+	} else if snr.Reference() != sr {
+		t.Error("wrong reference of the error")
+	}
+
+	if _, err = other.Registry().SchemaByName("test.User"); err == nil {
+		t.Error("missing error")
+	} else if snr, ok := err.(*SchemaNotRegisteredError); ok == false {
+		t.Error("wrong error:", err)
+	} else if snr.Name() != "test.User" {
+		t.Error("wrong name of the error")
+	}
+
+}
+
+func TestDynamic_SchemaName(t *testing.T) {
+	// SchemaName(pack Pack) (name string, err error)
+
+	var (
+		pack = getTestPack()
+
+		dr   Dynamic
+		name string
+		s    Schema
+		err  error
+	)
+
+	if name, err = dr.SchemaName(pack); err != nil {
+		t.Error(err)
+	} else if name != "" {
+		t.Error("non-empty name of blank Dynamic:", name)
+	}
+
+	if s, err = pack.Registry().SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	dr.Schema = s.Reference()
+	dr.Hash = cipher.SHA256{1, 2, 3} // not in the pack
+
+	if name, err = dr.SchemaName(pack); err != nil {
+		t.Error(err)
+	} else if name != "test.User" {
+		t.Error("wrong name:", name)
+	}
+
+	// schema is not registered
+
+	var other = testPackReg(NewRegistry(func(r *Reg) {
+		r.Register("test.Man", TestMan{})
+	}))
+
+	if _, err = dr.SchemaName(other); err == nil {
+		t.Error("missing error")
+	} else if _, ok := err.(*SchemaNotRegisteredError); ok == false {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestDynamic_ResolveSchema(t *testing.T) {
+	// ResolveSchema(pack Pack) (sch Schema, err error)
+
+	var (
+		pack = getTestPack()
+
+		dr  Dynamic
+		s   Schema
+		err error
+	)
+
+	if _, err = dr.ResolveSchema(pack); err != ErrReferenceRepresentsNil {
+		t.Error("missing or wrong error:", err)
+	}
+
+	var usr = TestUser{Name: "Alice", Age: 19}
+
+	if err = dr.SetValue(pack, &usr); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = dr.ResolveSchema(pack); err != ErrReferenceRepresentsNil {
+		t.Error("missing or wrong error:", err) // schema is not set
+	}
+
+	var reg = pack.Registry()
+
+	if s, err = reg.SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	dr.Schema = s.Reference()
+
+	if s, err = dr.ResolveSchema(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Name() != "test.User" {
+		t.Error("wrong name:", s.Name())
+	}
+
+	var fs = s.Fields()
+
+	if len(fs) != 2 {
+		t.Fatal("wrong number of fields:", len(fs))
+	}
+
+	for i, f := range []struct {
+		name string
+		kind reflect.Kind
+	}{
+		{"Name", reflect.String},
+		{"Age", reflect.Uint32},
+	} {
+		if fs[i].Name() != f.name {
+			t.Error("wrong field name:", fs[i].Name())
+		}
+		if fs[i].Schema().Kind() != f.kind {
+			t.Error("wrong field kind:", fs[i].Schema().Kind())
+		}
+	}
+
+	// schema is not registered
+
+	var other = testPackReg(NewRegistry(func(r *Reg) {
+		r.Register("test.Man", TestMan{})
+	}))
+
+	if _, err = dr.ResolveSchema(other); err == nil {
+		t.Error("missing error")
+	} else if _, ok := err.(*SchemaNotRegisteredError); ok == false {
+		t.Error("wrong error:", err)
+	}
+
 }
 
 func TestDynamic_SetValue(t *testing.T) {
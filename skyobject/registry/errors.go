@@ -2,14 +2,20 @@ package registry
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
 )
 
 // common errors
 var (
-	ErrInvalidEncodedSchema = errors.New("invalid encoded schema")
-	ErrInvalidType          = errors.New("invalid type registering")
-	ErrTypeNotFound         = errors.New("type not found")
-	ErrEmptySkyobjectTag    = errors.New(
+	ErrInvalidEncodedSchema   = errors.New("invalid encoded schema")
+	ErrInvalidEncodedRegistry = errors.New("invalid encoded registry")
+	ErrInvalidType            = errors.New("invalid type registering")
+	ErrTypeNotFound           = errors.New("type not found")
+	ErrDuplicateType          = errors.New("type already registered")
+	ErrEmptySkyobjectTag      = errors.New(
 		`empty skyobject tag, expected "schema=XXX"`)
 
 	ErrInvalidSchemaOrData     = errors.New("invalid schema or data")
@@ -32,4 +38,109 @@ var (
 	ErrNotFound        = errors.New("not found")
 	ErrStopIteration   = errors.New("stop iteration")
 	ErrMissingRegistry = errors.New("missing registry")
+	ErrCyclicReference = errors.New("cyclic reference")
 )
+
+// A SchemaNotRegisteredError occurs when a Registry
+// doesn't have requested Schema. The error contains
+// name or reference of the Schema, depending on
+// the request
+type SchemaNotRegisteredError struct {
+	name string
+	ref  SchemaRef
+}
+
+// Name of the missing Schema, or empty
+// string if the Schema requested by reference
+func (s *SchemaNotRegisteredError) Name() string {
+	return s.name
+}
+
+// Reference of the missing Schema, or blank
+// SchemaRef if the Schema requested by name
+func (s *SchemaNotRegisteredError) Reference() SchemaRef {
+	return s.ref
+}
+
+// Error implements error interface
+func (s *SchemaNotRegisteredError) Error() string {
+	if s.name != "" {
+		return fmt.Sprintf("schema not registered: %q", s.name)
+	}
+	return fmt.Sprintf("schema not registered: %s", s.ref.Short())
+}
+
+// A TreeTooDeepError occurs when a Refs tree is deeper
+// than limit of a DepthPack. The error contains hash
+// and depth of the Refs, and the limit
+type TreeTooDeepError struct {
+	hash  cipher.SHA256
+	depth int
+	max   int
+}
+
+// Hash of the Refs
+func (t *TreeTooDeepError) Hash() cipher.SHA256 {
+	return t.hash
+}
+
+// Depth of the Refs
+func (t *TreeTooDeepError) Depth() int {
+	return t.depth
+}
+
+// Max is the limit exceeded
+func (t *TreeTooDeepError) Max() int {
+	return t.max
+}
+
+// Error implements error interface
+func (t *TreeTooDeepError) Error() string {
+	return fmt.Sprintf("Refs %s is too deep: %d (> %d)",
+		t.hash.Hex()[:7], t.depth, t.max)
+}
+
+// A SchemaNameCollisionError occurs when two different
+// structures with different fields have the same name
+// (registered or not) in a Registry. The NewRegistry
+// panics with this error
+type SchemaNameCollisionError struct {
+	name        string
+	first, last reflect.Type
+}
+
+// Name of the Schema
+func (s *SchemaNameCollisionError) Name() string {
+	return s.name
+}
+
+// Types returns the structures that have the same name
+func (s *SchemaNameCollisionError) Types() (first, last reflect.Type) {
+	return s.first, s.last
+}
+
+// Error implements error interface
+func (s *SchemaNameCollisionError) Error() string {
+	return fmt.Sprintf("schema name collision: %q used by %s and %s",
+		s.name, s.first.String(), s.last.String())
+}
+
+// A MapTypeError occurs when the skycoin encoder (see
+// DefaultCodec) used to encode a value that contains
+// a map. The encoder writes values of a map in random
+// order without keys, thus the same value has different
+// hashes and can't be decoded. Use slices of key-value
+// pairs sorted by key instead
+type MapTypeError struct {
+	typ reflect.Type
+}
+
+// Type of the value that contains a map
+func (m *MapTypeError) Type() reflect.Type {
+	return m.typ
+}
+
+// Error implements error interface
+func (m *MapTypeError) Error() string {
+	return "maps are not allowed: " + m.typ.String()
+}
@@ -1,6 +1,8 @@
 package registry
 
 import (
+	"reflect"
+
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
@@ -65,6 +67,18 @@ const (
 	// it requirs encoding and SHA256 calculating, but it updates
 	// length field
 
+	// ViewOnly flag makes a Pack read-only. Set and Add
+	// methods of a Pack with this flag returns error.
+	// The skyobject package forces this flag for Pack
+	// objects of read-only Container
+	ViewOnly
+
+	// LeanWalk flag turns off keeping decoded values by
+	// a ValuePack with the ViewOnly flag. Every access to
+	// a value decodes it again. This way, memory used to
+	// walk a huge tree once is not retained by the Pack.
+	// The flag has no effect without the ViewOnly flag
+	LeanWalk
 )
 
 // A Degree represents degree of the Refs. The Degree represented
@@ -114,6 +128,63 @@ type Pack interface {
 	ClearFlags(Flags) // clear given Flags from internal (AND NOT)
 }
 
+// A ValuePack is Pack that keeps decoded values of
+// objects. If a Pack implements this interface, then
+// the Value method of the Ref looks up a decoded value
+// in the Pack before getting and decoding the object.
+// Objects are immutable, thus a value, keyed by hash
+// and type, never becomes stale; a reassigned Ref
+// refers to another key. A kept value is copied to
+// destination deeply (see CopyValue), thus a value got
+// from a ValuePack can be changed in place
+type ValuePack interface {
+	Pack
+
+	// Value returns kept value of given type
+	Value(hash cipher.SHA256, typ reflect.Type) (val reflect.Value, ok bool)
+	// KeepValue keeps decoded value
+	KeepValue(hash cipher.SHA256, val reflect.Value)
+}
+
+// A DepthPack is Pack that limits depth of Refs trees.
+// If a Pack implements this interface, then a Refs
+// deeper than the limit can't be loaded using the
+// Pack, and a TreeTooDeepError returned instead. The
+// limit protects against crafted Refs with pathological
+// depth received from network
+type DepthPack interface {
+	Pack
+
+	// MaxTreeDepth returns max depth of Refs tree,
+	// zero means no limit
+	MaxTreeDepth() int
+}
+
+// max depth of Refs tree of given Pack; zero if the
+// Pack is not DepthPack
+func maxTreeDepth(pack Pack) (max int) {
+	if dp, ok := pack.(DepthPack); ok == true {
+		max = dp.MaxTreeDepth()
+	}
+	return
+}
+
+// check depth of Refs tree if the Pack is DepthPack
+func checkDepth(
+	pack Pack, //          : pack to check
+	hash cipher.SHA256, // : hash of the Refs
+	depth int, //          : real depth of the Refs
+) (
+	err error, //          : TreeTooDeepError
+) {
+
+	if max := maxTreeDepth(pack); max > 0 && depth > max {
+		return &TreeTooDeepError{hash: hash, depth: depth, max: max}
+	}
+
+	return
+}
+
 // get by hash from the Pack and deocde to given pointer (obj)
 func get(
 	pack Pack, //          : pack to get from
@@ -3,6 +3,7 @@ package registry
 import (
 	//"testing"
 	"errors"
+	"reflect"
 
 	"github.com/skycoin/skycoin/src/cipher"
 	//"github.com/skycoin/skycoin/src/cipher/encoder"
@@ -102,3 +103,40 @@ func (e *errorPack) Add([]byte) (_ cipher.SHA256, err error) {
 	err = errTest
 	return
 }
+
+// pack that keeps decoded values and counts Get calls
+
+type valuePack struct {
+	*dummyPack
+	gets int
+	kept map[cipher.SHA256]reflect.Value
+}
+
+func getTestValuePack() (vp *valuePack) {
+	vp = new(valuePack)
+	vp.dummyPack = getTestPack()
+	vp.kept = make(map[cipher.SHA256]reflect.Value)
+	return
+}
+
+func (v *valuePack) Get(key cipher.SHA256) (val []byte, err error) {
+	v.gets++
+	return v.dummyPack.Get(key)
+}
+
+func (v *valuePack) Value(
+	hash cipher.SHA256,
+	typ reflect.Type,
+) (
+	val reflect.Value,
+	ok bool,
+) {
+	if val, ok = v.kept[hash]; ok == true && val.Type() != typ {
+		ok = false
+	}
+	return
+}
+
+func (v *valuePack) KeepValue(hash cipher.SHA256, val reflect.Value) {
+	v.kept[hash] = val
+}
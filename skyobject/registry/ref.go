@@ -4,7 +4,6 @@ import (
 	"reflect"
 
 	"github.com/skycoin/skycoin/src/cipher"
-	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
 //
@@ -32,20 +31,33 @@ func (r *Ref) String() string {
 	return r.Hash.Hex()
 }
 
-// Value of the Ref
+// Value of the Ref. If given Pack implements the
+// ValuePack interface, then decoded value is kept
+// by the Pack and repeated calls don't get and
+// decode the object again
 func (r *Ref) Value(pack Pack, obj interface{}) (err error) {
 
 	if true == r.IsBlank() {
 		return ErrReferenceRepresentsNil
 	}
 
-	// obtain encoded object
-	var val []byte
-	if val, err = pack.Get(r.Hash); err != nil {
-		return
+	if vp, ok := pack.(ValuePack); ok == true {
+		return getKeptValue(vp, r.Hash, obj)
+	}
+
+	return getValue(pack, r.Hash, obj)
+}
+
+// Bytes returns encoded object the Ref points to,
+// without decoding. The Bytes returns value of the
+// Pack as is, and the value must not be modified
+func (r *Ref) Bytes(pack Pack) (val []byte, err error) {
+
+	if true == r.IsBlank() {
+		return nil, ErrReferenceRepresentsNil
 	}
 
-	return encoder.DeserializeRaw(val, obj)
+	return pack.Get(r.Hash)
 }
 
 // SetValue replacing the Ref with new. Use nil-interface{} to clear
@@ -62,7 +74,7 @@ func (r *Ref) SetValue(
 	}
 
 	var hash cipher.SHA256
-	if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+	if hash, err = addValue(pack, obj); err != nil {
 		return
 	}
 
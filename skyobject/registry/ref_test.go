@@ -88,6 +88,149 @@ func TestRef_Value(t *testing.T) {
 
 }
 
+func TestRef_Value_kept(t *testing.T) {
+
+	var (
+		pack = getTestValuePack()
+
+		alice = TestUser{Name: "Alice", Age: 15}
+		eva   = TestUser{Name: "Eva", Age: 16}
+
+		dec TestUser
+		ref Ref
+
+		err error
+	)
+
+	if err = ref.SetValue(pack, &alice); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+
+		if err = ref.Value(pack, &dec); err != nil {
+			t.Fatal(err)
+		}
+
+		if dec.Name != alice.Name || dec.Age != alice.Age {
+			t.Error("wrong value")
+		}
+
+		if pack.gets != 1 {
+			t.Error("wrong number of Get calls:", pack.gets)
+		}
+
+		dec.Name = "Modified" // doesn't affect kept value
+
+	}
+
+	// reassign
+
+	if err = ref.SetValue(pack, &eva); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ref.Value(pack, &dec); err != nil {
+		t.Fatal(err)
+	}
+
+	if dec.Name != eva.Name || dec.Age != eva.Age {
+		t.Error("wrong value")
+	}
+
+	if pack.gets != 2 {
+		t.Error("wrong number of Get calls:", pack.gets)
+	}
+
+}
+
+func TestRef_Value_keptDeep(t *testing.T) {
+
+	var (
+		pack = getTestValuePack()
+
+		obj = TestSliceStruct{
+			Int8:   []int8{1, 2, 3},
+			String: []string{"one", "two"},
+		}
+
+		dec TestSliceStruct
+		ref Ref
+
+		err error
+	)
+
+	if err = ref.SetValue(pack, &obj); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+
+		if err = ref.Value(pack, &dec); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(dec.Int8) != 3 || dec.Int8[0] != 1 ||
+			len(dec.String) != 2 || dec.String[0] != "one" {
+
+			t.Error("wrong value", i)
+		}
+
+		// doesn't affect kept value
+		dec.Int8[0] = 10
+		dec.String[0] = "modified"
+
+	}
+
+	if pack.gets != 1 {
+		t.Error("wrong number of Get calls:", pack.gets)
+	}
+
+}
+
+func TestRef_Bytes(t *testing.T) {
+	// Bytes(pack Pack) (val []byte, err error)
+
+	var (
+		pack = getTestPack() // dummy pack
+
+		usr = TestUser{
+			Name: "Alice",
+			Age:  15,
+		}
+
+		ref Ref
+
+		val []byte
+		err error
+	)
+
+	if _, err = ref.Bytes(pack); err == nil {
+		t.Error("missing error") // blank
+	} else if err != ErrReferenceRepresentsNil {
+		t.Error("wrong error:", err)
+	}
+
+	ref.Hash = cipher.SHA256{1, 2, 3}
+
+	if _, err = ref.Bytes(pack); err == nil {
+		t.Error("missing error")
+	}
+
+	if err = ref.SetValue(pack, &usr); err != nil {
+		t.Fatal(err)
+	}
+
+	if val, err = ref.Bytes(pack); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(val) != string(encoder.Serialize(&usr)) {
+		t.Error("wrong value")
+	}
+
+}
+
 func TestRef_SetValue(t *testing.T) {
 	// SetValue(pack Pack, obj interface{}) (err error)
 
@@ -136,3 +279,99 @@ func TestRef_Clear(t *testing.T) {
 	}
 
 }
+
+func TestRef_nested(t *testing.T) {
+
+	var (
+		reg  = testNestedRegistry()
+		pack = testPackReg(reg)
+
+		alice = TestUser{Name: "Alice", Age: 15}
+		eva   = TestUser{Name: "Eva", Age: 16}
+
+		nested = TestNested{Name: "nested"}
+
+		err error
+	)
+
+	if err = nested.Inner.Curator.SetValue(pack, &alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = nested.Inner.Members.AppendValues(pack, &alice, &eva); err != nil {
+		t.Fatal(err)
+	}
+
+	var sch Schema
+	if sch, err = reg.SchemaByName("test.User"); err != nil {
+		t.Fatal(err)
+	}
+
+	nested.Inner.Favorite.Schema = sch.Reference()
+	if err = nested.Inner.Favorite.SetValue(pack, &eva); err != nil {
+		t.Fatal(err)
+	}
+
+	var ref Ref
+	if err = ref.SetValue(pack, &nested); err != nil {
+		t.Fatal(err)
+	}
+
+	// decode and dereference
+
+	var dec TestNested
+	if err = ref.Value(pack, &dec); err != nil {
+		t.Fatal(err)
+	}
+
+	var usr TestUser
+
+	if err = dec.Inner.Curator.Value(pack, &usr); err != nil {
+		t.Fatal(err)
+	} else if usr.Name != alice.Name || usr.Age != alice.Age {
+		t.Error("wrong value of nested Ref")
+	}
+
+	if _, err = dec.Inner.Members.ValueByIndex(pack, 1, &usr); err != nil {
+		t.Fatal(err)
+	} else if usr.Name != eva.Name || usr.Age != eva.Age {
+		t.Error("wrong value of nested Refs")
+	}
+
+	if err = dec.Inner.Favorite.Value(pack, &usr); err != nil {
+		t.Fatal(err)
+	} else if usr.Name != eva.Name || usr.Age != eva.Age {
+		t.Error("wrong value of nested Dynamic")
+	}
+
+	// walk through nested references
+
+	if sch, err = reg.SchemaByName("test.Nested"); err != nil {
+		t.Fatal(err)
+	}
+
+	var dr = Dynamic{Hash: ref.Hash, Schema: sch.Reference()}
+
+	var hashes = make(map[cipher.SHA256]struct{})
+
+	err = dr.Walk(pack, func(hash cipher.SHA256, _ int) (bool, error) {
+		hashes[hash] = struct{}{}
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, hash := range []cipher.SHA256{
+		ref.Hash,
+		getHash(alice),
+		getHash(eva),
+		nested.Inner.Members.Hash,
+	} {
+		if _, ok := hashes[hash]; ok == false {
+			t.Error("missing hash in walk", hash.Hex()[:7])
+		}
+	}
+
+}
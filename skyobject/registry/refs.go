@@ -107,6 +107,12 @@ func (r *Refs) initialize(pack Pack) (err error) {
 		return // get or decoding error
 	}
 
+	// check the depth before loading, since the
+	// loading is recursive
+	if err = checkDepth(pack, r.Hash, int(er.Depth)+1); err != nil {
+		return
+	}
+
 	r.depth = int(er.Depth)
 	r.degree = Degree(er.Degree) // overwrite from saved
 
@@ -143,6 +149,16 @@ func (r *Refs) Len(pack Pack) (ln int, err error) {
 	return
 }
 
+// IsEmpty returns true if the Refs has no elements.
+// The IsEmpty doesn't load the Refs and doesn't
+// require a Pack
+func (r *Refs) IsEmpty() bool {
+	if r.refsNode != nil && r.mods != 0 {
+		return r.length == 0 // loaded
+	}
+	return r.Hash == (cipher.SHA256{})
+}
+
 // Depth return real depth of the Refs
 func (r *Refs) Depth(pack Pack) (depth int, err error) {
 	if err = r.initialize(pack); err != nil {
@@ -310,7 +326,7 @@ func (r *Refs) ValueByHash(
 		return ErrRefsElementIsNil
 	}
 
-	err = get(pack, hash, obj)
+	err = getValue(pack, hash, obj)
 	return
 }
 
@@ -463,7 +479,7 @@ func (r *Refs) ValueOfHashWithIndex(
 		return
 	}
 
-	err = get(pack, hash, obj) // get and decode
+	err = getValue(pack, hash, obj) // get and decode
 	return
 }
 
@@ -501,6 +517,36 @@ func (r *Refs) HashByIndex(
 	return el.Hash, nil
 }
 
+// Hashes returns ordered list of hashes of all elements
+// of the Refs. The list can contain blank hashes of nil
+// elements. The Hashes iterates over the Refs ascending
+//
+// The big O of the call is O(n)
+func (r *Refs) Hashes(
+	pack Pack, //              : pack to load
+) (
+	hashes []cipher.SHA256, // : the hashes
+	err error, //              : error if any
+) {
+
+	if err = r.initialize(pack); err != nil {
+		return
+	}
+
+	if r.length == 0 {
+		return // empty Refs
+	}
+
+	hashes = make([]cipher.SHA256, r.length)
+
+	err = r.Ascend(pack, func(i int, hash cipher.SHA256) (_ error) {
+		hashes[i] = hash
+		return
+	})
+
+	return
+}
+
 // ValueByIndex returns value by index or ErrNotFound
 // or another error. It also returns hash of the value.
 // The ValueByIndex returns ErrRefsElementIsNil if element
@@ -525,7 +571,7 @@ func (r *Refs) ValueByIndex(
 		return
 	}
 
-	err = get(pack, hash, obj)
+	err = getValue(pack, hash, obj)
 	return
 }
 
@@ -652,7 +698,7 @@ func (r *Refs) SetValueByIndex(
 	var hash cipher.SHA256
 
 	if isNil(obj) == false {
-		if hash, err = pack.Add(encoder.Serialize(obj)); err != nil {
+		if hash, err = addValue(pack, obj); err != nil {
 			return
 		}
 	}
@@ -1504,7 +1550,7 @@ func (r *Refs) AppendValues(
 
 		} else {
 
-			if hash, err = pack.Add(encoder.Serialize(val)); err != nil {
+			if hash, err = addValue(pack, val); err != nil {
 				return
 			}
 
@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"encoding/binary"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// A proof of the Refs is list of levels from the node
+// that contains the leaf up to the Refs. A level is a
+// header followed by elements of the node except the
+// one on the path to the leaf. The header is not a hash,
+// it's fields of the encoded node packed to SHA256:
+//
+//     [ 4 length ][ 4 index ][ 4 elements ][ 4 depth ]
+//     [ 4 degree ][ 1 is the Refs ][ 11 zeroes ]
+//
+// where the index is index of the element on the path,
+// and the depth and the degree are used for the last
+// level (the Refs) only
+type proofHeader struct {
+	length   uint32 // length of the node
+	index    uint32 // index of the path element
+	elements uint32 // number of elements of the node
+	depth    uint32 // depth of the Refs
+	degree   uint32 // degree of the Refs
+	isRefs   bool   // the level is the Refs
+}
+
+func (p *proofHeader) encode() (h cipher.SHA256) {
+
+	binary.LittleEndian.PutUint32(h[0:], p.length)
+	binary.LittleEndian.PutUint32(h[4:], p.index)
+	binary.LittleEndian.PutUint32(h[8:], p.elements)
+	binary.LittleEndian.PutUint32(h[12:], p.depth)
+	binary.LittleEndian.PutUint32(h[16:], p.degree)
+
+	if p.isRefs == true {
+		h[20] = 1
+	}
+
+	return
+}
+
+func (p *proofHeader) decode(h cipher.SHA256) (ok bool) {
+
+	p.length = binary.LittleEndian.Uint32(h[0:])
+	p.index = binary.LittleEndian.Uint32(h[4:])
+	p.elements = binary.LittleEndian.Uint32(h[8:])
+	p.depth = binary.LittleEndian.Uint32(h[12:])
+	p.degree = binary.LittleEndian.Uint32(h[16:])
+	p.isRefs = h[20] == 1
+
+	for _, b := range h[21:] {
+		if b != 0 {
+			return false
+		}
+	}
+
+	return h[20] <= 1 && p.elements > 0 && p.index < p.elements
+}
+
+// Proof returns Merkle proof of given element of the
+// Refs. The proof can be verified using the VerifyProof
+// function against hash of the Refs without the Refs.
+// The Proof returns ErrNotFound if the Refs doesn't
+// have given element, and ErrRefsElementIsNil for blank
+// hash. The Refs should not have unsaved changes.
+// Otherwise, the Proof returns ErrInvalidRefsState. If
+// the Refs has many elements with given hash, then proof
+// of which of them will be returned, is undefined
+//
+// The big O of the call is the same as the big O of the
+// IndexOfHash call
+func (r *Refs) Proof(
+	pack Pack, //              : pack to load
+	hash cipher.SHA256, //     : hash of the element
+) (
+	proof []cipher.SHA256, // : the proof
+	err error, //              : an error
+) {
+
+	if hash == (cipher.SHA256{}) {
+		return nil, ErrRefsElementIsNil
+	}
+
+	var i int
+	if i, err = r.IndexOfHash(pack, hash); err != nil {
+		return
+	}
+
+	var el *refsElement
+	if el, err = r.elementByIndex(pack, r.refsNode, i, r.depth); err != nil {
+		return
+	}
+
+	var index int
+	if index, err = el.indexInUpper(); err != nil {
+		return
+	}
+
+	var (
+		rn       = el.upper
+		elements []cipher.SHA256
+	)
+
+	for _, el := range rn.leafs {
+		elements = append(elements, el.Hash)
+	}
+
+	for {
+
+		var ph = proofHeader{
+			length:   uint32(rn.length),
+			index:    uint32(index),
+			elements: uint32(len(elements)),
+			isRefs:   rn.upper == nil,
+		}
+
+		if ph.isRefs == true {
+			ph.depth, ph.degree = uint32(r.depth), uint32(r.degree)
+		}
+
+		proof = append(proof, ph.encode())
+		proof = append(proof, elements[:index]...)
+		proof = append(proof, elements[index+1:]...)
+
+		if ph.isRefs == true {
+			break
+		}
+
+		var up = rn.upper
+
+		elements = elements[:0]
+
+		for j, br := range up.branches {
+			if br == rn {
+				index = j
+			}
+			elements = append(elements, br.hash)
+		}
+
+		rn = up
+
+	}
+
+	if VerifyProof(r.Hash, hash, proof) == false {
+		return nil, ErrInvalidRefsState // unsaved changes
+	}
+
+	return
+}
+
+// VerifyProof returns true if given proof (see Proof
+// method of the Refs) proves that Refs with given hash
+// contains given element. Number of levels of the proof
+// must match depth of the Refs, thus a truncated proof
+// can't prove a branch of the Refs as an element
+func VerifyProof(root, leaf cipher.SHA256, proof []cipher.SHA256) bool {
+
+	var (
+		cur    = leaf
+		levels int
+	)
+
+	for len(proof) > 0 {
+
+		levels++
+
+		var ph proofHeader
+
+		if ph.decode(proof[0]) == false {
+			return false // invalid header
+		}
+
+		proof = proof[1:]
+
+		var siblings = int(ph.elements) - 1
+
+		if siblings > len(proof) {
+			return false // short proof
+		}
+
+		var elements = make([]cipher.SHA256, 0, ph.elements)
+
+		elements = append(elements, proof[:ph.index]...)
+		elements = append(elements, cur)
+		elements = append(elements, proof[ph.index:siblings]...)
+
+		proof = proof[siblings:]
+
+		if ph.isRefs == false {
+			cur = cipher.SumSHA256(encoder.Serialize(encodedRefsNode{
+				Length:   ph.length,
+				Elements: elements,
+			}))
+			continue
+		}
+
+		if len(proof) != 0 {
+			return false // the Refs is not last
+		}
+
+		if levels != int(ph.depth)+1 {
+			return false // truncated or extended proof
+		}
+
+		cur = cipher.SumSHA256(encoder.Serialize(encodedRefs{
+			Depth:    ph.depth,
+			Degree:   ph.degree,
+			Length:   ph.length,
+			Elements: elements,
+		}))
+
+		return cur == root
+
+	}
+
+	return false // the Refs is missing
+}
@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+func TestRefs_Proof(t *testing.T) {
+	// Proof(pack Pack, hash cipher.SHA256) ([]cipher.SHA256, error)
+
+	var pack = getTestPack()
+
+	for _, length := range []int{1, 3, 10, 30} {
+
+		t.Run(fmt.Sprint("length ", length), func(t *testing.T) {
+
+			var (
+				r      Refs
+				hashes = make([]cipher.SHA256, 0, length)
+			)
+
+			for i := 0; i < length; i++ {
+				hashes = append(hashes,
+					cipher.SumSHA256([]byte(fmt.Sprint("element ", i))))
+			}
+
+			if err := r.AppendHashes(pack, hashes...); err != nil {
+				t.Fatal(err)
+			}
+
+			// lazy loaded
+			var lr = Refs{Hash: r.Hash}
+
+			for i, hash := range hashes {
+
+				var proof, err = lr.Proof(pack, hash)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if VerifyProof(r.Hash, hash, proof) == false {
+					t.Errorf("can't verify proof of %d", i)
+				}
+
+				if VerifyProof(r.Hash, cipher.SHA256{1}, proof) == true {
+					t.Errorf("verified wrong leaf %d", i)
+				}
+
+				if VerifyProof(cipher.SHA256{1}, hash, proof) == true {
+					t.Errorf("verified wrong root %d", i)
+				}
+
+				// tampered
+
+				for j := range proof {
+					var tampered = append([]cipher.SHA256{}, proof...)
+					tampered[j][0]++
+					if VerifyProof(r.Hash, hash, tampered) == true {
+						t.Errorf("verified tampered proof of %d (%d)", i, j)
+					}
+				}
+
+				if VerifyProof(r.Hash, hash, proof[:len(proof)-1]) == true {
+					t.Errorf("verified short proof of %d", i)
+				}
+
+				// truncated: a branch as an element
+
+				var ph proofHeader
+				if ph.decode(proof[0]) == false {
+					t.Fatal("invalid header")
+				}
+
+				if ph.isRefs == true {
+					continue // no branches
+				}
+
+				var (
+					siblings = proof[1:ph.elements]
+					elements []cipher.SHA256
+				)
+
+				elements = append(elements, siblings[:ph.index]...)
+				elements = append(elements, hash)
+				elements = append(elements, siblings[ph.index:]...)
+
+				var branch = cipher.SumSHA256(encoder.Serialize(
+					encodedRefsNode{Length: ph.length, Elements: elements}))
+
+				var truncated = proof[ph.elements:]
+
+				if VerifyProof(r.Hash, branch, truncated) == true {
+					t.Errorf("verified truncated proof of %d", i)
+				}
+
+			}
+
+			// not found and nil
+
+			if _, err := lr.Proof(pack, cipher.SHA256{1}); err != ErrNotFound {
+				t.Error("wrong error:", err)
+			}
+
+			if _, err := lr.Proof(pack, cipher.SHA256{}); err != ErrRefsElementIsNil {
+				t.Error("wrong error:", err)
+			}
+
+		})
+
+	}
+
+}
@@ -517,6 +517,44 @@ func TestRefs_Len(t *testing.T) {
 
 }
 
+func TestRefs_IsEmpty(t *testing.T) {
+	// IsEmpty() bool
+
+	var (
+		pack = getTestPack()
+		refs Refs
+	)
+
+	if refs.IsEmpty() == false {
+		t.Error("blank Refs is not empty")
+	}
+
+	if testFillRefsWithUsers(t, &refs, pack, 3); t.Failed() {
+		t.FailNow()
+	}
+
+	if refs.IsEmpty() == true {
+		t.Error("non-empty Refs is empty")
+	}
+
+	// not loaded
+
+	var nl = Refs{Hash: refs.Hash}
+
+	if nl.IsEmpty() == true {
+		t.Error("non-empty Refs is empty")
+	}
+
+	// cleared
+
+	refs.Clear()
+
+	if refs.IsEmpty() == false {
+		t.Error("cleared Refs is not empty")
+	}
+
+}
+
 func TestRefs_Depth(t *testing.T) {
 	// Depth(pack Pack) (depth int, err error)
 
@@ -1840,6 +1878,79 @@ func TestRefs_HashByIndex(t *testing.T) {
 
 }
 
+func TestRefs_Hashes(t *testing.T) {
+	// Hashes(pack Pack) (hashes []cipher.SHA256, err error)
+
+	var (
+		pack = getTestPack()
+
+		refs   Refs
+		hashes []cipher.SHA256
+		err    error
+	)
+
+	// blank
+
+	if hashes, err = refs.Hashes(pack); err != nil {
+		t.Fatal(err)
+	} else if len(hashes) != 0 {
+		t.Fatal("blank Refs returns hashes")
+	}
+
+	for _, flags := range []Flags{
+		testNoMeaninFlag,
+		EntireRefs,
+		HashTableIndex,
+	} {
+
+		for _, length := range []int{
+			int(pack.Degree()),                   // only leafs
+			int(pack.Degree()) + 1,               // leafs and branches
+			int(pack.Degree()*pack.Degree()) + 1, // branches with branches
+		} {
+
+			t.Run(fmt.Sprintf("%d:%d", length, flags), func(t *testing.T) {
+
+				refs.Clear()
+				pack.ClearFlags(^0)
+				pack.AddFlags(flags)
+
+				var users = getHashList(
+					testFillRefsWithUsers(t, &refs, pack, length),
+				)
+
+				// with nil element
+				users = append(users, cipher.SHA256{})
+				if err = refs.AppendHashes(pack, cipher.SHA256{}); err != nil {
+					t.Fatal(err)
+				}
+
+				refs.Reset() // load from DB
+
+				if hashes, err = refs.Hashes(pack); err != nil {
+					t.Fatal(err)
+				}
+
+				if len(hashes) != len(users) {
+					t.Fatalf("wrong length: want %d, got %d", len(users),
+						len(hashes))
+				}
+
+				for i, hash := range hashes {
+					if hash != users[i] {
+						t.Errorf("wrong hash %d: want %s, got %s", i,
+							users[i].Hex()[:7], hash.Hex()[:7])
+					}
+				}
+
+			})
+
+		}
+
+	}
+
+}
+
 func TestRefs_ValueByIndex(t *testing.T) {
 	// ValueByIndex(pack Pack, i int, obj interface{}) (hash cipher.SHA256,
 	//     err error)
@@ -1,17 +1,21 @@
 package registry
 
 import (
+	"bytes"
 	"reflect"
 )
 
 // A Reg creates new Registry
 type Reg struct {
-	tn map[reflect.Type]string // type -> registered name
+	tn    map[reflect.Type]string // type -> registered name
+	nt    map[string]reflect.Type // name -> type (all named structures)
+	codec string                  // name of Codec (see UseCodec)
 }
 
 func newReg() *Reg {
 	return &Reg{
 		tn: make(map[reflect.Type]string),
+		nt: make(map[string]reflect.Type),
 	}
 }
 
@@ -37,6 +41,15 @@ func (r *Reg) Register(name string, val interface{}) {
 	}
 
 	r.tn[typ] = name
+	r.nt[name] = typ
+}
+
+// UseCodec sets Codec values of objects encoded with.
+// Name of the Codec is part of the Registry. By default,
+// the DefaultCodec used (see Codec and CodecName method
+// of the Registry)
+func (r *Reg) UseCodec(codec Codec) {
+	r.codec = codec.Name()
 }
 
 // use (reflect.Type).Name() or name provided to Register;
@@ -131,8 +144,16 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 
 		}
 
+		if len(ss.name) > 0 {
+			r.checkNameCollision(typ, ss)
+		}
+
 		return ss
 
+	case reflect.Map:
+
+		panic(&MapTypeError{typ}) // see MapTypeError for details
+
 	default:
 	}
 
@@ -140,6 +161,41 @@ func (r *Reg) getSchema(typ reflect.Type) Schema {
 
 }
 
+// a Schema of a struct field refers to a named structure
+// by name; thus, two different structures with the same
+// name can't be used together, since the name can
+// point to wrong structure; the checkNameCollision
+// panics with *SchemaNameCollisionError if another
+// structure with different fields has the same name
+func (r *Reg) checkNameCollision(typ reflect.Type, ss *structSchema) {
+
+	var (
+		name     = string(ss.name)
+		prev, ok = r.nt[name]
+		prevs    Schema
+	)
+
+	if ok == false {
+		r.nt[name] = typ
+		return
+	}
+
+	if prev == typ {
+		return
+	}
+
+	// the same fields (the same encoded schema)
+	// can be used with any of the structures
+
+	prevs = r.getSchema(prev)
+
+	if bytes.Compare(prevs.Encode(), ss.Encode()) == 0 {
+		return
+	}
+
+	panic(&SchemaNameCollisionError{name, prev, typ})
+}
+
 func (r *Reg) getField(sf reflect.StructField) Field {
 
 	f := new(field)
@@ -18,7 +18,9 @@ const Tag = "skyobject"
 type Registry struct {
 	done bool // stop registration and use
 
-	ref RegistryRef // reference to the registry
+	ref   RegistryRef // reference to the registry
+	enc   []byte      // encoded registry (read only)
+	codec string      // name of Codec, blank for the DefaultCodec
 
 	reg map[string]Schema    // by name
 	srf map[SchemaRef]Schema // by reference (for Dynamic references)
@@ -39,19 +41,34 @@ func newRegistry() (r *Registry) {
 // DecodeRegistry decodes an encoded Registry
 func DecodeRegistry(b []byte) (r *Registry, err error) {
 
+	// the encoder panics decoding malformed data
+	defer func() {
+		if recover() != nil {
+			r, err = nil, ErrInvalidEncodedRegistry
+		}
+	}()
+
 	var (
 		res = registryEntities{}
 		s   Schema
 	)
 
-	if err = encoder.DeserializeRaw(b, &res); err != nil {
-		return
-	}
-
 	r = newRegistry()
 
+	// a Registry with a Codec (see encode); entities of
+	// the registryCodec can be decoded as registryEntities
+	// ignoring the rest, thus the registryCodec first
+
+	if rc, ok := decodeRegistryCodec(b); ok == true {
+		res, r.codec = rc.Entities, rc.Codec
+	} else if err = encoder.DeserializeRaw(b, &res); err != nil {
+		return nil, err
+	}
+
 	for _, re := range res {
-		s, err = decodeSchema(re.Schema)
+		if s, err = decodeSchema(re.Schema); err != nil {
+			return nil, err
+		}
 		r.reg[re.Name] = s
 		r.srf[s.Reference()] = s
 	}
@@ -83,12 +100,113 @@ func NewRegistry(cl func(t *Reg)) (r *Registry) {
 	return
 }
 
-// Encode registry to send
-func (r *Registry) Encode() []byte {
+// BuildRegistry creates Registry registering types of
+// given values. Name of a type is (reflect.Type).String()
+// of the type (e.g. "pkg.User"). Pointers are converted
+// to non-pointer types. The BuildRegistry returns the
+// Registry and its Types. It returns ErrInvalidType if a
+// value is nil, is a reference or its type is unnamed;
+// ErrDuplicateType if a type (or name) used many times,
+// *MapTypeError if a type contains a map, and another
+// error if the types can't be registered
+func BuildRegistry(
+	values ...interface{}, // : example values
+) (
+	r *Registry, //           : the Registry
+	ts *Types, //             : types of the Registry
+	err error, //             : an error
+) {
+
+	var (
+		names = make([]string, 0, len(values))
+		types = make(map[string]reflect.Type, len(values))
+	)
+
+	for _, val := range values {
+
+		if val == nil {
+			return nil, nil, ErrInvalidType
+		}
+
+		var typ = reflect.TypeOf(val)
+
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		switch typ {
+		case typeOfRef, typeOfRefs, typeOfDynamic:
+			return nil, nil, ErrInvalidType
+		}
+
+		if typ.Name() == "" {
+			return nil, nil, ErrInvalidType
+		}
+
+		var name = typ.String()
+
+		if _, ok := types[name]; ok == true {
+			return nil, nil, ErrDuplicateType
+		}
+
+		names = append(names, name)
+		types[name] = typ
+	}
+
+	// the NewRegistry panics registering invalid types
+	defer func() {
+		if pc := recover(); pc != nil {
+			if err, _ = pc.(error); err == nil {
+				err = fmt.Errorf("%v", pc)
+			}
+			r, ts = nil, nil
+		}
+	}()
+
+	r = NewRegistry(func(t *Reg) {
+		for _, name := range names {
+			t.Register(name, reflect.Zero(types[name]).Interface())
+		}
+	})
+
+	ts = r.Types()
+	return
+}
 
-	if len(r.reg) == 0 {
-		return encoder.Serialize(registryEntities{}) // empty
+// Encode registry to send. The Registry is encoded
+// once and the Encode returns the same slice every
+// time. Thus the slice must not be modified
+func (r *Registry) Encode() []byte {
+	if r.enc == nil {
+		r.enc = r.encode()
 	}
+	return r.enc
+}
+
+// encoded Registry with a Codec other than the DefaultCodec;
+// a Registry with the DefaultCodec is encoded as its entities
+// only, and its encoding is the same as before the Codec
+type registryCodec struct {
+	Entities registryEntities
+	Codec    string
+}
+
+// decode registryCodec, the ok is false
+// if given data is not a registryCodec
+func decodeRegistryCodec(b []byte) (rc registryCodec, ok bool) {
+
+	// the encoder panics decoding malformed data
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	ok = encoder.DeserializeRaw(b, &rc) == nil && rc.Codec != ""
+	return
+}
+
+func (r *Registry) encode() []byte {
 
 	var ent = make(registryEntities, 0, len(r.reg))
 	for name, sch := range r.reg {
@@ -97,24 +215,40 @@ func (r *Registry) Encode() []byte {
 
 	sort.Sort(ent)
 
+	if r.codec != "" {
+		return encoder.Serialize(registryCodec{ent, r.codec})
+	}
+
 	return encoder.Serialize(ent)
 }
 
+// CodecName returns name of Codec values of objects
+// encoded with (see Codec and UseCodec method of the
+// Reg). The name is part of the Registry
+func (r *Registry) CodecName() string {
+	if r.codec == "" {
+		return DefaultCodec.Name()
+	}
+	return r.codec
+}
+
 // Reference of the Registry
 func (r *Registry) Reference() RegistryRef {
 	return r.ref
 }
 
 // SchemaByReference returns Schema by SchemaRef that is obvious.
+// It returns *SchemaNotRegisteredError if the Registry doesn't
+// have the Schema
 func (r *Registry) SchemaByReference(sr SchemaRef) (s Schema, err error) {
 	var ok bool
 	if s, ok = r.srf[sr]; !ok {
-		err = fmt.Errorf("missng schema %q", sr.String())
+		err = &SchemaNotRegisteredError{ref: sr}
 	}
 	return
 }
 
-// SchemaByName returns schema by name or "missing schema" error
+// SchemaByName returns schema by name or *SchemaNotRegisteredError
 func (r *Registry) SchemaByName(name string) (Schema, error) {
 	return r.schemaByName(name)
 }
@@ -136,6 +270,10 @@ func (r *Registry) register(reg *Reg) {
 
 	r.tn = reg.tn // keep the map
 
+	if reg.codec != DefaultCodec.Name() {
+		r.codec = reg.codec
+	}
+
 	for typ, name := range reg.tn {
 		r.nt[name] = typ // build r.nt by the reg.tn
 		s := reg.getSchema(typ)
@@ -209,7 +347,7 @@ func (r *Registry) fillSchema(s Schema, filled map[Schema]struct{}) {
 func (r *Registry) schemaByName(name string) (s Schema, err error) {
 	var ok bool
 	if s, ok = r.reg[name]; !ok {
-		err = fmt.Errorf("missing schema %q", name)
+		err = &SchemaNotRegisteredError{name: name}
 	}
 	return
 }
@@ -225,8 +363,8 @@ func (r *Registry) finialize() {
 		r.srf[sch.Reference()] = sch
 	}
 
-	encoded := r.Encode()
-	r.ref = RegistryRef(cipher.SumSHA256(encoded))
+	r.enc = r.encode()
+	r.ref = RegistryRef(cipher.SumSHA256(r.enc))
 }
 
 // TagSchemaName returns schema name from given reflect.StructTag.
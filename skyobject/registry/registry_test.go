@@ -2,6 +2,7 @@ package registry
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 )
 
@@ -337,7 +338,89 @@ func TestRegistry_SchemaByReference(t *testing.T) {
 }
 
 func TestRegistry_Encode(t *testing.T) {
-	//
+	// Encode() []byte
+
+	var (
+		reg = testRegistry()
+		enc = reg.Encode()
+	)
+
+	if bytes.Equal(enc, reg.encode()) == false {
+		t.Error("wrong encoded registry")
+	}
+
+	if &reg.Encode()[0] != &enc[0] {
+		t.Error("registry encoded twice")
+	}
+
+	var dec, err = DecodeRegistry(enc)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dec.Reference() != reg.Reference() {
+		t.Error("wrong reference of decoded registry")
+	}
+
+	if bytes.Equal(dec.Encode(), enc) == false {
+		t.Error("wrong encoded decoded registry")
+	}
+
+}
+
+type testCodec struct{}
+
+func (testCodec) Name() string                        { return "test" }
+func (testCodec) Marshal(interface{}) ([]byte, error) { return nil, nil }
+func (testCodec) Unmarshal([]byte, interface{}) error { return nil }
+
+func TestRegistry_CodecName(t *testing.T) {
+	// CodecName() string
+
+	var reg = testRegistry()
+
+	if reg.CodecName() != DefaultCodec.Name() {
+		t.Error("wrong default codec name")
+	}
+
+	var cr = NewRegistry(func(r *Reg) {
+		r.UseCodec(testCodec{})
+		r.Register("test.User", TestUser{})
+		r.Register("test.Group", TestGroup{})
+	})
+
+	if cr.CodecName() != "test" {
+		t.Error("wrong codec name")
+	}
+
+	if cr.Reference() == NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.Group", TestGroup{})
+	}).Reference() {
+		t.Error("codec is not part of the registry")
+	}
+
+	var dec, err = DecodeRegistry(cr.Encode())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dec.CodecName() != "test" {
+		t.Error("codec name lost")
+	}
+
+	if dec.Reference() != cr.Reference() {
+		t.Error("wrong reference of decoded registry")
+	}
+
+	// the DefaultCodec is not encoded
+
+	if _, ok := decodeRegistryCodec(reg.Encode()); ok == true {
+		t.Error("encoding of registry with default codec changed")
+	}
+
 }
 
 func TestRegistry_Reference(t *testing.T) {
@@ -416,3 +499,192 @@ func TestRegistry_userProvidedName(t *testing.T) {
 	}
 
 }
+
+func TestRegistry_nameCollision(t *testing.T) {
+
+	var info = func() interface{} {
+		type Info struct {
+			About string
+		}
+		return Info{}
+	}()
+
+	type Info struct {
+		Note string
+	}
+
+	type Any struct {
+		Info Info
+	}
+
+	t.Run("different fields", func(t *testing.T) {
+		defer func() {
+			var err, ok = recover().(*SchemaNameCollisionError)
+			if ok == false {
+				t.Fatal("missing SchemaNameCollisionError")
+			}
+			if err.Name() != "Info" {
+				t.Error("wrong name:", err.Name())
+			}
+		}()
+
+		NewRegistry(func(r *Reg) {
+			r.Register("Info", info)
+			r.Register("test.Any", Any{})
+		})
+	})
+
+	t.Run("the same fields", func(t *testing.T) {
+		defer shouldNotPanic(t)
+
+		type Brief struct {
+			Note string
+		}
+
+		NewRegistry(func(r *Reg) {
+			r.Register("Info", Brief{})
+			r.Register("test.Any", Any{})
+		})
+	})
+
+}
+
+func TestBuildRegistry(t *testing.T) {
+
+	var (
+		reg *Registry
+		ts  *Types
+		err error
+	)
+
+	reg, ts, err = BuildRegistry(
+		TestIntsStruct{},
+		&TestUintsStruct{}, // pointer
+		TestStringStruct{},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(TestIntsStruct{}),
+		reflect.TypeOf(TestUintsStruct{}),
+		reflect.TypeOf(TestStringStruct{}),
+	} {
+
+		var name = typ.String()
+
+		if sch, err := reg.SchemaByName(name); err != nil {
+			t.Error(err)
+		} else if sch.Name() != name {
+			t.Error("wrong schema name:", sch.Name())
+		}
+
+		if ts.Direct[name] != typ {
+			t.Error("wrong type of", name)
+		}
+
+		if ts.Inverse[typ] != name {
+			t.Error("wrong name of", typ)
+		}
+
+	}
+
+	_, _, err = BuildRegistry(TestIntsStruct{}, &TestIntsStruct{})
+
+	if err != ErrDuplicateType {
+		t.Error("missing or wrong error:", err)
+	}
+
+	for _, val := range []interface{}{nil, Ref{}, []int8{}} {
+		if _, _, err = BuildRegistry(val); err != ErrInvalidType {
+			t.Error("missing or wrong error:", err)
+		}
+	}
+
+	type WithMap struct{ Map map[string]string }
+
+	if _, _, err = BuildRegistry(WithMap{}); err == nil {
+		t.Error("missing error")
+	} else if _, ok := err.(*MapTypeError); ok == false {
+		t.Error("wrong error:", err)
+	}
+
+}
+
+func TestTypes_SchemaReference(t *testing.T) {
+	// SchemaReference(obj interface{}) (ref SchemaRef, err error)
+
+	for _, reg := range []*Registry{testRegistry(), testNestedRegistry()} {
+
+		var ts = reg.Types()
+
+		for typ, name := range ts.Inverse {
+
+			var (
+				obj = reflect.Zero(typ).Interface()
+				sch Schema
+				ref SchemaRef
+				err error
+			)
+
+			if sch, err = reg.SchemaByName(name); err != nil {
+				t.Fatal(err)
+			}
+
+			if ref, err = ts.SchemaReference(obj); err != nil {
+				t.Error(err)
+			} else if ref != sch.Reference() {
+				t.Errorf("wrong reference of %s: %s, want %s", name,
+					ref.Short(), sch.Reference().Short())
+			}
+
+		}
+
+	}
+
+	t.Run("changed", func(t *testing.T) {
+
+		type TestUser struct {
+			Name  string
+			Age   uint32
+			Email string // new field
+		}
+
+		var (
+			reg = NewRegistry(func(r *Reg) {
+				r.Register("test.User", TestUser{})
+			})
+			was, ref SchemaRef
+			sch      Schema
+			err      error
+		)
+
+		if sch, err = testRegistry().SchemaByName("test.User"); err != nil {
+			t.Fatal(err)
+		}
+
+		was = sch.Reference()
+
+		if ref, err = reg.Types().SchemaReference(TestUser{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if ref == was {
+			t.Error("the same reference of changed type")
+		}
+
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+
+		var _, err = testRegistry().Types().SchemaReference(TestInner{})
+
+		if err != ErrTypeNotFound {
+			t.Error("unexpected error:", err)
+		}
+
+	})
+
+}
@@ -0,0 +1,264 @@
+package registry
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// ObjectSchemas walks through the Root and puts Schemas
+// of all objects the Root refers to, to given map (hash
+// -> Schema). Nodes of Refs trees (including the Refs
+// themselves) put with nil Schema. Every object walked
+// once: subtrees of objects that already are in the map
+// are not walked, thus the map can be shared between
+// Root objects. An object that has references is put
+// only if it has been got and decoded; objects that
+// can't be reached (because of a missing Schema or a DB
+// error) are not put. The ObjectSchemas doesn't get and
+// decode objects that have not references, they are put
+// if the object that refers to them is decoded.
+//
+// Objects encoded by non-default Codec are converted using
+// registered types of the Registry (see SchemaData). If
+// a type is not registered (e.g. the Registry obtained
+// from DB), then the ObjectSchemas walks through other
+// objects and returns ErrTypeNotFound
+func (r *Root) ObjectSchemas(
+	pack Pack, //                        : pack to get objects
+	schemas map[cipher.SHA256]Schema, // : the map
+) (
+	err error, //                        : error
+) {
+
+	if pack.Registry() == nil {
+		err = errors.New(
+			"(*registry.Root).ObjectSchemas: missing registry in Pack")
+		return
+	}
+
+	var sw = &schemasWalker{pack: pack, schemas: schemas}
+
+	for i := range r.Refs {
+		sw.dynamic(&r.Refs[i])
+	}
+
+	return sw.err
+}
+
+// A schemasWalker collects Schemas of objects
+// for the ObjectSchemas method of the Root
+type schemasWalker struct {
+	pack    Pack
+	schemas map[cipher.SHA256]Schema
+	err     error // first ErrTypeNotFound
+}
+
+func (s *schemasWalker) dynamic(d *Dynamic) {
+
+	if d.IsValid() == false || d.Hash == (cipher.SHA256{}) {
+		return
+	}
+
+	var sch, err = s.pack.Registry().SchemaByReference(d.Schema)
+
+	if err != nil {
+		return // missing Schema
+	}
+
+	s.hash(sch, d.Hash)
+}
+
+func (s *schemasWalker) hash(sch Schema, hash cipher.SHA256) {
+
+	if hash == (cipher.SHA256{}) {
+		return
+	}
+
+	if _, ok := s.schemas[hash]; ok == true {
+		return // already walked
+	}
+
+	if sch.HasReferences() == false {
+		s.schemas[hash] = sch
+		return // nothing to walk through
+	}
+
+	var (
+		val []byte
+		err error
+	)
+
+	if val, err = s.pack.Get(hash); err != nil {
+		return // can't be reached
+	}
+
+	if val, err = SchemaData(s.pack, sch, val); err != nil {
+		if err == ErrTypeNotFound && s.err == nil {
+			s.err = err // can't be decoded without the type
+		}
+		return
+	}
+
+	s.schemas[hash] = sch
+	s.data(sch, val)
+}
+
+func (s *schemasWalker) data(sch Schema, val []byte) {
+
+	if sch.HasReferences() == false {
+		return
+	}
+
+	if sch.IsReference() == true {
+		s.references(sch, val)
+		return
+	}
+
+	switch sch.Kind() {
+	case reflect.Array, reflect.Slice:
+		s.slice(sch, val)
+	case reflect.Struct:
+		s.structure(sch, val)
+	}
+
+}
+
+func (s *schemasWalker) references(sch Schema, val []byte) {
+
+	switch sch.ReferenceType() {
+
+	case ReferenceTypeSingle:
+
+		var ref Ref
+		if el := sch.Elem(); el != nil &&
+			encoder.DeserializeRaw(val, &ref) == nil {
+
+			s.hash(el, ref.Hash)
+		}
+
+	case ReferenceTypeSlice:
+
+		s.refs(sch, val)
+
+	case ReferenceTypeDynamic:
+
+		var dr Dynamic
+		if encoder.DeserializeRaw(val, &dr) == nil {
+			s.dynamic(&dr)
+		}
+
+	}
+
+}
+
+func (s *schemasWalker) refs(sch Schema, val []byte) {
+
+	var (
+		refs Refs
+		el   Schema
+	)
+
+	if el = sch.Elem(); el == nil {
+		return
+	}
+
+	if encoder.DeserializeRaw(val, &refs) != nil {
+		return
+	}
+
+	if refs.Hash == (cipher.SHA256{}) {
+		return // blank
+	}
+
+	if _, ok := s.schemas[refs.Hash]; ok == true {
+		return // already walked
+	}
+
+	// nodes are walked by the Walk, but elements are walked
+	// by the hash method that skips elements already walked
+
+	refs.Walk(s.pack, el, func(
+		hash cipher.SHA256,
+		depth int,
+	) (
+		deepper bool,
+		err error,
+	) {
+
+		if depth == 0 {
+			s.hash(el, hash)
+			return // don't go deepper, already walked
+		}
+
+		if _, ok := s.schemas[hash]; ok == true {
+			return // already walked
+		}
+
+		s.schemas[hash] = nil
+		return true, nil
+	})
+
+}
+
+func (s *schemasWalker) slice(sch Schema, val []byte) {
+
+	var el Schema
+	if el = sch.Elem(); el == nil {
+		return
+	}
+
+	var (
+		ln, shift, m int
+		err          error
+	)
+
+	if sch.Kind() == reflect.Array {
+		ln = sch.Len()
+	} else {
+		if ln, err = getLength(val); err != nil {
+			return
+		}
+		shift = 4
+	}
+
+	for k := 0; k < ln; k++ {
+
+		if shift > len(val) {
+			return
+		}
+
+		if m, err = el.Size(val[shift:]); err != nil {
+			return
+		}
+
+		s.data(el, val[shift:shift+m])
+		shift += m
+	}
+
+}
+
+func (s *schemasWalker) structure(sch Schema, val []byte) {
+
+	var (
+		shift, m int
+		err      error
+	)
+
+	for _, f := range sch.Fields() {
+
+		if shift > len(val) {
+			return
+		}
+
+		if m, err = f.Schema().Size(val[shift:]); err != nil {
+			return
+		}
+
+		s.data(f.Schema(), val[shift:shift+m])
+		shift += m
+	}
+
+}
@@ -51,6 +51,97 @@ func (r *Root) Tree(pack Pack) (tree string, err error) {
 
 }
 
+// A treePack used to limit depth of a tree and
+// to find Schema of an object by its hash
+type treePack struct {
+	Pack
+	depth int // current depth
+	limit int // depth limit (zero is no limit)
+
+	find  cipher.SHA256 // hash to find
+	found Schema        // Schema of the object found
+}
+
+// Codec of underlying Pack
+func (t *treePack) Codec() Codec {
+	return codecOf(t.Pack)
+}
+
+// MaxTreeDepth of underlying Pack (see DepthPack)
+func (t *treePack) MaxTreeDepth() int {
+	return maxTreeDepth(t.Pack)
+}
+
+// ObjectTree used to print object with given hash
+// and Schema as tree (see also Tree method of the
+// Root). The depth is limit of references the
+// ObjectTree goes through. Zero means no limit.
+// The ObjectTree doesn't use registered types
+func ObjectTree(
+	pack Pack, //          : pack to get objects
+	sch Schema, //         : schema of the object
+	hash cipher.SHA256, // : hash of the object
+	depth int, //          : depth limit
+) (
+	tree string, //        : printed tree
+	err error, //          : error
+) {
+
+	if hash == (cipher.SHA256{}) {
+		err = ErrReferenceRepresentsNil
+		return
+	}
+
+	var val []byte
+	if val, err = pack.Get(hash); err != nil {
+		return
+	}
+
+	var (
+		tp = &treePack{Pack: pack, limit: depth}
+		gt = rootTreeData(tp, sch, val)
+	)
+
+	gt.Name = fmt.Sprintf("%s (%s)", gt.Name, hash.Hex()[:7])
+
+	tree = gotree.StringTree(gt)
+	return
+}
+
+// SchemaOf returns Schema of object with given hash.
+// The SchemaOf walks through the Root to find the
+// object. It returns ErrNotFound if the Root doesn't
+// refer to the object
+func (r *Root) SchemaOf(
+	pack Pack, //          : pack to get objects
+	hash cipher.SHA256, // : hash of the object
+) (
+	sch Schema, //         : schema of the object
+	err error, //          : error
+) {
+
+	if hash == (cipher.SHA256{}) {
+		err = ErrReferenceRepresentsNil
+		return
+	}
+
+	if pack.Registry() == nil {
+		err = errors.New("(*registry.Root).SchemaOf: missing registry in Pack")
+		return
+	}
+
+	var tp = &treePack{Pack: pack, find: hash}
+
+	for _, dr := range r.Refs {
+		if rootTreeDynamic(&dr, tp); tp.found != nil {
+			return tp.found, nil
+		}
+	}
+
+	err = ErrNotFound
+	return
+}
+
 func rootTreeDynamic(d *Dynamic, pack Pack) (it *gotree.GTStructure) {
 
 	it = new(gotree.GTStructure)
@@ -103,11 +194,42 @@ func rootTreeHash(
 
 	it = new(gotree.GTStructure)
 
+	if tp, ok := pack.(*treePack); ok == true {
+
+		if tp.find != (cipher.SHA256{}) {
+
+			if tp.found == nil && tp.find == hash {
+				tp.found = sch
+			}
+
+			if tp.found != nil {
+				return // found, skip all other
+			}
+
+		} else if tp.limit > 0 {
+
+			if tp.depth == tp.limit {
+				it.Name = "(...)"
+				return
+			}
+
+			tp.depth++
+			defer func() { tp.depth-- }()
+
+		}
+
+	}
+
 	if val, err = pack.Get(hash); err != nil {
 		it.Name = "(err) " + err.Error()
 		return
 	}
 
+	if val, err = SchemaData(pack, sch, val); err != nil {
+		it.Name = "(err) " + err.Error()
+		return
+	}
+
 	return rootTreeData(pack, sch, val)
 }
 
@@ -91,6 +91,15 @@ func splitSchemaHash(
 		return
 	}
 
+	if sch.HasReferences() == false {
+		return // no references, no walking
+	}
+
+	if val, err = schemaData(codecOf(s), s.Registry(), sch, val); err != nil {
+		s.Fail(err)
+		return
+	}
+
 	// go deepper
 
 	splitSchemaData(s, sch, val)
@@ -27,6 +27,14 @@ func (*fakePack) Add([]byte) (cipher.SHA256, error) {
 	panic("fake method called")
 }
 
+// MaxTreeDepth of the Splitter, if it's implemented
+func (f *fakePack) MaxTreeDepth() int {
+	if dp, ok := f.s.(interface{ MaxTreeDepth() int }); ok == true {
+		return dp.MaxTreeDepth()
+	}
+	return 0 // no limit
+}
+
 func (*fakePack) Degree() Degree         { return MaxDegree /* any valid */ }
 func (*fakePack) SetDegree(Degree) error { panic("fake method called") }
 func (*fakePack) Flags() (_ Flags)       { return }
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"fmt"
 	"reflect"
 )
 
@@ -20,3 +21,41 @@ func (t *Types) SchemaName(obj interface{}) (name string, err error) {
 	err = ErrTypeNotFound
 	return
 }
+
+// SchemaReference returns reference of Schema of given
+// object. The reference is computed using current
+// definition of type of the object, and names of the
+// Types, thus it can be compared with references of
+// Schemas of stored Registries to detect schema drift
+// (e.g. fields of a struct has been changed). The
+// SchemaReference returns ErrTypeNotFound if type of the
+// object is not registered, and another error if the
+// type can't be registered
+func (t *Types) SchemaReference(obj interface{}) (ref SchemaRef, err error) {
+
+	var name, ok = t.Inverse[typeOf(obj)]
+
+	if ok == false {
+		err = ErrTypeNotFound
+		return
+	}
+
+	// the NewRegistry panics registering invalid types
+	defer func() {
+		if pc := recover(); pc != nil {
+			if err, _ = pc.(error); err == nil {
+				err = fmt.Errorf("%v", pc)
+			}
+			ref = SchemaRef{}
+		}
+	}()
+
+	var reg = NewRegistry(func(r *Reg) {
+		for typ, name := range t.Inverse {
+			r.Register(name, reflect.Zero(typ).Interface())
+		}
+	})
+
+	ref = reg.reg[name].Reference()
+	return
+}
@@ -156,3 +156,24 @@ func testRegistry() (reg *Registry) {
 
 	return
 }
+
+// nested references (not registered by testRegistry)
+
+type TestInner struct {
+	Curator  Ref     `skyobject:"schema=test.User"`
+	Members  Refs    `skyobject:"schema=test.User"`
+	Favorite Dynamic // any
+}
+
+type TestNested struct {
+	Name  string
+	Inner TestInner
+}
+
+func testNestedRegistry() (reg *Registry) {
+	return NewRegistry(func(r *Reg) {
+		r.Register("test.User", TestUser{})
+		r.Register("test.Inner", TestInner{})
+		r.Register("test.Nested", TestNested{})
+	})
+}
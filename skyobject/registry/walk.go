@@ -8,6 +8,71 @@ import (
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 )
 
+// A walkPack used to keep hashes of objects the walking
+// goes through, from the top to current object. The
+// walkPack used to detect cyclic references
+type walkPack struct {
+	Pack
+	path map[cipher.SHA256]struct{}
+}
+
+// Codec of underlying Pack
+func (w *walkPack) Codec() Codec {
+	return codecOf(w.Pack)
+}
+
+// MaxTreeDepth of underlying Pack (see DepthPack)
+func (w *walkPack) MaxTreeDepth() int {
+	return maxTreeDepth(w.Pack)
+}
+
+// Value kept by underlying Pack (see ValuePack)
+func (w *walkPack) Value(
+	hash cipher.SHA256, // :
+	typ reflect.Type, //   :
+) (
+	val reflect.Value, //  :
+	ok bool, //            :
+) {
+	if vp, is := w.Pack.(ValuePack); is == true {
+		return vp.Value(hash, typ)
+	}
+	return
+}
+
+// KeepValue using underlying Pack (see ValuePack)
+func (w *walkPack) KeepValue(hash cipher.SHA256, val reflect.Value) {
+	if vp, ok := w.Pack.(ValuePack); ok == true {
+		vp.KeepValue(hash, val)
+	}
+}
+
+// enterWalkPack wraps given Pack if it's not a walkPack
+// and adds given hash to the path, the enterWalkPack
+// returns ErrCyclicReference if the path already
+// contains the hash
+func enterWalkPack(pack Pack, hash cipher.SHA256) (wp *walkPack, err error) {
+
+	var ok bool
+	if wp, ok = pack.(*walkPack); ok == false {
+		wp = &walkPack{
+			Pack: pack,
+			path: make(map[cipher.SHA256]struct{}),
+		}
+	}
+
+	if _, ok = wp.path[hash]; ok == true {
+		return nil, ErrCyclicReference
+	}
+
+	wp.path[hash] = struct{}{}
+	return
+}
+
+func (w *walkPack) leave(hash cipher.SHA256) {
+	delete(w.path, hash)
+}
+
 // walkSchemaHash walks usng given Schema and
 // hash of the object (the Schema is Schema of the
 // object the hash points to); the WalkFunc is
@@ -32,6 +97,18 @@ func walkSchemaHash(
 		return // nothing to walk through
 	}
 
+	// the object can't refer to itself, but a malformed
+	// objects (where hash doesn't match value) can; thus
+	// we are keeping the path to detect cycles
+
+	var wp *walkPack
+	if wp, err = enterWalkPack(pack, hash); err != nil {
+		return
+	}
+	defer wp.leave(hash)
+
+	pack = wp
+
 	// get object
 
 	var val []byte
@@ -39,6 +116,10 @@ func walkSchemaHash(
 		return
 	}
 
+	if val, err = SchemaData(pack, sch, val); err != nil {
+		return
+	}
+
 	return walkSchemaData(pack, sch, val, walkFunc)
 }
 
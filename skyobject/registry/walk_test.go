@@ -1,3 +1,169 @@
 package registry
 
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
 // TODO (kostyarin): implement walk tests
+
+// fabricated cycle: group B -> group A -> group B,
+// where hash of A is fake; the fakeA is key of A
+func testCyclicGroups(
+	t *testing.T,
+	pack *dummyPack,
+) (
+	dr Dynamic, // : Dynamic that points to B
+) {
+
+	var sch, err = pack.Registry().SchemaByName("test.Group")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		fakeA = cipher.SHA256{1, 2, 3}
+
+		b = TestGroup{
+			Name:      "B",
+			Developer: Dynamic{Hash: fakeA, Schema: sch.Reference()},
+		}
+
+		valB  = encoder.Serialize(&b)
+		hashB = cipher.SumSHA256(valB)
+
+		a = TestGroup{
+			Name:      "A",
+			Developer: Dynamic{Hash: hashB, Schema: sch.Reference()},
+		}
+	)
+
+	pack.Set(hashB, valB)
+	pack.Set(fakeA, encoder.Serialize(&a))
+
+	return Dynamic{Hash: hashB, Schema: sch.Reference()}
+}
+
+func TestWalk_cyclicReference(t *testing.T) {
+
+	var (
+		pack = getTestPack()
+		dr   = testCyclicGroups(t, pack)
+
+		n   int
+		err error
+	)
+
+	err = dr.Walk(pack, func(cipher.SHA256, int) (bool, error) {
+		if n++; n > 100 {
+			t.Fatal("infinity walking")
+		}
+		return true, nil
+	})
+
+	if err != ErrCyclicReference {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the same object twice is not a cycle
+
+	var (
+		usr = TestUser{Name: "Alice"}
+		grp = TestGroup{Name: "group"}
+	)
+
+	if err = grp.Curator.SetValue(pack, &usr); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = grp.Members.AppendValues(pack, &usr, &usr); err != nil {
+		t.Fatal(err)
+	}
+
+	var sch Schema
+	if sch, err = pack.Registry().SchemaByName("test.Group"); err != nil {
+		t.Fatal(err)
+	}
+
+	dr = Dynamic{Schema: sch.Reference()}
+	if err = dr.SetValue(pack, &grp); err != nil {
+		t.Fatal(err)
+	}
+
+	n = 0
+	err = dr.Walk(pack, func(cipher.SHA256, int) (bool, error) {
+		n++
+		return true, nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// group, curator, refs, two members, developer (blank)
+	if n != 6 {
+		t.Error("wrong number of objects walked:", n)
+	}
+
+}
+
+func TestWalk_valuePack(t *testing.T) {
+
+	var (
+		vp  = getTestValuePack()
+		usr = TestUser{Name: "Alice", Age: 19}
+
+		ref Ref
+		err error
+	)
+
+	if err = ref.SetValue(vp, &usr); err != nil {
+		t.Fatal(err)
+	}
+
+	// the walkPack wraps the ValuePack
+
+	var wp *walkPack
+	if wp, err = enterWalkPack(vp, cipher.SHA256{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		var got TestUser
+		if err = ref.Value(wp, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != usr.Name || got.Age != usr.Age {
+			t.Error("wrong value:", got)
+		}
+	}
+
+	if vp.gets != 1 {
+		t.Error("value is not kept by underlying ValuePack:", vp.gets)
+	}
+
+	if len(vp.kept) != 1 {
+		t.Error("wrong number of kept values:", len(vp.kept))
+	}
+
+	// not a ValuePack
+
+	var pack = getTestPack()
+	if err = ref.SetValue(pack, &usr); err != nil {
+		t.Fatal(err)
+	}
+
+	if wp, err = enterWalkPack(pack, cipher.SHA256{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got TestUser
+	if err = ref.Value(wp, &got); err != nil {
+		t.Fatal(err)
+	} else if got.Name != usr.Name || got.Age != usr.Age {
+		t.Error("wrong value:", got)
+	}
+
+}
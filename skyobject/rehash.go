@@ -0,0 +1,110 @@
+package skyobject
+
+import (
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// Rehash re-encodes top-level objects of given Root
+// (e.g. elements of the Refs field of the Root) using
+// current Codec and updates hashes in the Refs if they
+// are different. The Rehash returns number of changed
+// hashes. It never changes the Root if all hashes are
+// actual. Otherwise, the Refs of the Root is replaced
+// with changed copy, thus other Root objects that share
+// the Refs (e.g. Root of a Pack) are not changed.
+//
+// To decode objects, the Rehash requires Registry created
+// from Go types (see registry.NewRegistry). Thus an Unpack
+// with Registry obtained from DB can't be used.
+//
+// The Rehash saves re-encoded objects using Set method of
+// the Unpack. Thus, after the Rehash, the Root should be
+// saved using the Unpack (see Save). The Rehash doesn't
+// walk through objects deeper than the Refs of the Root.
+// The Rehash returns ErrPackWithoutRoot if given Root
+// is nil
+func (u *Unpack) Rehash(r *registry.Root) (changed int, err error) {
+
+	if r == nil {
+		return 0, ErrPackWithoutRoot
+	}
+
+	var (
+		types = u.reg.Types()
+		refs  = r.Refs
+	)
+
+	for i := range refs {
+
+		var dr = &refs[i]
+
+		if dr.IsBlank() == true {
+			continue
+		}
+
+		var hash cipher.SHA256
+		if hash, err = u.rehashDynamic(types, dr); err != nil {
+			return
+		}
+
+		if hash == dr.Hash {
+			continue
+		}
+
+		if changed == 0 {
+			refs = append([]registry.Dynamic(nil), r.Refs...) // copy
+		}
+
+		refs[i].Hash = hash
+		changed++
+
+	}
+
+	r.Refs = refs
+	return
+}
+
+func (u *Unpack) rehashDynamic(
+	types *registry.Types, // : Go types of the Registry
+	dr *registry.Dynamic, //   : the Dynamic
+) (
+	hash cipher.SHA256, //     : actual hash
+	err error, //              : an error
+) {
+
+	var sch registry.Schema
+	if sch, err = u.reg.SchemaByReference(dr.Schema); err != nil {
+		return
+	}
+
+	var typ, ok = types.Direct[sch.Name()]
+
+	if ok == false {
+		err = registry.ErrTypeNotFound
+		return
+	}
+
+	var (
+		obj = reflect.New(typ).Interface()
+		val []byte
+	)
+
+	if err = dr.Value(u, obj); err != nil {
+		return
+	}
+
+	if val, err = u.Codec().Marshal(obj); err != nil {
+		return
+	}
+
+	if hash = cipher.SumSHA256(val); hash == dr.Hash {
+		return // actual
+	}
+
+	err = u.Set(hash, val)
+	return
+}
@@ -0,0 +1,473 @@
+package skyobject
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// ReplaceObject replaces object with given hash with given
+// value and updates all objects that refer to the object
+// directly or indirectly, up to the Root of the Pack. E.g.
+// the ReplaceObject is persistent-tree update. The object
+// can be anything the Root refers to through Ref, Refs and
+// Dynamic references: an element of the Refs of the Root,
+// or an object deep inside the tree.
+//
+// Objects are decoded using their Schemas and only the
+// references are changed; Refs are changed using methods
+// of the Refs. New objects are saved using given Unpack,
+// thus they are tracked by the Unpack and are removed if
+// the Unpack closed without saving. The Unpack must be
+// created by the same Container with the same Registry.
+// The Root of the Pack changed in place, and the
+// ReplaceObject returns hash of the changed Root (see
+// RootHash for details). Thus, after the ReplaceObject,
+// the Root should be saved using the Unpack. If the Time
+// of the Root is not newer than timestamp of last Root
+// of the head, then the ReplaceObject sets it to now,
+// as the Save does. Thus, the returned hash is hash the
+// Save produces, if other Root is not saved to the head
+// before.
+//
+// The ReplaceObject returns ErrPackWithoutRoot if the Pack
+// has not a Root, ErrDifferentDB or ErrDifferentReg if the
+// Unpack doesn't match the Pack, and data.ErrNotFound if
+// the Root doesn't refer to the old object
+func (p *Pack) ReplaceObject(
+	up *Unpack, //            : unpack to save new objects
+	old cipher.SHA256, //     : hash of object to replace
+	newVal []byte, //         : new value of the object
+) (
+	newRoot cipher.SHA256, // : hash of the changed Root
+	err error, //             : an error
+) {
+
+	if p.r == nil {
+		return cipher.SHA256{}, ErrPackWithoutRoot
+	}
+
+	if up.c != p.c {
+		return cipher.SHA256{}, ErrDifferentDB
+	}
+
+	if up.Registry().Reference() != p.reg.Reference() {
+		return cipher.SHA256{}, ErrDifferentReg
+	}
+
+	if old == (cipher.SHA256{}) {
+		return cipher.SHA256{}, data.ErrNotFound
+	}
+
+	var x = replacer{
+		up:   up,
+		old:  old,
+		nh:   cipher.SumSHA256(newVal),
+		done: make(map[cipher.SHA256]cipher.SHA256),
+		path: make(map[cipher.SHA256]struct{}),
+	}
+
+	// new hashes of the Refs of the Root
+
+	var hashes = make([]cipher.SHA256, len(p.r.Refs))
+
+	for i, dr := range p.r.Refs {
+		if hashes[i], err = x.dynamic(dr); err != nil {
+			return
+		}
+	}
+
+	if x.found == false {
+		return cipher.SHA256{}, data.ErrNotFound
+	}
+
+	if err = up.Set(x.nh, newVal); err != nil {
+		return
+	}
+
+	// update the Root
+
+	for i, hash := range hashes {
+		p.r.Refs[i].Hash = hash
+	}
+
+	// the Time the Save keeps
+
+	var last *data.Root
+	if last, err = p.c.lastRootOfHead(p.r.Pub, p.r.Nonce); err != nil {
+		return
+	}
+
+	var r = *p.r // shallow copy
+
+	if last == nil {
+		setNextRoot(&r, 0, cipher.SHA256{}, 0)
+	} else {
+		setNextRoot(&r, last.Seq, last.Hash, last.Time)
+	}
+
+	p.r.Time = r.Time
+	return p.RootHash()
+}
+
+// a replacer replaces references to an object
+// in encoded objects using their Schemas
+type replacer struct {
+	up *Unpack // get and save
+
+	old cipher.SHA256 // object to replace
+	nh  cipher.SHA256 // hash of the new object
+
+	found bool // the old object is found
+
+	done map[cipher.SHA256]cipher.SHA256 // hash -> new hash
+	path map[cipher.SHA256]struct{}      // to detect cycles
+}
+
+// dynamic returns new hash of object of given Dynamic
+func (x *replacer) dynamic(
+	dr registry.Dynamic,
+) (
+	nh cipher.SHA256,
+	err error,
+) {
+
+	if dr.IsValid() == false {
+		return cipher.SHA256{}, registry.ErrInvalidDynamicReference
+	}
+
+	if dr.Hash == (cipher.SHA256{}) {
+		return // nil
+	}
+
+	var sch registry.Schema
+	if sch, err = x.up.Registry().SchemaByReference(dr.Schema); err != nil {
+		return
+	}
+
+	return x.object(sch, dr.Hash)
+}
+
+// object returns new hash of object with given hash
+// and Schema; the hash is the same if the object has
+// not been changed
+func (x *replacer) object(
+	sch registry.Schema, // : Schema of the object
+	hash cipher.SHA256, //  : hash of the object
+) (
+	nh cipher.SHA256, //    : new hash
+	err error, //           : an error
+) {
+
+	if hash == (cipher.SHA256{}) {
+		return // nil
+	}
+
+	if hash == x.old {
+		x.found = true
+		return x.nh, nil
+	}
+
+	var ok bool
+	if nh, ok = x.done[hash]; ok == true {
+		return // already replaced
+	}
+
+	if sch.HasReferences() == false {
+		x.done[hash] = hash
+		return hash, nil
+	}
+
+	if _, ok = x.path[hash]; ok == true {
+		return cipher.SHA256{}, registry.ErrCyclicReference
+	}
+
+	x.path[hash] = struct{}{}
+	defer delete(x.path, hash)
+
+	var val, res []byte
+	if val, err = x.up.Get(hash); err != nil {
+		return
+	}
+
+	// the Schema describes value encoded by the
+	// skycoin encoder (see registry.Codec)
+
+	if val, err = registry.SchemaData(x.up, sch, val); err != nil {
+		return
+	}
+
+	var changed bool
+	if res, changed, err = x.data(sch, val); err != nil {
+		return
+	}
+
+	if changed == false {
+		nh = hash
+	} else if res, err = registry.CodecData(x.up, sch, res); err != nil {
+		return
+	} else if nh, err = x.up.Add(res); err != nil {
+		return
+	}
+
+	x.done[hash] = nh
+	return
+}
+
+// data replaces references in given encoded value
+// of given Schema; the changed is false and the res
+// is the val if nothing has been replaced
+func (x *replacer) data(
+	sch registry.Schema, // : Schema of the value
+	val []byte, //          : encoded value
+) (
+	res []byte, //          : the result
+	changed bool, //        : has been changed
+	err error, //           : an error
+) {
+
+	if sch.HasReferences() == false {
+		return val, false, nil
+	}
+
+	if sch.IsReference() == true {
+		return x.reference(sch, val)
+	}
+
+	switch sch.Kind() {
+
+	case reflect.Array:
+
+		var el registry.Schema
+		if el = sch.Elem(); el == nil {
+			return nil, false, fmt.Errorf("Schema of element of array %q "+
+				"is nil", sch)
+		}
+
+		return x.elements(el, sch.Len(), val, 0)
+
+	case reflect.Slice:
+
+		var el registry.Schema
+		if el = sch.Elem(); el == nil {
+			return nil, false, fmt.Errorf("Schema of element of slice %q "+
+				"is nil", sch)
+		}
+
+		if len(val) < 4 {
+			return nil, false, fmt.Errorf("unexpected end of encoded "+
+				"slice of <%s>", el)
+		}
+
+		var ln uint32
+		if err = encoder.DeserializeRaw(val, &ln); err != nil {
+			return
+		}
+
+		return x.elements(el, int(ln), val, 4)
+
+	case reflect.Struct:
+
+		return x.fields(sch, val)
+
+	}
+
+	return nil, false, fmt.Errorf("invalid Schema to replace in: %s", sch)
+}
+
+// elements replaces references in encoded elements of
+// an array or a slice
+func (x *replacer) elements(
+	el registry.Schema, // : Schema of an element
+	ln int, //             : number of elements
+	val []byte, //         : encoded array or slice
+	shift int, //          : first element
+) (
+	res []byte, //         : the result
+	changed bool, //       : has been changed
+	err error, //          : an error
+) {
+
+	var (
+		parts [][]byte // don't trust the ln
+		start = shift
+	)
+
+	for i := 0; i < ln; i++ {
+
+		var m int
+		if m, err = el.Size(val[shift:]); err != nil {
+			return
+		}
+
+		var part []byte
+		var ch bool
+		if part, ch, err = x.data(el, val[shift:shift+m]); err != nil {
+			return
+		}
+
+		parts = append(parts, part)
+		changed = changed || ch
+		shift += m
+
+	}
+
+	if changed == false {
+		return val, false, nil
+	}
+
+	res = append(res, val[:start]...)
+	for _, part := range parts {
+		res = append(res, part...)
+	}
+
+	return append(res, val[shift:]...), true, nil
+}
+
+// fields replaces references in encoded struct
+func (x *replacer) fields(
+	sch registry.Schema, // : Schema of the struct
+	val []byte, //          : encoded struct
+) (
+	res []byte, //          : the result
+	changed bool, //        : has been changed
+	err error, //           : an error
+) {
+
+	var (
+		parts = make([][]byte, 0, len(sch.Fields()))
+		shift int
+	)
+
+	for _, fl := range sch.Fields() {
+
+		var s int
+		if s, err = fl.Schema().Size(val[shift:]); err != nil {
+			return
+		}
+
+		var part []byte
+		var ch bool
+		if part, ch, err = x.data(fl.Schema(), val[shift:shift+s]); err != nil {
+			return
+		}
+
+		parts = append(parts, part)
+		changed = changed || ch
+		shift += s
+
+	}
+
+	if changed == false {
+		return val, false, nil
+	}
+
+	for _, part := range parts {
+		res = append(res, part...)
+	}
+
+	return append(res, val[shift:]...), true, nil
+}
+
+// reference replaces hashes of encoded Ref, Refs or
+// Dynamic
+func (x *replacer) reference(
+	sch registry.Schema, // : Schema of the reference
+	val []byte, //          : encoded reference
+) (
+	res []byte, //          : the result
+	changed bool, //        : has been changed
+	err error, //           : an error
+) {
+
+	switch rt := sch.ReferenceType(); rt {
+
+	case registry.ReferenceTypeSingle:
+
+		var ref registry.Ref
+		if err = encoder.DeserializeRaw(val, &ref); err != nil {
+			return
+		}
+
+		var nh cipher.SHA256
+		if nh, err = x.object(sch.Elem(), ref.Hash); err != nil {
+			return
+		}
+
+		if nh == ref.Hash {
+			return val, false, nil
+		}
+
+		ref.Hash = nh
+		return encoder.Serialize(&ref), true, nil
+
+	case registry.ReferenceTypeSlice:
+
+		var refs registry.Refs
+		if err = encoder.DeserializeRaw(val, &refs); err != nil {
+			return
+		}
+
+		var hashes []cipher.SHA256
+		if hashes, err = refs.Hashes(x.up); err != nil {
+			return
+		}
+
+		for i, hash := range hashes {
+
+			var nh cipher.SHA256
+			if nh, err = x.object(sch.Elem(), hash); err != nil {
+				return
+			}
+
+			if nh == hash {
+				continue
+			}
+
+			if err = refs.SetHashByIndex(x.up, i, nh); err != nil {
+				return
+			}
+
+			changed = true
+
+		}
+
+		if changed == false {
+			return val, false, nil
+		}
+
+		if err = refs.Rebuild(x.up); err != nil {
+			return
+		}
+
+		return encoder.Serialize(&refs), true, nil
+
+	case registry.ReferenceTypeDynamic:
+
+		var dr registry.Dynamic
+		if err = encoder.DeserializeRaw(val, &dr); err != nil {
+			return
+		}
+
+		var nh cipher.SHA256
+		if nh, err = x.dynamic(dr); err != nil {
+			return
+		}
+
+		if nh == dr.Hash {
+			return val, false, nil
+		}
+
+		dr.Hash = nh
+		return encoder.Serialize(&dr), true, nil
+
+	}
+
+	return nil, false, fmt.Errorf("invalid ReferenceType %d to replace in",
+		sch.ReferenceType())
+}
@@ -0,0 +1,211 @@
+package skyobject
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestPack_ReplaceObject(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		first = Post{"first", "hello"}
+		last  = Post{"last", "bye"}
+		fixed = Post{"last", "see you"}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	// Root -> Feed -> Refs -> Post
+
+	var feed Feed
+	assertNil(t, feed.Posts.AppendValues(up, &first, &last))
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var (
+		userHash = r.Refs[0].Hash
+		feedHash = r.Refs[1].Hash
+		refsHash = feed.Posts.Hash
+		rootHash = r.Hash
+
+		firstHash, lastHash cipher.SHA256
+	)
+
+	firstHash, err = feed.Posts.HashByIndex(up, 0)
+	assertNil(t, err)
+	lastHash, err = feed.Posts.HashByIndex(up, 1)
+	assertNil(t, err)
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	var newRoot cipher.SHA256
+	newRoot, err = pack.ReplaceObject(up, lastHash,
+		encoder.Serialize(&fixed))
+	assertNil(t, err)
+
+	// the Root
+
+	var rh cipher.SHA256
+	rh, err = pack.RootHash()
+	assertNil(t, err)
+
+	assertTrue(t, newRoot == rh, "wrong hash of the Root")
+	assertTrue(t, newRoot != rootHash, "the Root is not changed")
+
+	assertTrue(t, r.Refs[0].Hash == userHash, "unrelated object changed")
+	assertTrue(t, r.Refs[1].Hash != feedHash, "the Feed is not changed")
+
+	// the Feed and the Refs
+
+	var nf Feed
+	assertNil(t, r.Refs[1].Value(pack, &nf))
+
+	assertTrue(t, nf.Head == feed.Head && nf.Info == feed.Info,
+		"wrong Feed")
+	assertTrue(t, nf.Posts.Hash != refsHash, "the Refs is not changed")
+
+	var ph cipher.SHA256
+
+	ph, err = nf.Posts.HashByIndex(pack, 0)
+	assertNil(t, err)
+	assertTrue(t, ph == firstHash, "unrelated object changed")
+
+	ph, err = nf.Posts.HashByIndex(pack, 1)
+	assertNil(t, err)
+	assertTrue(t, ph == cipher.SumSHA256(encoder.Serialize(&fixed)),
+		"wrong hash of the replaced object")
+
+	// save and check the replaced object
+
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Hash == newRoot, "saved Root has another hash")
+
+	var (
+		sr *registry.Root
+		np Post
+	)
+
+	sr, err = c.LastRoot(pk, 1)
+	assertNil(t, err)
+
+	pack, err = c.Pack(sr, nil)
+	assertNil(t, err)
+
+	var sf Feed
+	assertNil(t, sr.Refs[1].Value(pack, &sf))
+	_, err = sf.Posts.ValueByIndex(pack, 1, &np)
+	assertNil(t, err)
+	assertTrue(t, np == fixed, "wrong replaced object")
+
+	// not found
+
+	_, err = pack.ReplaceObject(up, lastHash, encoder.Serialize(&fixed))
+	assertTrue(t, err == data.ErrNotFound, "missing or wrong error")
+
+}
+
+func TestPack_ReplaceObject_hashInData(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		bob   = User{"Bob", 21}
+
+		aliceHash = cipher.SumSHA256(encoder.Serialize(&alice))
+
+		// the Post contains hash of the Alice,
+		// but doesn't refer to the Alice
+		post = Post{"hash", string(aliceHash[:])}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var feed Feed
+	assertNil(t, feed.Posts.AppendValues(up, &post))
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+	assertNil(t, c.Save(up, r))
+
+	assertTrue(t, r.Refs[0].Hash == aliceHash, "wrong hash of the Alice")
+
+	var feedHash = r.Refs[1].Hash
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	_, err = pack.ReplaceObject(up, aliceHash, encoder.Serialize(&bob))
+	assertNil(t, err)
+
+	var bobHash = cipher.SumSHA256(encoder.Serialize(&bob))
+
+	assertTrue(t, r.Refs[0].Hash == bobHash, "the User is not replaced")
+	assertTrue(t, r.Refs[1].Hash == feedHash, "unrelated Feed changed")
+
+	var np Post
+	var nf Feed
+	assertNil(t, r.Refs[1].Value(pack, &nf))
+	_, err = nf.Posts.ValueByIndex(pack, 0, &np)
+	assertNil(t, err)
+	assertTrue(t, np == post, "unrelated Post changed")
+
+	// the new object is tracked by the Unpack and
+	// counted by the Save
+
+	assertNil(t, c.Save(up, r))
+
+	var rc int
+	_, rc, err = c.Get(bobHash, 0)
+	assertNil(t, err)
+	assertTrue(t, rc == 1, fmt.Sprint("wrong rc: ", rc))
+
+	// different Registry
+
+	var other *Unpack
+	other, err = c.Unpack(sk, registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.User", User{})
+	}))
+	assertNil(t, err)
+	defer other.Close()
+
+	_, err = pack.ReplaceObject(other, bobHash, encoder.Serialize(&alice))
+	assertTrue(t, err == ErrDifferentReg, "missing or wrong error")
+
+}
@@ -1,6 +1,7 @@
 package skyobject
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -23,11 +24,20 @@ type Stat struct {
 	// CacheCleaning is average pause of Cache
 	// for cleaning
 	CacheCleaning time.Duration
+	// CacheHits and CacheMisses are total numbers
+	// of reads of objects found in the Cache and
+	// read from DB
+	CacheHits   uint64
+	CacheMisses uint64
 
 	CacheObjects ObjectsStat // cached objects
 	AllObjects   ObjectsStat // all objects
 	UsedObjects  ObjectsStat // used objects
 
+	// Unsaved is number of objects of Unpack
+	// instances that are not saved yet
+	Unsaved int
+
 	// RootsPerSecond is average vlaue of new
 	// Root objects per second.
 	RootsPerSecond float64
@@ -89,6 +99,9 @@ func (c *Container) Stat() (s *Stat) {
 
 	s.CacheCleaning = c.Cache.stat.cacheCleaning()
 
+	s.CacheHits = atomic.LoadUint64(&c.Cache.hits)
+	s.CacheMisses = atomic.LoadUint64(&c.Cache.misses)
+
 	var amount, volume = c.amountVolume() // of cache
 
 	s.CacheObjects.Amount = statutil.Amount(amount)
@@ -104,6 +117,8 @@ func (c *Container) Stat() (s *Stat) {
 	s.AllObjects.Volume = statutil.Volume(all)
 	s.UsedObjects.Volume = statutil.Volume(used)
 
+	s.Unsaved = int(atomic.LoadInt32(&c.unsaved))
+
 	s.RootsPerSecond = c.Index.stat.rootsPerSecond()
 
 	s.Feeds = c.Index.feedsStat()
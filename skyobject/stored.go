@@ -0,0 +1,99 @@
+package skyobject
+
+import (
+	"sort"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// store time of objects (under lock of the Cache)
+type stored struct {
+	enable  bool  // tracking enabled
+	limit   int   // max number of store times
+	dropped int64 // store time of last dropped object
+	timeline
+}
+
+func (s *stored) init(enable bool, limit int) {
+	s.enable, s.limit, s.dropped = enable, limit, 0
+	if enable == true {
+		s.timeline.init()
+	}
+}
+
+func (s *stored) touch(key cipher.SHA256) {
+
+	if s.enable == false {
+		return
+	}
+
+	if dropped := s.timeline.touch(key, s.limit); dropped != 0 {
+		s.dropped = dropped
+	}
+}
+
+// del store time of removed object
+func (s *stored) del(key cipher.SHA256) {
+
+	if s.enable == false {
+		return
+	}
+
+	s.timeline.del(key)
+}
+
+// ObjectsSince returns hashes of objects stored at or
+// after given time, in order they have been stored. An
+// object is stored when it is written to DB being new
+// (or being kept with zero rc). The store times kept
+// in memory, thus objects stored before the Container
+// has been created are never returned. Removed objects
+// (see DelObject) are not returned. The ObjectsSince
+// returns ErrStoredNotTracked if TrackStored field of
+// the Config is false. If store times of objects stored
+// after given time are dropped (see MaxStored), then
+// the ObjectsSince returns ErrStoredDropped with hashes
+// of objects which store times are kept
+func (c *Container) ObjectsSince(
+	t time.Time, //          :
+) (
+	keys []cipher.SHA256, // :
+	err error, //            :
+) {
+
+	c.Cache.mx.Lock()
+	defer c.Cache.mx.Unlock()
+
+	var s = &c.Cache.stored
+
+	if s.enable == false {
+		return nil, ErrStoredNotTracked
+	}
+
+	// the UnixNano is undefined for time before 1678
+	// (e.g. for zero time), and all store times are
+	// after the epoch
+
+	var since int64
+
+	if t.After(time.Unix(0, 0)) == true {
+		since = t.UnixNano()
+	}
+
+	var i = sort.Search(len(s.order), func(i int) bool {
+		return s.order[i].ts >= since
+	})
+
+	for _, ti := range s.order[i:] {
+		if s.actual(ti) == true {
+			keys = append(keys, ti.key)
+		}
+	}
+
+	if s.dropped != 0 && s.dropped >= since {
+		err = ErrStoredDropped
+	}
+
+	return
+}
@@ -0,0 +1,114 @@
+package skyobject
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestContainer_ObjectsSince(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.TrackStored = true
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var set = func(vals ...string) (keys []cipher.SHA256) {
+		for _, val := range vals {
+			var key = cipher.SumSHA256([]byte(val))
+			_, err = c.Set(key, []byte(val), 1)
+			assertNil(t, err)
+			keys = append(keys, key)
+		}
+		return
+	}
+
+	var start = time.Now()
+	var early = set("one", "two")
+
+	time.Sleep(10 * time.Millisecond)
+	var since = time.Now()
+
+	var later = set("three", "four")
+
+	set("one") // existing object, not stored again
+
+	var keys []cipher.SHA256
+	keys, err = c.ObjectsSince(since)
+	assertNil(t, err)
+
+	assertTrue(t, len(keys) == 2, "wrong length")
+	assertTrue(t, keys[0] == later[0] && keys[1] == later[1], "wrong keys")
+
+	keys, err = c.ObjectsSince(start)
+	assertNil(t, err)
+
+	assertTrue(t, len(keys) == 4, "wrong length")
+	assertTrue(t, keys[0] == early[0] && keys[1] == early[1], "wrong order")
+
+	// zero time
+
+	keys, err = c.ObjectsSince(time.Time{})
+	assertNil(t, err)
+	assertTrue(t, len(keys) == 4, "wrong length")
+
+	// removed
+
+	_, err = c.Inc(early[0], -1)
+	assertNil(t, err)
+	_, err = c.Inc(early[0], -1)
+	assertNil(t, err)
+	assertNil(t, c.DelObject(early[0]))
+
+	keys, err = c.ObjectsSince(start)
+	assertNil(t, err)
+
+	assertTrue(t, len(keys) == 3, "wrong length")
+	assertTrue(t, keys[0] == early[1], "removed object returned")
+	assertTrue(t, len(c.Cache.stored.ts) == 3, "store time is not removed")
+
+	// disabled by default
+
+	var d = getTestContainer()
+	defer d.Close()
+
+	_, err = d.ObjectsSince(since)
+	assertTrue(t, err == ErrStoredNotTracked, "wrong error")
+
+}
+
+func TestContainer_ObjectsSince_limit(t *testing.T) {
+
+	var conf = getTestConfig()
+	conf.TrackStored = true
+	conf.MaxStored = 2
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var (
+		start = time.Now()
+		keys  []cipher.SHA256
+	)
+
+	for _, val := range []string{"one", "two", "three"} {
+		var key = cipher.SumSHA256([]byte(val))
+		_, err = c.Set(key, []byte(val), 1)
+		assertNil(t, err)
+		keys = append(keys, key)
+	}
+
+	var got []cipher.SHA256
+	got, err = c.ObjectsSince(start)
+	assertTrue(t, err == ErrStoredDropped, "missing ErrStoredDropped")
+
+	assertTrue(t, len(got) == 2, "wrong length")
+	assertTrue(t, got[0] == keys[1] && got[1] == keys[2], "wrong keys")
+
+	assertTrue(t, len(c.Cache.stored.ts) == 2, "limit exceeded")
+
+}
@@ -0,0 +1,84 @@
+package skyobject
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// SubtreeRoot creates new Root with given Dynamic as the
+// only element of its Refs, and saves the Root in given
+// Container. Objects reachable from the Dynamic are copied
+// from the Pack to the Container, thus the new Root is
+// self-contained; other objects of the Pack are not
+// copied. The new Root uses Registry of the Pack. It's
+// signed by given secret key, and its head is given nonce.
+// The feed (public key of the secret key) must exist in
+// the Container, otherwise the SubtreeRoot returns
+// data.ErrNoSuchFeed. The Container can be the same
+// Container the Pack created by.
+//
+// The Dynamic can be any Dynamic the Pack can walk
+// through, not only an element of Refs of the Root of
+// the Pack
+func (p *Pack) SubtreeRoot(
+	dr registry.Dynamic, // : the subtree
+	dst *Container, //      : container to save to
+	sk cipher.SecKey, //    : owner of the new Root
+	nonce uint64, //        : head of the new Root
+) (
+	r *registry.Root, //    : the new Root
+	err error, //           : an error
+) {
+
+	if dr.IsValid() == false {
+		return nil, registry.ErrInvalidDynamicReference
+	}
+
+	var up *Unpack
+	if up, err = dst.Unpack(sk, p.reg); err != nil {
+		return
+	}
+	defer up.Close()
+
+	// copy objects of the subtree
+
+	err = dr.Walk(p,
+		func(hash cipher.SHA256, _ int) (deepper bool, err error) {
+
+			if hash == (cipher.SHA256{}) {
+				return // nil
+			}
+
+			if _, ok := up.m[hash]; ok == true {
+				return // already copied
+			}
+
+			var val []byte
+			if val, err = p.Get(hash); err != nil {
+				return
+			}
+
+			if err = up.set(hash, val); err != nil {
+				return
+			}
+
+			return true, nil
+		})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r = new(registry.Root)
+
+	r.Pub = cipher.PubKeyFromSecKey(sk)
+	r.Nonce = nonce
+	r.Refs = []registry.Dynamic{dr}
+
+	if err = dst.Save(up, r); err != nil {
+		return nil, err
+	}
+
+	return
+}
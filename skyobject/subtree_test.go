@@ -0,0 +1,112 @@
+package skyobject
+
+import (
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestPack_SubtreeRoot(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		feed  = Feed{Head: "news", Info: "daily"}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	assertNil(t, feed.Posts.AppendValues(up,
+		&Post{"first", "hello"},
+		&Post{"second", "world"},
+	))
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.Feed", &feed),
+	}
+	assertNil(t, c.Save(up, r))
+
+	var pack *Pack
+	pack, err = c.Pack(r, nil)
+	assertNil(t, err)
+
+	var (
+		dst      = getTestContainer()
+		dpk, dsk = cipher.GenerateKeyPair()
+	)
+
+	defer dst.Close()
+
+	// no such feed
+
+	_, err = pack.SubtreeRoot(r.Refs[1], dst, dsk, 1)
+	assertTrue(t, err == data.ErrNoSuchFeed, "missing or wrong error")
+
+	assertNil(t, dst.AddFeed(dpk))
+
+	var sr *registry.Root
+	sr, err = pack.SubtreeRoot(r.Refs[1], dst, dsk, 1)
+	assertNil(t, err)
+
+	assertTrue(t, sr.Pub == dpk, "wrong feed")
+	assertTrue(t, len(sr.Refs) == 1 && sr.Refs[0] == r.Refs[1],
+		"wrong Refs")
+
+	// self-contained
+
+	var complete bool
+	var missing []cipher.SHA256
+	complete, missing, err = dst.RootComplete(sr)
+	assertNil(t, err)
+	assertTrue(t, complete == true && len(missing) == 0, "not complete")
+
+	// the same objects
+
+	var subtree, copied []cipher.SHA256
+
+	assertNil(t, r.Refs[1].Walk(pack,
+		func(hash cipher.SHA256, _ int) (_ bool, _ error) {
+			subtree = append(subtree, hash)
+			return true, nil
+		}))
+
+	var spack *Pack
+	spack, err = dst.Pack(sr, nil)
+	assertNil(t, err)
+
+	copied, err = spack.Manifest()
+	assertNil(t, err)
+
+	assertTrue(t, len(copied) == len(subtree), "wrong number of objects")
+
+	// only the subtree
+
+	_, _, err = dst.Get(r.Refs[0].Hash, 0)
+	assertTrue(t, err == data.ErrNotFound, "object outside the subtree")
+
+	var dec Feed
+	assertNil(t, sr.Refs[0].Value(spack, &dec))
+	assertTrue(t, dec.Head == "news", "wrong value")
+
+	// invalid
+
+	var invalid = registry.Dynamic{Hash: cipher.SHA256{1}}
+	_, err = pack.SubtreeRoot(invalid, dst, dsk, 2)
+	assertTrue(t, err == registry.ErrInvalidDynamicReference,
+		"missing or wrong error")
+
+}
@@ -0,0 +1,85 @@
+package skyobject
+
+import (
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+// timestamp of an object
+type timelineItem struct {
+	key cipher.SHA256
+	ts  int64
+}
+
+// timestamps of objects, strictly increasing and
+// ordered; used for access and store times
+type timeline struct {
+	last  int64                   // last timestamp
+	ts    map[cipher.SHA256]int64 // hash -> timestamp
+	order []timelineItem          // timestamps in order (can be stale)
+}
+
+func (t *timeline) init() {
+	t.last, t.ts, t.order = 0, make(map[cipher.SHA256]int64), nil
+}
+
+// is given item actual (not removed, not touched again)
+func (t *timeline) actual(ti timelineItem) (ok bool) {
+	var ts, exists = t.ts[ti.key]
+	return exists == true && ts == ti.ts
+}
+
+// touch sets timestamp of given object dropping oldest
+// timestamps if there are more then given limit (if the
+// limit is not zero); it returns last dropped timestamp
+// or zero
+func (t *timeline) touch(key cipher.SHA256, limit int) (dropped int64) {
+
+	var now = time.Now().UnixNano()
+
+	if now <= t.last {
+		now = t.last + 1 // keep order
+	}
+
+	t.last = now
+	t.ts[key] = now
+	t.order = append(t.order, timelineItem{key, now})
+
+	for limit > 0 && len(t.ts) > limit {
+		var ti = t.order[0]
+		t.order = t.order[1:]
+		if t.actual(ti) == true {
+			delete(t.ts, ti.key)
+			dropped = ti.ts
+		}
+	}
+
+	t.compact()
+	return
+}
+
+// del timestamp of given object
+func (t *timeline) del(key cipher.SHA256) {
+	delete(t.ts, key)
+	t.compact()
+}
+
+// remove stale items of the order
+// if there are too many of them
+func (t *timeline) compact() {
+
+	if len(t.order) <= 2*len(t.ts)+64 {
+		return
+	}
+
+	var order = make([]timelineItem, 0, len(t.ts))
+
+	for _, ti := range t.order {
+		if t.actual(ti) == true {
+			order = append(order, ti)
+		}
+	}
+
+	t.order = order
+}
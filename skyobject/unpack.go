@@ -2,6 +2,9 @@ package skyobject
 
 import (
 	"errors"
+	"log"
+	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/skycoin/skycoin/src/cipher"
@@ -14,15 +17,36 @@ type unpackItem struct {
 	inc     int  // saved times
 	dec     int  // used times
 	created bool // created
+	added   bool // set by the Unpack
+	existed bool // already existed in DB when set
 }
 
 // An Unpack implements registry.Pack
 // and used to change or cerate a Root
 type Unpack struct {
-	m     map[cipher.SHA256]*unpackItem // hash -> rc
-	c     *Container                    // Set method
-	*Pack                               // other methods
-	sk    cipher.SecKey                 // owner
+	*unsaved               // objects
+	c        *Container    // Set method
+	*Pack                  // other methods
+	sk       cipher.SecKey // owner
+
+	newObjects int // new objects saved by last Save
+	dupObjects int // already existing objects of last Save
+}
+
+// unsaved objects of an Unpack; the Container keeps
+// the unsaved instead of the Unpack, thus an Unpack
+// that is not closed can be collected by GC
+type unsaved struct {
+	c   *Container                    // the Container
+	m   map[cipher.SHA256]*unpackItem // hash -> rc
+	vol int                           // volume of unsaved objects
+	n   int                           // number of unsaved objects
+}
+
+// add given number of unsaved objects
+func (u *unsaved) addUnsaved(n int) {
+	u.n += n
+	atomic.AddInt32(&u.c.unsaved, int32(n))
 }
 
 func (u *Unpack) reset() {
@@ -31,9 +55,57 @@ func (u *Unpack) reset() {
 	}
 }
 
-// Set value
+// keys of objects tracked by the Unpack
+func (u *Unpack) keys() (keys []cipher.SHA256) {
+	keys = make([]cipher.SHA256, 0, len(u.m))
+	for key := range u.m {
+		keys = append(keys, key)
+	}
+	return
+}
+
+// Get value by hash. The Get is Get of the Pack,
+// but a value fetched by the OnMissing callback (see
+// Config) is stored as an unsaved object of the Unpack.
+// Thus, the value is removed if it's not used by a
+// saved Root
+func (u *Unpack) Get(key cipher.SHA256) (val []byte, err error) {
+
+	var fetched bool
+	if val, fetched, err = u.get(key); err == nil && fetched == true {
+		err = u.set(key, val) // ignore the limits
+	}
+
+	return
+}
+
+// Set value. It returns ErrUnsavedLimit if the
+// value exceeds MaxUnsaved or MaxUnsavedVolume
+// limit (see Config)
 func (u *Unpack) Set(key cipher.SHA256, val []byte) (err error) {
 
+	if _, ok := u.m[key]; ok == false {
+
+		var conf = u.c.conf
+
+		if conf.MaxUnsaved > 0 && len(u.m) >= conf.MaxUnsaved {
+			return ErrUnsavedLimit
+		}
+
+		if conf.MaxUnsavedVolume > 0 &&
+			u.vol+len(val) > conf.MaxUnsavedVolume {
+
+			return ErrUnsavedLimit
+		}
+
+	}
+
+	return u.set(key, val)
+}
+
+// set value ignoring the limits
+func (u *Unpack) set(key cipher.SHA256, val []byte) (err error) {
+
 	var rc int
 	if rc, err = u.c.Set(key, val, 1); err != nil {
 		return
@@ -44,6 +116,13 @@ func (u *Unpack) Set(key cipher.SHA256, val []byte) (err error) {
 	if ok == false {
 		ui = new(unpackItem)
 		u.m[key] = ui
+		u.vol += len(val)
+		u.addUnsaved(1)
+	}
+
+	if ui.added == false {
+		ui.added = true
+		ui.existed = (rc > 1)
 	}
 
 	ui.inc++
@@ -61,6 +140,178 @@ func (u *Unpack) Add(val []byte) (key cipher.SHA256, err error) {
 
 }
 
+// AppendUnique appends given object to given Refs if
+// the Refs doesn't contain the object. See AppendUnique
+// method of the Pack for details. Objects are saved
+// using Set method of the Unpack
+func (u *Unpack) AppendUnique(
+	refs *registry.Refs, // : the Refs
+	obj interface{}, //     : object to append
+) (
+	added bool, //          : appended
+	err error, //           : an error
+) {
+	return appendUnique(u, u.Codec(), refs, obj)
+}
+
+// ForEachUnsaved calls given function for every object
+// of the Unpack that is not saved yet. Order is random.
+// Return false from the function to stop the iteration.
+// The function must not add objects to the Unpack
+func (u *Unpack) ForEachUnsaved(
+	fn func(hash cipher.SHA256, val []byte) (next bool), // :
+) (
+	err error, //                                            :
+) {
+
+	var val []byte
+
+	for key, ui := range u.m {
+
+		if ui.inc == 0 {
+			continue // not added by the Unpack
+		}
+
+		if val, _, err = u.c.Get(key, 0); err != nil {
+			return
+		}
+
+		if fn(key, val) == false {
+			return
+		}
+
+	}
+
+	return
+}
+
+// Merge moves unsaved objects of given Unpack to this
+// one. Thus, changes made by both can be saved by single
+// Save. The other Unpack is empty after the Merge and
+// should be closed. Both Unpack instances must be created
+// by the same Container with the same Registry. Otherwise
+// the Merge returns ErrDifferentDB or ErrDifferentReg.
+// The Merge doesn't change Root objects, use Refs of
+// a Root to refer to merged objects
+func (u *Unpack) Merge(other *Unpack) (err error) {
+
+	if u == other {
+		return // the same
+	}
+
+	if u.c != other.c {
+		return ErrDifferentDB
+	}
+
+	if u.Registry().Reference() != other.Registry().Reference() {
+		return ErrDifferentReg
+	}
+
+	for key, oi := range other.m {
+
+		if ui, ok := u.m[key]; ok == true {
+			ui.inc += oi.inc // rc of the object is already incremented
+			ui.dec += oi.dec
+			ui.created = ui.created || oi.created
+		} else {
+			u.m[key] = oi
+		}
+
+		delete(other.m, key)
+
+	}
+
+	u.vol, other.vol = u.vol+other.vol, 0
+	u.n, other.n = u.n+other.n, 0 // the same total
+
+	return
+}
+
+// Touch sets given value to element i of the Refs of
+// the Root the Unpack saves. The value is encoded by the
+// Save. Objects are immutable, and a value kept by the
+// Unpack (see Value and KeepValue) must not be changed
+// in place. Thus, get the value, change it and Touch
+// the element with the changed value. The object must
+// be of registered type, or nil to make the element
+// blank. Schema of the element is replaced with Schema
+// of the object. The Touch returns registry.ErrIndexOutOfRange
+// if given index is negative, or the Save returns it
+// if the index is out of the Refs of the Root
+func (u *Unpack) Touch(i int, obj interface{}) (err error) {
+
+	if i < 0 || (u.r != nil && i >= len(u.r.Refs)) {
+		return registry.ErrIndexOutOfRange
+	}
+
+	if obj != nil {
+		if _, err = u.Registry().Types().SchemaName(obj); err != nil {
+			return
+		}
+	}
+
+	if u.touched == nil {
+		u.touched = make(map[int]interface{})
+	}
+
+	u.touched[i] = obj
+	return
+}
+
+// encode values of touched elements of the
+// Refs of given Root (see Touch)
+func (u *Unpack) saveTouched(r *registry.Root) (err error) {
+
+	if len(u.touched) == 0 {
+		return
+	}
+
+	for i := range u.touched {
+		if i >= len(r.Refs) {
+			return registry.ErrIndexOutOfRange
+		}
+	}
+
+	var types = u.Registry().Types()
+
+	for i, obj := range u.touched {
+
+		var dr = &r.Refs[i]
+
+		if obj == nil {
+			dr.Clear()
+			continue
+		}
+
+		var name string
+		if name, err = types.SchemaName(obj); err != nil {
+			return
+		}
+
+		var sch registry.Schema
+		if sch, err = u.Registry().SchemaByName(name); err != nil {
+			return
+		}
+
+		var enc []byte
+		if enc, err = u.Codec().Marshal(obj); err != nil {
+			return
+		}
+
+		var hash = cipher.SumSHA256(enc)
+		if err = u.set(hash, enc); err != nil { // ignore the limits
+			return
+		}
+
+		dr.Schema = sch.Reference()
+		dr.Hash = hash
+
+	}
+
+	u.touched = nil
+	return
+}
+
 // Unpack creates Unpack using given registry. Use
 // the Unapck to modify a Root object and to save
 // cahnges after.
@@ -72,34 +323,132 @@ func (c *Container) Unpack(
 	err error,
 ) {
 
+	if c.conf.ReadOnly == true {
+		err = ErrViewOnlyTree
+		return
+	}
+
 	if reg == nil {
 		err = errors.New("Registry is nil")
 		return
 	}
 
+	if err = c.checkCodec(reg); err != nil {
+		return
+	}
+
 	if err = sk.Verify(); err != nil {
 		return
 	}
 
 	up = &Unpack{
+		unsaved: &unsaved{
+			c: c,
+			m: make(map[cipher.SHA256]*unpackItem),
+		},
 		sk:   sk,
 		c:    c,
-		m:    make(map[cipher.SHA256]*unpackItem),
 		Pack: c.getPack(reg),
 	}
 
+	c.addUnpack(up) // track
+
 	c.AddRegistryToCache(reg) // cache
 
 	return
 
 }
 
+// setNextRoot sets Seq, Prev and Time fields of given
+// Root to follow last Root of its head. The lastHash is
+// blank if the head is empty. The Time is kept if it's
+// greater than the lastTime, otherwise it's set to now
+// (strictly newer than the lastTime)
+func setNextRoot(
+	r *registry.Root, //       : the Root
+	lastSeq uint64, //         : seq of last Root
+	lastHash cipher.SHA256, // : hash of last Root
+	lastTime int64, //         : timestamp of last Root
+) {
+
+	if lastHash != (cipher.SHA256{}) {
+		r.Seq = lastSeq + 1
+		r.Prev = lastHash
+	} else {
+		r.Seq = 0
+		r.Prev = cipher.SHA256{}
+	}
+
+	if r.Time > lastTime {
+		return // keep
+	}
+
+	if r.Time = time.Now().UnixNano(); r.Time <= lastTime {
+		r.Time = lastTime + 1 // strictly newer
+	}
+}
+
 // Save cahnges of given Root updating seq number and
 // timestamp of the Root. The Root should have correct
 // Pub, and Nonce fields. The Seq field will be set
 // to next inside the Save. The Save also set Hash and
-// Prev fields of the Root, and signs the Root
+// Prev fields of the Root, and signs the Root.
+//
+// The Time field of given Root is an input of the Save.
+// If it's greater than timestamp of last Root of the
+// head, then the Save keeps it. Otherwise (for example,
+// if it's zero or it's the Time of a saved Root), the
+// Save sets it to now, strictly newer than the last
+// Root. Set the Time before the Save to get predictable
+// hash of the Root (see (*Pack).RootHash).
+//
+// The Save returns ErrStaleRoot if given Root is an old
+// version of a Root of the head (it has been saved,
+// but there is a newer Root in the head)
 func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
+	return c.save(up, r, nil)
+}
+
+// CompareAndSave saves given Root (like the Save) only
+// if hash of last Root of the head (feed and nonce of
+// given Root) is equal to given expected hash. Use
+// blank hash if the head should be empty. The hash is
+// checked inside the same transaction the Root saved
+// in. Thus, the CompareAndSave doesn't clobber Root
+// objects saved concurrently. It returns false and
+// no error if the hash doesn't match. Objects of the
+// Unpack are kept in this case, and the Root can be
+// changed and saved again
+func (c *Container) CompareAndSave(
+	up *Unpack, //             : objects of the Root
+	r *registry.Root, //       : the Root to save
+	expected cipher.SHA256, // : hash of last Root of the head
+) (
+	saved bool, //             : false if the hash doesn't match
+	err error, //              : an error
+) {
+
+	if err = c.save(up, r, &expected); err == errRootMismatch {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// save the Root; if the expected is not nil, then
+// hash of last Root of the head must be equal to it,
+// otherwise the save returns errRootMismatch
+func (c *Container) save(
+	up *Unpack, //              : objects of the Root
+	r *registry.Root, //        : the Root to save
+	expected *cipher.SHA256, // : optional hash of last Root
+) (
+	err error, //               : an error
+) {
+
+	if c.conf.ReadOnly == true {
+		return ErrViewOnlyTree
+	}
 
 	// save the Root recursive
 
@@ -135,6 +484,10 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 		}
 	}()
 
+	if err = up.saveTouched(r); err != nil {
+		return
+	}
+
 	for _, dr := range r.Refs {
 
 		err = dr.Walk(up, func(
@@ -198,31 +551,65 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 		return data.ErrNoSuchFeed
 	}
 
+	if c.wal.used() == true {
+
+		// finish previous Save if its WAL record is kept
+		if err = c.wal.finish(c); err != nil {
+			return
+		}
+
+		// the Cache is write-behind, but the rc of the objects
+		// must count the Root before it committed, since the WAL
+		// can't restore the rc later; decrements below can be
+		// lost, but it's a leak, not a loss
+		if err = c.flush(up.keys()); err != nil {
+			return
+		}
+
+	}
+
 	// save into Index and IdxDB
 	var val []byte
 
-	if val, err = c.Index.saveRoot(up, r); err != nil {
+	if val, err = c.Index.saveRoot(up, r, expected); err != nil {
+		// the errRootMismatch is not a failure (see CompareAndSave)
+		if c.conf.OnSaveRollback != nil && err != errRootMismatch {
+			c.conf.OnSaveRollback(err) // the transaction rolled back
+		}
 		return
 	}
 
-	// save the Root in CXDS
+	// keep the WAL record to finish the Save later
+	// if an error occurred
+	defer func() { c.wal.end(err != nil) }()
+
+	// save registry (before the Root, see WAL)
 
-	if err = up.Set(r.Hash, val); err != nil {
+	if err = up.set(cipher.SHA256(r.Reg), up.Registry().Encode()); err != nil {
 		return
 	}
 
-	// save registry
+	// save the Root in CXDS
 
-	if err = up.Set(cipher.SHA256(r.Reg), up.Registry().Encode()); err != nil {
+	if err = up.set(r.Hash, val); err != nil {
 		return
 	}
 
 	// make rc of related objects actual
 
+	var newObjects, dupObjects int // see SaveStat
+
 	for key, ui := range up.m {
 
 		if key == r.Hash || key == cipher.SHA256(r.Reg) {
 			ui.dec++
+		} else if ui.added == true && ui.dec > 0 {
+			if ui.existed == true {
+				dupObjects++
+			} else {
+				newObjects++
+				c.committed.notify(r.Pub, key) // see Committed
+			}
 		}
 
 		var inc = ui.dec - ui.inc
@@ -238,12 +625,18 @@ func (c *Container) Save(up *Unpack, r *registry.Root) (err error) {
 
 	}
 
+	up.newObjects, up.dupObjects = newObjects, dupObjects
+
+	up.vol = 0 // reset the limits
+	up.addUnsaved(-up.n)
+
 	return
 }
 
 func (i *Index) saveRoot(
 	up *Unpack,
 	r *registry.Root,
+	expected *cipher.SHA256,
 ) (
 	val []byte,
 	err error,
@@ -253,7 +646,10 @@ func (i *Index) saveRoot(
 	defer i.mx.Unlock()
 
 	// val []byte --> encoded Root
-	var dr = new(data.Root)
+	var (
+		dr       = new(data.Root)
+		walBegun bool
+	)
 
 	err = i.c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
 		var hs data.Heads
@@ -268,12 +664,14 @@ func (i *Index) saveRoot(
 		var (
 			lastSeq  uint64
 			lastHash cipher.SHA256
+			lastTime int64
 		)
 
 		// get last
 		err = roots.Descend(func(dr *data.Root) (err error) {
 			lastSeq = dr.Seq
 			lastHash = dr.Hash
+			lastTime = dr.Time
 			return data.ErrStopIteration // enough
 		})
 
@@ -281,14 +679,19 @@ func (i *Index) saveRoot(
 			return
 		}
 
-		if lastHash != (cipher.SHA256{}) {
-			r.Seq = lastSeq + 1
-			r.Prev = lastHash
+		if expected != nil && *expected != lastHash {
+			return errRootMismatch // see CompareAndSave
 		}
 
-		// else -> 0 and blank
+		// an old version of a Root of the head (or
+		// the last Root with stale seq number)
+		if lastHash != (cipher.SHA256{}) && r.Hash != (cipher.SHA256{}) &&
+			r.Seq <= lastSeq && (r.Hash != lastHash || r.Seq != lastSeq) {
 
-		r.Time = time.Now().UnixNano()
+			return ErrStaleRoot
+		}
+
+		setNextRoot(r, lastSeq, lastHash, lastTime)
 
 		// hash of the Root
 
@@ -306,11 +709,32 @@ func (i *Index) saveRoot(
 		dr.Sig = r.Sig
 		dr.Time = r.Time
 
+		err = i.c.wal.begin(&walRecord{
+			Pub:   r.Pub,
+			Nonce: r.Nonce,
+			Seq:   r.Seq,
+			Prev:  r.Prev,
+			Hash:  r.Hash,
+			Sig:   r.Sig,
+			Time:  r.Time,
+			Root:  val,
+			Reg:   up.Registry().Encode(),
+		})
+
+		if err != nil {
+			return
+		}
+
+		walBegun = true
+
 		return roots.Set(dr) // save
 
 	})
 
 	if err != nil {
+		if walBegun == true {
+			i.c.wal.end(false) // not committed
+		}
 		return
 	}
 
@@ -341,9 +765,31 @@ func (c *Container) NewRoot(
 
 */
 
+// SaveStat returns number of new objects and number
+// of objects already existing in DB, the last successful
+// Save (or CompareAndSave) of the Unpack has saved.
+// Only objects set by the Unpack and used by the Root
+// are counted. The Root and its Registry are not counted
+func (u *Unpack) SaveStat() (newObjects, dupObjects int) {
+	return u.newObjects, u.dupObjects
+}
+
 // Close the Unpack, rejecting all saved objects that
 // will not be used
 func (u *Unpack) Close() (err error) {
+	runtime.SetFinalizer(u, nil)
+	u.c.delUnpack(u.unsaved)
+	return u.reject()
+}
+
+// IsDirty returns true if the Unpack has objects
+// that are not saved yet
+func (u *Unpack) IsDirty() (dirty bool) {
+	return len(u.m) != 0
+}
+
+// reject objects keeping them in DB with zero rc
+func (u *unsaved) reject() (err error) {
 	for key, ui := range u.m {
 		if ui.inc > 0 {
 			if _, err = u.c.Inc(key, -ui.inc); err != nil {
@@ -351,6 +797,103 @@ func (u *Unpack) Close() (err error) {
 			}
 		}
 	}
-	u.m = nil
+	u.m, u.vol = nil, 0
+	u.addUnsaved(-u.n)
+	return
+}
+
+// reject objects and remove created
+// objects that are not used by others
+func (u *unsaved) remove() (err error) {
+
+	var created []cipher.SHA256
+
+	for key, ui := range u.m {
+		if ui.created == true {
+			created = append(created, key)
+		}
+	}
+
+	if err = u.reject(); err != nil {
+		return
+	}
+
+	for _, key := range created {
+		err = u.c.DelObject(key)
+		if err != nil && err != ErrObjectIsUsed && err != data.ErrNotFound {
+			return
+		}
+	}
+
+	return nil
+}
+
+func (c *Container) addUnpack(up *Unpack) {
+	c.upmx.Lock()
+	defer c.upmx.Unlock()
+
+	c.ups[up.unsaved] = struct{}{}
+	runtime.SetFinalizer(up, (*Unpack).finalize)
+}
+
+// delUnpack returns false if the Unpack
+// is not tracked by the Container
+func (c *Container) delUnpack(u *unsaved) (ok bool) {
+	c.upmx.Lock()
+	defer c.upmx.Unlock()
+
+	if _, ok = c.ups[u]; ok == true {
+		delete(c.ups, u)
+	}
+	return
+}
+
+// finalize an Unpack that is not closed
+func (u *Unpack) finalize() {
+
+	if u.c.delUnpack(u.unsaved) == false || u.IsDirty() == false {
+		return // closed or clean
+	}
+
+	log.Printf("[WRN] Unpack is not closed: reject %d unsaved objects",
+		len(u.m))
+
+	if err := u.remove(); err != nil {
+		log.Print("[ERR] rejecting objects of Unpack: ", err)
+	}
+
+}
+
+// closeUnpacks removes objects of dirty Unpack
+// instances or keeps them if FlushOnClose is set
+func (c *Container) closeUnpacks() (err error) {
+
+	c.upmx.Lock()
+	defer c.upmx.Unlock()
+
+	for u := range c.ups {
+
+		delete(c.ups, u)
+
+		if len(u.m) == 0 {
+			continue
+		}
+
+		if c.conf.FlushOnClose == true {
+			if err = u.reject(); err != nil { // keep with zero rc
+				return
+			}
+			continue
+		}
+
+		log.Printf("[WRN] closing Container: reject %d unsaved objects",
+			len(u.m))
+
+		if err = u.remove(); err != nil {
+			return
+		}
+
+	}
+
 	return
 }
@@ -0,0 +1,691 @@
+package skyobject
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/data/cxds"
+	"github.com/skycoin/cxo/data/idxdb"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+type Chain struct {
+	Name string
+	Next registry.Dynamic
+}
+
+func TestContainer_Save_cyclicReference(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		reg = registry.NewRegistry(func(r *registry.Reg) {
+			r.Register("test.Chain", Chain{})
+		})
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, reg)
+	assertNil(t, err)
+	defer up.Close()
+
+	var sch registry.Schema
+	sch, err = reg.SchemaByName("test.Chain")
+	assertNil(t, err)
+
+	// fabricated cycle B -> A -> B, where hash of A is fake
+
+	var (
+		fakeA = cipher.SHA256{1, 2, 3}
+
+		b = Chain{"B", registry.Dynamic{Hash: fakeA, Schema: sch.Reference()}}
+
+		valB  = encoder.Serialize(&b)
+		hashB = cipher.SumSHA256(valB)
+
+		a = Chain{"A", registry.Dynamic{Hash: hashB, Schema: sch.Reference()}}
+	)
+
+	assertNil(t, up.Set(hashB, valB))
+	assertNil(t, up.Set(fakeA, encoder.Serialize(&a)))
+
+	var r = new(registry.Root)
+
+	r.Pub = pk
+	r.Nonce = 9021
+	r.Refs = []registry.Dynamic{{Hash: hashB, Schema: sch.Reference()}}
+
+	if err = c.Save(up, r); err != registry.ErrCyclicReference {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = c.LastRoot(pk, r.Nonce)
+	assertTrue(t, err != nil, "the Root saved")
+
+}
+
+func TestContainer_Save_registry(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	for nonce := uint64(1); nonce <= 2; nonce++ {
+
+		var r = new(registry.Root)
+		r.Pub, r.Nonce = pk, nonce
+		r.Refs = []registry.Dynamic{
+			createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+		}
+		assertNil(t, c.Save(up, r))
+
+		var val []byte
+		val, _, err = c.Get(cipher.SHA256(r.Reg), 0)
+		assertNil(t, err)
+
+		var reg *registry.Registry
+		reg, err = registry.DecodeRegistry(val)
+		assertNil(t, err)
+
+		assertTrue(t, reg.Reference() == testRegistry.Reference(),
+			"wrong Registry saved")
+
+	}
+
+}
+
+func BenchmarkContainer_Save(b *testing.B) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	if err := c.AddFeed(pk); err != nil {
+		b.Fatal(err)
+	}
+
+	var up, err = c.Unpack(sk, testRegistry)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err = c.Save(up, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+}
+
+func TestContainer_Save_seq(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var (
+		r    = new(registry.Root)
+		prev registry.Root
+	)
+
+	r.Pub, r.Nonce = pk, 1
+	r.Seq = 10 // ignored for first Root of a head
+
+	for seq := uint64(0); seq < 5; seq++ {
+
+		assertNil(t, c.Save(up, r))
+
+		assertTrue(t, r.Seq == seq, "wrong seq")
+
+		if seq == 0 {
+			assertTrue(t, r.Prev == (cipher.SHA256{}), "not blank Prev")
+		} else {
+			assertTrue(t, r.Prev == prev.Hash, "wrong Prev")
+			assertTrue(t, r.Time > prev.Time, "not newer")
+		}
+
+		prev = *r
+
+		// the same Root (but a copy) from DB
+		var lr *registry.Root
+		lr, err = c.LastRoot(pk, 1)
+		assertNil(t, err)
+		assertTrue(t, lr.Hash == r.Hash && lr.Seq == seq, "wrong last Root")
+
+	}
+
+}
+
+func TestContainer_Save_time(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+
+	// zero Time, set to now
+
+	var before = time.Now().UnixNano()
+	assertNil(t, c.Save(up, r))
+	var after = time.Now().UnixNano()
+
+	assertTrue(t, r.Time >= before && r.Time <= after, "Time is not now")
+
+	// given Time, greater than the last, kept
+
+	var given = r.Time + int64(time.Hour)
+
+	r.Time = given
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Time == given, "given Time is not kept")
+
+	// stale Time, set to strictly newer
+
+	var last = r.Time
+
+	r.Time = last - 1
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Time > last, "not newer")
+
+	// the same Time (of the saved Root), set to strictly newer
+
+	last = r.Time
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Time > last, "not newer")
+
+}
+
+func TestContainer_Save_stale(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+
+	assertNil(t, c.Save(up, r)) // seq 0
+
+	var old = *r // copy of Root with seq 0
+
+	assertNil(t, c.Save(up, r)) // seq 1
+
+	assertTrue(t, c.Save(up, &old) == ErrStaleRoot, "missing ErrStaleRoot")
+
+	// manual seq
+
+	old = *r
+	old.Seq = 0
+
+	assertTrue(t, c.Save(up, &old) == ErrStaleRoot, "missing ErrStaleRoot")
+
+	// last Root can be saved
+
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, r.Seq == 2, "wrong seq")
+
+}
+
+func TestUnpack_Merge(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		bob   = User{"Bob", 20}
+		eva   = User{"Eva", 21}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up1, up2 *Unpack
+	var err error
+
+	up1, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up1.Close()
+
+	up2, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up2.Close()
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up1, testRegistry, "test.User", &alice),
+		createDynamic(up2, testRegistry, "test.User", &bob),
+		createDynamic(up2, testRegistry, "test.User", &eva),
+		createDynamic(up1, testRegistry, "test.User", &eva), // the same
+	}
+
+	assertNil(t, up1.Merge(up2))
+	assertTrue(t, up2.IsDirty() == false, "not moved")
+
+	assertNil(t, c.Save(up1, r))
+	assertTrue(t, up1.IsDirty() == false, "not saved")
+
+	for i, usr := range []User{alice, bob, eva, eva} {
+
+		var (
+			hash   = r.Refs[i].Hash
+			val    []byte
+			rc     int
+			stored User
+		)
+
+		val, rc, err = c.Get(hash, 0)
+		assertNil(t, err)
+
+		assertNil(t, encoder.DeserializeRaw(val, &stored))
+		assertTrue(t, stored == usr, "wrong value")
+
+		if usr == eva {
+			assertTrue(t, rc == 2, fmt.Sprint("wrong rc: ", rc))
+		} else {
+			assertTrue(t, rc == 1, fmt.Sprint("wrong rc: ", rc))
+		}
+
+	}
+
+	// different Registry
+
+	var up3 *Unpack
+	up3, err = c.Unpack(sk, registry.NewRegistry(func(r *registry.Reg) {
+		r.Register("test.Post", Post{})
+	}))
+	assertNil(t, err)
+	defer up3.Close()
+
+	assertTrue(t, up1.Merge(up3) == ErrDifferentReg, "missing ErrDifferentReg")
+
+	// different Container
+
+	var c2 = getTestContainer()
+	defer c2.Close()
+
+	var up4 *Unpack
+	up4, err = c2.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up4.Close()
+
+	assertTrue(t, up1.Merge(up4) == ErrDifferentDB, "missing ErrDifferentDB")
+
+}
+
+func TestUnpack_MaxUnsaved(t *testing.T) {
+
+	var conf = getTestConfig()
+
+	conf.MaxUnsaved = 3
+	conf.MaxUnsavedVolume = 1024
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var pk, sk = cipher.GenerateKeyPair()
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var add = func(i int) (err error) {
+		_, err = up.Add(encoder.Serialize(User{fmt.Sprint("User #", i), 21}))
+		return
+	}
+
+	t.Run("amount", func(t *testing.T) {
+
+		for i := 0; i < conf.MaxUnsaved; i++ {
+			assertNil(t, add(i))
+		}
+
+		assertTrue(t, add(conf.MaxUnsaved) == ErrUnsavedLimit,
+			"missing ErrUnsavedLimit")
+
+		// the same object again
+		assertNil(t, add(0))
+
+	})
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+
+	assertNil(t, c.Save(up, r))
+
+	t.Run("reset", func(t *testing.T) {
+		assertNil(t, add(conf.MaxUnsaved))
+	})
+
+	assertNil(t, c.Save(up, r))
+
+	t.Run("volume", func(t *testing.T) {
+
+		var large = make([]byte, conf.MaxUnsavedVolume)
+
+		_, err = up.Add(large[:conf.MaxUnsavedVolume/2])
+		assertNil(t, err)
+
+		_, err = up.Add(large)
+		assertTrue(t, err == ErrUnsavedLimit, "missing ErrUnsavedLimit")
+
+	})
+
+}
+
+func TestUnpack_ForEachUnsaved(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var pending = make(map[cipher.SHA256][]byte)
+
+	for _, usr := range []User{{"Alice", 19}, {"Bob", 20}, {"Eva", 21}} {
+		var (
+			val = encoder.Serialize(usr)
+			key cipher.SHA256
+		)
+		key, err = up.Add(val)
+		assertNil(t, err)
+		pending[key] = val
+	}
+
+	var seen = make(map[cipher.SHA256]int)
+
+	err = up.ForEachUnsaved(func(hash cipher.SHA256, val []byte) bool {
+		seen[hash]++
+		assertTrue(t, string(pending[hash]) == string(val), "wrong value")
+		return true
+	})
+	assertNil(t, err)
+
+	assertTrue(t, len(seen) == len(pending), "wrong number of objects")
+
+	for hash, times := range seen {
+		assertTrue(t, times == 1, fmt.Sprint("visited times: ", times))
+		_, ok := pending[hash]
+		assertTrue(t, ok == true, "unexpected object")
+	}
+
+	// stop
+
+	var visited int
+
+	err = up.ForEachUnsaved(func(cipher.SHA256, []byte) bool {
+		visited++
+		return false
+	})
+	assertNil(t, err)
+	assertTrue(t, visited == 1, "not stopped")
+
+	// saved
+
+	assertNil(t, c.Save(up, &registry.Root{Pub: pk, Nonce: 1}))
+
+	err = up.ForEachUnsaved(func(cipher.SHA256, []byte) bool {
+		t.Error("visit saved object")
+		return true
+	})
+	assertNil(t, err)
+
+}
+
+func TestContainer_CompareAndSave(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var (
+		r     = &registry.Root{Pub: pk, Nonce: 1}
+		saved bool
+	)
+
+	// empty head
+
+	saved, err = c.CompareAndSave(up, r, cipher.SHA256{})
+	assertNil(t, err)
+	assertTrue(t, saved == true, "not saved")
+
+	var first = r.Hash
+
+	// stale expected
+
+	var other = &registry.Root{Pub: pk, Nonce: 1}
+	other.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	saved, err = c.CompareAndSave(up, other, cipher.SHA256{})
+	assertNil(t, err)
+	assertTrue(t, saved == false, "saved")
+	assertTrue(t, other.Hash == (cipher.SHA256{}), "the Root changed")
+
+	var last *registry.Root
+	last, err = c.LastRoot(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, last.Hash == first, "last Root changed")
+
+	// matching expected
+
+	saved, err = c.CompareAndSave(up, other, first)
+	assertNil(t, err)
+	assertTrue(t, saved == true, "not saved")
+	assertTrue(t, other.Seq == 1 && other.Prev == first, "wrong seq or prev")
+
+	last, err = c.LastRoot(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, last.Hash == other.Hash, "wrong last Root")
+
+}
+
+// failingIdxDB is IdxDB that fails transactions if
+// the fail field is true
+type failingIdxDB struct {
+	data.IdxDB
+	fail bool
+}
+
+var errTestTx = errors.New("test transaction error")
+
+func (f *failingIdxDB) Tx(fn func(data.Feeds) error) (err error) {
+	if f.fail == true {
+		return errTestTx
+	}
+	return f.IdxDB.Tx(fn)
+}
+
+func TestContainer_Save_rollback(t *testing.T) {
+
+	var (
+		idx    = &failingIdxDB{IdxDB: idxdb.NewMemeoryDB()}
+		conf   = getTestConfig()
+		pk, sk = cipher.GenerateKeyPair()
+
+		rollbacks []error
+	)
+
+	conf.DB = data.NewDB(cxds.NewMemoryCXDS(), idx)
+	conf.OnSaveRollback = func(err error) {
+		rollbacks = append(rollbacks, err)
+	}
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up *Unpack
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var r = &registry.Root{Pub: pk, Nonce: 1}
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &User{"Alice", 19}),
+	}
+
+	assertNil(t, c.Save(up, r))
+	assertTrue(t, len(rollbacks) == 0, "called on success")
+
+	idx.fail = true
+
+	r.Refs = append(r.Refs,
+		createDynamic(up, testRegistry, "test.User", &User{"Bob", 21}))
+
+	assertTrue(t, c.Save(up, r) == errTestTx, "missing or wrong error")
+	assertTrue(t, len(rollbacks) == 1 && rollbacks[0] == errTestTx,
+		fmt.Sprint("wrong rollbacks: ", rollbacks))
+
+	idx.fail = false
+
+	// the CompareAndSave with wrong expected hash
+
+	var saved bool
+	saved, err = c.CompareAndSave(up, r, cipher.SHA256{})
+	assertNil(t, err)
+	assertTrue(t, saved == false, "saved")
+	assertTrue(t, len(rollbacks) == 1,
+		fmt.Sprint("called on mismatch: ", rollbacks))
+
+}
+
+func TestUnpack_SaveStat(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		alice = User{"Alice", 19}
+		bob   = User{"Bob", 21}
+		eva   = User{"Eva", 23}
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var newObjects, dupObjects = up.SaveStat()
+	assertTrue(t, newObjects == 0 && dupObjects == 0, "not saved yet")
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+		createDynamic(up, testRegistry, "test.User", &bob),
+	}
+	assertNil(t, c.Save(up, r))
+
+	newObjects, dupObjects = up.SaveStat()
+	assertTrue(t, newObjects == 2, "wrong number of new objects")
+	assertTrue(t, dupObjects == 0, "wrong number of existing objects")
+
+	// the Alice already exists
+
+	var other *Unpack
+	other, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer other.Close()
+
+	r.Refs = []registry.Dynamic{
+		createDynamic(other, testRegistry, "test.User", &alice),
+		createDynamic(other, testRegistry, "test.User", &eva),
+	}
+
+	// not used by the Root
+	_, err = other.Add([]byte("unused object"))
+	assertNil(t, err)
+
+	assertNil(t, c.Save(other, r))
+
+	newObjects, dupObjects = other.SaveStat()
+	assertTrue(t, newObjects == 1, "wrong number of new objects")
+	assertTrue(t, dupObjects == 1, "wrong number of existing objects")
+
+}
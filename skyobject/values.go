@@ -0,0 +1,131 @@
+package skyobject
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+// under lock, get values by given keys; a value
+// that is not found is nil; it returns index of
+// failed key
+func (c *Cache) getMany(
+	keys []cipher.SHA256, // :
+) (
+	vals [][]byte, //        :
+	i int, //                : index of failed key
+	err error, //            :
+) {
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	vals = make([][]byte, len(keys))
+
+	for i = range keys {
+		vals[i], _, err = c.get(keys[i], 0)
+		if err != nil && err != data.ErrNotFound {
+			return
+		}
+	}
+
+	return vals, 0, nil
+}
+
+// Values gets objects by given references and decodes
+// them to the slice the dst points to. The dst must be
+// pointer to slice. Order of the objects is the same
+// as order of the references. The Values uses values
+// kept by the Pack (see Value and KeepValue) and fetches
+// other objects at once. Objects not found are received
+// using the Get method (see OnMissing field of the
+// Config). Decoded values are kept by the Pack. If an
+// object can't be received or decoded, then the Values
+// returns ValueError with index of the reference
+func (p *Pack) Values(refs []registry.Ref, dst interface{}) (err error) {
+	return p.values(p.Get, refs, dst)
+}
+
+// Values is Values of the Pack, but objects not
+// found are received using the Get method of the
+// Unpack (see Get)
+func (u *Unpack) Values(refs []registry.Ref, dst interface{}) (err error) {
+	return u.values(u.Get, refs, dst)
+}
+
+func (p *Pack) values(
+	get func(cipher.SHA256) ([]byte, error), // : Get of the Pack
+	refs []registry.Ref, //                      : references
+	dst interface{}, //                          : pointer to slice
+) (
+	err error, //                                : an error
+) {
+
+	var pv = reflect.ValueOf(dst)
+
+	if pv.Kind() != reflect.Ptr || pv.Elem().Kind() != reflect.Slice {
+		return errors.New("destination is not a pointer to slice")
+	}
+
+	var (
+		et = pv.Elem().Type().Elem()
+		sv = reflect.MakeSlice(pv.Elem().Type(), len(refs), len(refs))
+
+		keys []cipher.SHA256 // not kept
+		idxs []int           // indices of the keys
+	)
+
+	for i := range refs {
+
+		if refs[i].IsBlank() == true {
+			return &ValueError{i, registry.ErrReferenceRepresentsNil}
+		}
+
+		if val, ok := p.Value(refs[i].Hash, et); ok == true {
+			sv.Index(i).Set(registry.CopyValue(val))
+			continue
+		}
+
+		keys = append(keys, refs[i].Hash)
+		idxs = append(idxs, i)
+
+	}
+
+	var (
+		vals [][]byte
+		k    int
+	)
+
+	if vals, k, err = p.c.getMany(keys); err != nil {
+		return &ValueError{idxs[k], err}
+	}
+
+	var codec = p.Codec()
+
+	for k, val := range vals {
+
+		var i = idxs[k]
+
+		if val == nil {
+			if val, err = get(keys[k]); err != nil {
+				return &ValueError{i, err}
+			}
+		}
+
+		var el = sv.Index(i)
+
+		if err = codec.Unmarshal(val, el.Addr().Interface()); err != nil {
+			return &ValueError{i, err}
+		}
+
+		p.KeepValue(keys[k], registry.CopyValue(el))
+
+	}
+
+	pv.Elem().Set(sv)
+	return
+}
@@ -0,0 +1,196 @@
+package skyobject
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestPack_Values(t *testing.T) {
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+
+		users = []User{
+			{"Alice", 19},
+			{"Bob", 20},
+			{"Eva", 21},
+			{"Alice", 19}, // duplicate
+		}
+		refs = make([]registry.Ref, 0, len(users))
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	for _, usr := range users {
+		var key cipher.SHA256
+		key, err = up.Add(encoder.Serialize(usr))
+		assertNil(t, err)
+		refs = append(refs, registry.Ref{Hash: key})
+	}
+
+	var got []User
+	assertNil(t, up.Values(refs, &got))
+
+	assertTrue(t, len(got) == len(users), "wrong length")
+	for i := range users {
+		assertTrue(t, got[i] == users[i], "wrong order or content")
+	}
+
+	// kept by the Pack
+
+	var kept, ok = up.Value(refs[2].Hash, reflect.TypeOf(User{}))
+	assertTrue(t, ok == true, "value is not kept")
+	assertTrue(t, kept.Interface().(User) == users[2], "wrong kept value")
+
+	// not a pointer to slice
+
+	assertTrue(t, up.Values(refs, got) != nil, "missing error")
+
+	// errors
+
+	var ve *ValueError
+
+	refs[1] = registry.Ref{Hash: cipher.SumSHA256([]byte("missing"))}
+	ve, _ = up.Values(refs, &got).(*ValueError)
+	assertTrue(t, ve != nil, "missing ValueError")
+	assertTrue(t, ve.Index() == 1, "wrong index")
+	assertTrue(t, ve.Err() == data.ErrNotFound, "wrong cause")
+
+	refs[1] = registry.Ref{}
+	ve, _ = up.Values(refs, &got).(*ValueError)
+	assertTrue(t, ve != nil, "missing ValueError")
+	assertTrue(t, ve.Index() == 1, "wrong index")
+
+	// schema mismatch
+
+	type Large struct {
+		A, B, C, D uint64
+	}
+
+	var key cipher.SHA256
+	key, err = up.Add(encoder.Serialize(Large{1, 2, 3, 4}))
+	assertNil(t, err)
+
+	var large []Large
+	ve, _ = up.Values([]registry.Ref{{Hash: key}, refs[0]}, &large).(*ValueError)
+	assertTrue(t, ve != nil, "missing ValueError")
+	assertTrue(t, ve.Index() == 1, "wrong index")
+
+}
+
+func TestPack_Values_keptDeep(t *testing.T) {
+
+	type Tagged struct {
+		Tags []string
+	}
+
+	var (
+		c      = getTestContainer()
+		pk, sk = cipher.GenerateKeyPair()
+	)
+
+	defer c.Close()
+
+	assertNil(t, c.AddFeed(pk))
+
+	var up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+	defer up.Close()
+
+	var key cipher.SHA256
+	key, err = up.Add(encoder.Serialize(Tagged{[]string{"one", "two"}}))
+	assertNil(t, err)
+
+	var refs = []registry.Ref{{Hash: key}}
+
+	for i := 0; i < 2; i++ {
+
+		var got []Tagged
+		assertNil(t, up.Values(refs, &got))
+
+		assertTrue(t, len(got) == 1, "wrong length")
+		assertTrue(t, len(got[0].Tags) == 2 && got[0].Tags[0] == "one",
+			"wrong value")
+
+		got[0].Tags[0] = "modified" // doesn't affect kept value
+
+	}
+
+	var tg Tagged
+	assertNil(t, refs[0].Value(up, &tg))
+	assertTrue(t, tg.Tags[0] == "one", "kept value changed")
+
+}
+
+func TestPack_Values_onMissing(t *testing.T) {
+
+	var (
+		conf   = getTestConfig()
+		remote = make(map[cipher.SHA256][]byte)
+
+		users = []User{{"Alice", 19}, {"Bob", 20}}
+		refs  []registry.Ref
+	)
+
+	for _, usr := range users {
+		var val = encoder.Serialize(usr)
+		var key = cipher.SumSHA256(val)
+		remote[key] = val
+		refs = append(refs, registry.Ref{Hash: key})
+	}
+
+	conf.OnMissing = func(key cipher.SHA256) (val []byte, err error) {
+		var ok bool
+		if val, ok = remote[key]; ok == false {
+			err = data.ErrNotFound
+		}
+		return
+	}
+
+	var c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	// stored locally
+
+	var local = User{"Eva", 21}
+
+	var key cipher.SHA256
+	key = cipher.SumSHA256(encoder.Serialize(local))
+	_, err = c.Set(key, encoder.Serialize(local), 1)
+	assertNil(t, err)
+
+	refs = append(refs, registry.Ref{Hash: key})
+	users = append(users, local)
+
+	var pack *Pack
+	pack, err = c.Pack(nil, testRegistry)
+	assertNil(t, err)
+
+	var got []User
+	assertNil(t, pack.Values(refs, &got))
+
+	assertTrue(t, len(got) == len(users), "wrong length")
+	for i := range users {
+		assertTrue(t, got[i] == users[i], "wrong order or content")
+	}
+
+	// the Pack doesn't store fetched values
+
+	_, _, err = c.Get(refs[0].Hash, 0)
+	assertTrue(t, err == data.ErrNotFound, "stored by a Pack")
+
+}
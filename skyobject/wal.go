@@ -0,0 +1,258 @@
+package skyobject
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+
+	"github.com/skycoin/cxo/data"
+)
+
+// A walRecord is a Root being saved. The record is
+// written to WAL before the Root committed to IdxDB.
+// The Root and its Registry are written to CXDS after
+// the commit. Thus, an interrupted Save can be finished
+// using the record
+type walRecord struct {
+	Pub   cipher.PubKey
+	Nonce uint64
+
+	Seq  uint64
+	Prev cipher.SHA256
+	Hash cipher.SHA256
+	Sig  cipher.Sig
+	Time int64
+
+	Root []byte // encoded Root
+	Reg  []byte // encoded Registry
+}
+
+// write-ahead log of the Container; the WAL keeps
+// one record, because Save holds the WAL from
+// writing a record to removing it
+type wal struct {
+	mx   sync.Mutex
+	path string // empty if WAL is not used
+	kept bool   // the record is kept (not finished)
+
+	crash func() // testing: called after a record written
+}
+
+func (w *wal) init(c *Container) {
+	if c.conf.WAL == false {
+		return
+	}
+	if c.conf.DB != nil || c.conf.InMemoryDB == true {
+		return // not durable DB
+	}
+	if c.conf.DBPath == "" {
+		w.path = filepath.Join(c.conf.DataDir, WAL)
+	} else {
+		w.path = c.conf.DBPath + ".wal"
+	}
+}
+
+// begin writes given record and holds the WAL;
+// the WAL is not held if an error returned
+func (w *wal) begin(rec *walRecord) (err error) {
+
+	if w.path == "" {
+		return
+	}
+
+	w.mx.Lock()
+
+	if w.kept == true {
+		w.mx.Unlock()
+		return ErrUnfinishedSave // don't overwrite the record
+	}
+
+	var val = encoder.Serialize(rec)
+	var sum = cipher.SumSHA256(val)
+
+	if err = writeSync(w.path, append(val, sum[:]...)); err != nil {
+		os.Remove(w.path) // ignore error
+		w.mx.Unlock()
+		return
+	}
+
+	if w.crash != nil {
+		w.crash()
+	}
+
+	return
+}
+
+// end releases the WAL removing the record,
+// or keeping it to finish the Save later
+func (w *wal) end(keep bool) {
+
+	if w.path == "" {
+		return
+	}
+
+	if keep == false {
+		os.Remove(w.path) // ignore error
+	}
+
+	w.kept = keep
+	w.mx.Unlock()
+}
+
+// used reports that the WAL is used
+func (w *wal) used() bool {
+	return w.path != ""
+}
+
+// finish kept record if any; the finish
+// should be called before a Save
+func (w *wal) finish(c *Container) (err error) {
+
+	if w.path == "" {
+		return
+	}
+
+	w.mx.Lock()
+	defer w.mx.Unlock()
+
+	if w.kept == false {
+		return
+	}
+
+	if err = w.replay(c); err == nil {
+		w.kept = false
+	}
+
+	return
+}
+
+func writeSync(path string, val []byte) (err error) {
+
+	var fl *os.File
+	if fl, err = os.Create(path); err != nil {
+		return
+	}
+
+	if _, err = fl.Write(val); err == nil {
+		err = fl.Sync()
+	}
+
+	if cerr := fl.Close(); err == nil {
+		err = cerr
+	}
+
+	return
+}
+
+// read record of the WAL; the read returns nil if
+// there is not a record, or the record is broken
+// (since it has been written partially, the Root
+// has not been committed)
+func (w *wal) read() (rec *walRecord, err error) {
+
+	var val []byte
+
+	if val, err = ioutil.ReadFile(w.path); err != nil {
+		if os.IsNotExist(err) == true {
+			err = nil
+		}
+		return
+	}
+
+	if len(val) < len(cipher.SHA256{}) {
+		return // broken
+	}
+
+	var (
+		sum  cipher.SHA256
+		body = val[:len(val)-len(sum)]
+	)
+
+	copy(sum[:], val[len(body):])
+
+	if cipher.SumSHA256(body) != sum {
+		return // broken
+	}
+
+	rec = new(walRecord)
+	if err = encoder.DeserializeRaw(body, rec); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// replay finishes interrupted Save if any;
+// it's called before the Index loaded, or
+// to finish kept record (see finish)
+func (w *wal) replay(c *Container) (err error) {
+
+	if w.path == "" || c.conf.ReadOnly == true {
+		return
+	}
+
+	var rec *walRecord
+	if rec, err = w.read(); err != nil || rec == nil {
+		if err == nil {
+			os.Remove(w.path) // remove broken record if any
+		}
+		return
+	}
+
+	err = c.db.IdxDB().Tx(func(fs data.Feeds) (err error) {
+
+		var hs data.Heads
+		if hs, err = fs.Heads(rec.Pub); err != nil {
+			return
+		}
+
+		var roots data.Roots
+		if roots, err = hs.Add(rec.Nonce); err != nil {
+			return
+		}
+
+		return roots.Set(&data.Root{
+			Time: rec.Time,
+			Seq:  rec.Seq,
+			Prev: rec.Prev,
+			Hash: rec.Hash,
+			Sig:  rec.Sig,
+		})
+
+	})
+
+	switch err {
+	case nil:
+	case data.ErrNoSuchFeed:
+		return os.Remove(w.path) // the feed has been removed
+	default:
+		return
+	}
+
+	// the Registry is written before the Root, thus
+	// if the Root exists, then the Save is finished
+
+	var cx = c.db.CXDS()
+
+	if _, _, err = cx.Get(rec.Hash, 0); err == nil {
+		return os.Remove(w.path)
+	} else if err != data.ErrNotFound {
+		return
+	}
+
+	var regHash = cipher.SumSHA256(rec.Reg)
+
+	if _, err = cx.Set(regHash, rec.Reg, 1); err != nil {
+		return
+	}
+
+	if _, err = cx.Set(rec.Hash, rec.Root, 1); err != nil {
+		return
+	}
+
+	return os.Remove(w.path)
+}
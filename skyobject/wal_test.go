@@ -0,0 +1,169 @@
+package skyobject
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skycoin/skycoin/src/cipher"
+
+	"github.com/skycoin/cxo/skyobject/registry"
+)
+
+func TestContainer_WAL(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "test")
+	conf.WAL = true
+
+	var walPath = conf.DBPath + ".wal"
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+
+	var (
+		pk, sk = cipher.GenerateKeyPair()
+		up     *Unpack
+
+		alice = User{"Alice", 19}
+		bob   = User{"Bob", 20}
+	)
+
+	assertNil(t, c.AddFeed(pk))
+
+	up, err = c.Unpack(sk, testRegistry)
+	assertNil(t, err)
+
+	var r = new(registry.Root)
+	r.Pub, r.Nonce = pk, 1
+	r.Refs = []registry.Dynamic{
+		createDynamic(up, testRegistry, "test.User", &alice),
+	}
+	assertNil(t, c.Save(up, r)) // seq 0
+
+	_, err = os.Stat(walPath)
+	assertTrue(t, os.IsNotExist(err), "WAL record is not removed")
+
+	// crash after the WAL record written,
+	// but before the Root committed
+
+	var (
+		errCrash = errors.New("crash")
+		rec      *walRecord
+	)
+
+	c.wal.crash = func() {
+		if rec, err = c.wal.read(); err != nil {
+			t.Error(err)
+		}
+		panic(errCrash)
+	}
+
+	r.Refs = append(r.Refs,
+		createDynamic(up, testRegistry, "test.User", &bob))
+
+	func() {
+		defer func() {
+			assertTrue(t, recover() == errCrash, "not crashed")
+		}()
+		c.Save(up, r) // seq 1
+	}()
+
+	assertTrue(t, rec != nil, "missing WAL record")
+	assertTrue(t, rec.Seq == 1, "wrong seq of WAL record")
+
+	// not committed
+	var lr *registry.Root
+	lr, err = c.LastRoot(pk, 1)
+	assertNil(t, err)
+	assertTrue(t, lr.Seq == 0, "committed")
+
+	// the process died (keep the DB as is)
+
+	assertNil(t, c.db.Close())
+	c.Cache.stat.Close()
+	c.expiry.close()
+
+	// reopen
+
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	_, err = os.Stat(walPath)
+	assertTrue(t, os.IsNotExist(err), "WAL record is not removed")
+
+	lr, err = c.LastRoot(pk, 1)
+	assertNil(t, err)
+
+	assertTrue(t, lr.Seq == 1, "not replayed")
+	assertTrue(t, lr.Hash == rec.Hash, "wrong Root replayed")
+	assertTrue(t, len(lr.Refs) == 2, "wrong Refs")
+
+	var pack *Pack
+	pack, err = c.Pack(lr, nil)
+	assertNil(t, err)
+
+	var usr User
+	assertNil(t, lr.Refs[1].Value(pack, &usr))
+	assertTrue(t, usr == bob, "wrong value")
+
+	// rc of the objects counts the replayed Root
+
+	var rc uint32
+	_, rc, err = c.db.CXDS().Get(lr.Refs[0].Hash, 0)
+	assertNil(t, err)
+	assertTrue(t, rc == 2, "wrong rc of object of both Roots")
+
+	assertNil(t, c.DelRoot(pk, 1, 0))
+
+	assertNil(t, lr.Refs[0].Value(pack, &usr))
+	assertTrue(t, usr == alice, "wrong value")
+
+}
+
+func TestContainer_WAL_kept(t *testing.T) {
+
+	var dir, err = ioutil.TempDir("", "cxo-test")
+	assertNil(t, err)
+	defer os.RemoveAll(dir)
+
+	var conf = getTestConfig()
+
+	conf.InMemoryDB = false
+	conf.DBPath = filepath.Join(dir, "test")
+	conf.WAL = true
+
+	var c *Container
+	c, err = NewContainer(conf)
+	assertNil(t, err)
+	defer c.Close()
+
+	var pk, _ = cipher.GenerateKeyPair()
+
+	assertNil(t, c.wal.begin(&walRecord{Pub: pk, Nonce: 1}))
+	c.wal.end(true) // keep
+
+	// the kept record is not overwritten
+	assertTrue(t, c.wal.begin(&walRecord{}) == ErrUnfinishedSave,
+		"kept record overwritten")
+
+	// finish the kept record (no such feed)
+	assertNil(t, c.wal.finish(c))
+
+	_, err = os.Stat(conf.DBPath + ".wal")
+	assertTrue(t, os.IsNotExist(err), "WAL record is not removed")
+
+	assertNil(t, c.wal.begin(&walRecord{}))
+	c.wal.end(false)
+
+}